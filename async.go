@@ -0,0 +1,373 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"time"
+)
+
+// AsyncLogger returns a logger which queues the logs and passes them
+// to the inner logger by the pool of the workers goroutines, so the slow
+// inner logger (for example the logger writing to the slow sink)
+// does not block the operations holding the database connections.
+//
+// The queue holds up to queueSize logs. The log is dropped if the queue
+// is full (or the logger is closed) and the onDrop function (if it is not nil)
+// is called synchronously with the event of the dropped log which holds
+// the topic, the duration, the query and the error of the operation.
+//
+// The arguments and the destination values are copied and the results
+// are resolved (the last insert id, the rows affected and the warnings)
+// before queueing, the context is passed to the inner logger as is,
+// so the inner logger may receive the canceled context. Timers are provided
+// by the inner logger synchronously. The logs of the different workers
+// are not ordered, use a single worker to preserve the order.
+// The optional interfaces (for example RoleLogger or RetryLogger)
+// are forwarded to the inner logger and the tagged loggers share the queue.
+//
+// The returned logger implements io.Closer: Close stops accepting the logs,
+// waits until the workers pass the queued logs to the inner logger
+// and closes the inner logger if it implements io.Closer.
+func AsyncLogger(inner Logger, workers, queueSize int, onDrop func(Event)) Logger {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	q := &asyncQueue{queue: make(chan func(), queueSize), onDrop: onDrop}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return newAsyncLogger(inner, q)
+}
+
+func newAsyncLogger(inner Logger, q *asyncQueue) *asyncLogger {
+	l := &asyncLogger{asyncQueue: q}
+	l.forwarder = forward(inner, func(inner Logger) Logger { return newAsyncLogger(inner, q) })
+	return l
+}
+
+type asyncLogger struct {
+	forwarder
+	*asyncQueue
+}
+
+// asyncQueue is shared by the asyncLogger
+// and its decorators of the tagged inner loggers.
+type asyncQueue struct {
+	queue  chan func()
+	onDrop func(Event)
+	mu     sync.RWMutex // guards the closed and the queue closing
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func (q *asyncQueue) work() {
+	defer q.wg.Done()
+	for log := range q.queue {
+		log()
+	}
+}
+
+// enqueue queues the log or drops the log and reports the event.
+func (q *asyncQueue) enqueue(e Event, log func()) {
+	q.mu.RLock()
+	if !q.closed {
+		select {
+		case q.queue <- log:
+			q.mu.RUnlock()
+			return
+		default:
+		}
+	}
+	q.mu.RUnlock()
+
+	if q.onDrop != nil {
+		q.onDrop(e)
+	}
+}
+
+// Close stops accepting the logs, waits until the queued logs are passed
+// to the inner logger and closes the inner logger if it implements io.Closer.
+func (l *asyncLogger) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+
+	l.wg.Wait()
+
+	if closer, ok := l.Logger.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (l *asyncLogger) DriverOpen(name string, d time.Duration, err error) {
+	l.enqueue(Event{Topic: "driver-open", Duration: d, Err: err}, func() {
+		l.Logger.DriverOpen(name, d, err)
+	})
+}
+
+func (l *asyncLogger) ConnPrepare(d time.Duration, query string, err error) {
+	l.enqueue(Event{Topic: "conn-prepare", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.ConnPrepare(d, query, err)
+	})
+}
+
+func (l *asyncLogger) ConnClose(d time.Duration, err error) {
+	l.enqueue(Event{Topic: "conn-close", Duration: d, Err: err}, func() {
+		l.Logger.ConnClose(d, err)
+	})
+}
+
+func (l *asyncLogger) ConnBegin(d time.Duration, err error) {
+	l.enqueue(Event{Topic: "conn-begin", Duration: d, Err: err}, func() {
+		l.Logger.ConnBegin(d, err)
+	})
+}
+
+func (l *asyncLogger) ConnBeginTx(ctx context.Context, d time.Duration, opts driver.TxOptions, err error) {
+	l.enqueue(Event{Topic: "conn-begin-tx", Duration: d, Err: err}, func() {
+		l.Logger.ConnBeginTx(ctx, d, opts, err)
+	})
+}
+
+func (l *asyncLogger) ConnPrepareContext(ctx context.Context, d time.Duration, query string, err error) {
+	l.enqueue(Event{Topic: "conn-prepare-context", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.ConnPrepareContext(ctx, d, query, err)
+	})
+}
+
+func (l *asyncLogger) ConnPrepareFallback(ctx context.Context, d time.Duration, query string, err error) {
+	l.enqueue(Event{Topic: "conn-prepare-fallback", Duration: d, Query: query, Err: err}, func() {
+		logPrepareFallback(l.Logger, ctx, d, query, err)
+	})
+}
+
+func (l *asyncLogger) ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	res = snapshotResult(res)
+	dargs = copyValues(dargs)
+	l.enqueue(Event{Topic: "conn-exec", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.ConnExec(d, query, dargs, res, err)
+	})
+}
+
+func (l *asyncLogger) ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	res = snapshotResult(res)
+	nvdargs = copyNamedValues(nvdargs)
+	l.enqueue(Event{Topic: "conn-exec-context", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.ConnExecContext(ctx, d, query, nvdargs, res, err)
+	})
+}
+
+func (l *asyncLogger) ConnPing(ctx context.Context, d time.Duration, err error) {
+	l.enqueue(Event{Topic: "conn-ping", Duration: d, Err: err}, func() {
+		l.Logger.ConnPing(ctx, d, err)
+	})
+}
+
+func (l *asyncLogger) ConnRaw() {
+	l.enqueue(Event{Topic: "conn-raw"}, func() {
+		logRaw(l.Logger)
+	})
+}
+
+func (l *asyncLogger) ConnExplain(ctx context.Context, d time.Duration, query string, plan []string, err error) {
+	l.enqueue(Event{Topic: "conn-explain", Duration: d, Query: query, Err: err}, func() {
+		logExplain(l.Logger, ctx, d, query, plan, err)
+	})
+}
+
+func (l *asyncLogger) ConnQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	dargs = copyValues(dargs)
+	l.enqueue(Event{Topic: "conn-query", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.ConnQuery(d, query, dargs, err)
+	})
+}
+
+func (l *asyncLogger) ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	nvdargs = copyNamedValues(nvdargs)
+	l.enqueue(Event{Topic: "conn-query-context", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.ConnQueryContext(ctx, d, query, nvdargs, err)
+	})
+}
+
+func (l *asyncLogger) StmtClose(d time.Duration, err error) {
+	l.enqueue(Event{Topic: "stmt-close", Duration: d, Err: err}, func() {
+		l.Logger.StmtClose(d, err)
+	})
+}
+
+func (l *asyncLogger) StmtCloseTotal(d, total time.Duration, err error) {
+	l.enqueue(Event{Topic: "stmt-close", Duration: d, Err: err}, func() {
+		logStmtClose(l.Logger, d, total, err)
+	})
+}
+
+func (l *asyncLogger) StmtExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	res = snapshotResult(res)
+	dargs = copyValues(dargs)
+	l.enqueue(Event{Topic: "stmt-exec", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.StmtExec(d, query, dargs, res, err)
+	})
+}
+
+func (l *asyncLogger) StmtExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	res = snapshotResult(res)
+	nvdargs = copyNamedValues(nvdargs)
+	l.enqueue(Event{Topic: "stmt-exec-context", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.StmtExecContext(ctx, d, query, nvdargs, res, err)
+	})
+}
+
+func (l *asyncLogger) StmtQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	dargs = copyValues(dargs)
+	l.enqueue(Event{Topic: "stmt-query", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.StmtQuery(d, query, dargs, err)
+	})
+}
+
+func (l *asyncLogger) StmtQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	nvdargs = copyNamedValues(nvdargs)
+	l.enqueue(Event{Topic: "stmt-query-context", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.StmtQueryContext(ctx, d, query, nvdargs, err)
+	})
+}
+
+func (l *asyncLogger) RowsNext(d time.Duration, dest []driver.Value, err error) {
+	dest = copyValues(dest)
+	l.enqueue(Event{Topic: "rows-next", Duration: d, Err: err}, func() {
+		l.Logger.RowsNext(d, dest, err)
+	})
+}
+
+func (l *asyncLogger) RowsNextRow(d time.Duration, row int, columns []string, dest []driver.Value, err error) {
+	dest = copyValues(dest)
+	l.enqueue(Event{Topic: "rows-next", Duration: d, Err: err}, func() {
+		logRowsNext(l.Logger, d, row, columns, dest, err)
+	})
+}
+
+func (l *asyncLogger) TxCommit(d time.Duration, err error) {
+	l.enqueue(Event{Topic: "tx-commit", Duration: d, Err: err}, func() {
+		l.Logger.TxCommit(d, err)
+	})
+}
+
+func (l *asyncLogger) TxRollback(d time.Duration, err error) {
+	l.enqueue(Event{Topic: "tx-rollback", Duration: d, Err: err}, func() {
+		l.Logger.TxRollback(d, err)
+	})
+}
+
+func (l *asyncLogger) TxSavepoint(ctx context.Context, d time.Duration, query, command, name string, err error) {
+	l.enqueue(Event{Topic: "tx-savepoint", Duration: d, Query: query, Err: err}, func() {
+		l.Logger.TxSavepoint(ctx, d, query, command, name, err)
+	})
+}
+
+func (l *asyncLogger) ConnRetry(name string, d time.Duration, attempt int, backoff time.Duration, err error) {
+	if rl, ok := l.Logger.(RetryLogger); ok {
+		l.enqueue(Event{Topic: "conn-retry", Duration: d, Err: err}, func() {
+			rl.ConnRetry(name, d, attempt, backoff, err)
+		})
+	}
+}
+
+func (l *asyncLogger) ConnectorConnect(ctx context.Context, d time.Duration, err error) {
+	if cl, ok := l.Logger.(ConnectorLogger); ok {
+		l.enqueue(Event{Topic: "connector-connect", Duration: d, Err: err}, func() {
+			cl.ConnectorConnect(ctx, d, err)
+		})
+	}
+}
+
+func (l *asyncLogger) Timer() Timer {
+	return l.Logger.Timer()
+}
+
+// copyValues returns the copy of the values and of the byte slices
+// of the values, so the values may outlive the operation
+// (the database/sql and the drivers reuse the destination buffers).
+func copyValues(values []driver.Value) []driver.Value {
+	if values == nil {
+		return nil
+	}
+
+	c := make([]driver.Value, len(values))
+	for i, v := range values {
+		if p, ok := v.([]byte); ok {
+			v = append([]byte(nil), p...)
+		}
+		c[i] = v
+	}
+	return c
+}
+
+// copyNamedValues returns the copy of the named values
+// and of the byte slices of the values.
+func copyNamedValues(values []driver.NamedValue) []driver.NamedValue {
+	if values == nil {
+		return nil
+	}
+
+	c := make([]driver.NamedValue, len(values))
+	for i, v := range values {
+		if p, ok := v.Value.([]byte); ok {
+			v.Value = append([]byte(nil), p...)
+		}
+		c[i] = v
+	}
+	return c
+}
+
+// resultSnapshot is a driver.Result resolved before queueing,
+// so the inner logger does not call the result of the driver
+// after the operation (for example concurrently with the next
+// operation of the connection).
+type resultSnapshot struct {
+	id       int64
+	idErr    error
+	rows     int64
+	rowsErr  error
+	all      []int64
+	warnings []string
+}
+
+// snapshotResult returns the snapshot of the result or nil if the result is nil.
+func snapshotResult(res driver.Result) driver.Result {
+	if res == nil {
+		return nil
+	}
+
+	var r resultSnapshot
+	r.id, r.idErr = res.LastInsertId()
+	r.rows, r.rowsErr = res.RowsAffected()
+	if a, ok := res.(ResultAllRowsAffected); ok {
+		r.all = a.AllRowsAffected()
+	}
+	if w, ok := res.(ResultWarnings); ok {
+		r.warnings = w.Warnings()
+	}
+	return r
+}
+
+func (r resultSnapshot) LastInsertId() (int64, error) { return r.id, r.idErr }
+
+func (r resultSnapshot) RowsAffected() (int64, error) { return r.rows, r.rowsErr }
+
+func (r resultSnapshot) AllRowsAffected() []int64 { return r.all }
+
+func (r resultSnapshot) Warnings() []string { return r.warnings }
@@ -0,0 +1,175 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestAsyncLoggerSlowSink(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		logged    []time.Duration
+		dropped   []Event
+		release   = make(chan struct{})
+		slowInner = EventLogger{Callback: func(e Event) {
+			<-release
+			mu.Lock()
+			logged = append(logged, e.Duration)
+			mu.Unlock()
+		}}
+	)
+
+	l := AsyncLogger(slowInner, 1, 1, func(e Event) { dropped = append(dropped, e) })
+
+	start := time.Now()
+	for d := time.Duration(1); d <= 10; d++ {
+		l.ConnExec(d, "INSERT INTO foo VALUES (1)", nil, nil, nil)
+	}
+	elapsed := time.Since(start)
+
+	if elapsed > time.Second {
+		t.Errorf("unexpected latency of the operations, expected: less than 1s, recieved: %s", elapsed)
+	}
+
+	// one log is held by the worker and one log is queued
+	// or the worker has not received the first log yet
+	if len(dropped) < 8 || len(dropped) > 9 {
+		t.Errorf("unexpected number of drops, expected: 8 or 9, recieved: %d", len(dropped))
+	}
+
+	for _, e := range dropped {
+		if e.Topic != "conn-exec" || e.Query != "INSERT INTO foo VALUES (1)" || e.Duration < 2 {
+			t.Errorf("unexpected dropped event, recieved: %+v", e)
+		}
+	}
+
+	close(release)
+	if err := l.(io.Closer).Close(); err != nil {
+		t.Fatalf("close error: %#v", err)
+	}
+
+	if len(logged)+len(dropped) != 10 || logged[0] != 1 {
+		t.Errorf("unexpected logs, expected: 10 logged or dropped starting by 1, recieved: %v and %d drops", logged, len(dropped))
+	}
+
+	l.ConnClose(42, nil)
+	if len(dropped) == 0 || dropped[len(dropped)-1].Topic != "conn-close" {
+		t.Errorf("unexpected log after close, expected: drop of conn-close, recieved: %+v", dropped)
+	}
+}
+
+func TestAsyncLoggerCopyValues(t *testing.T) {
+	var (
+		dest    []driver.Value
+		release = make(chan struct{})
+	)
+
+	l := AsyncLogger(EventLogger{Callback: func(e Event) {
+		<-release
+		for _, a := range e.Args {
+			dest = append(dest, a.Value)
+		}
+	}}, 1, 1, nil)
+
+	buf := []byte("foo")
+	values := []driver.Value{buf, int64(42)}
+	l.RowsNext(42, values, nil)
+
+	copy(buf, "bar")
+	values[1] = int64(0)
+
+	close(release)
+	l.(io.Closer).Close()
+
+	expected := []driver.Value{[]byte("foo"), int64(42)}
+	if !reflect.DeepEqual(dest, expected) {
+		t.Errorf("unexpected dest, expected: %v, recieved: %v", expected, dest)
+	}
+}
+
+// callsResult is a driver.Result which counts the calls.
+type callsResult struct {
+	calls *int32
+}
+
+func (r callsResult) LastInsertId() (int64, error) {
+	atomic.AddInt32(r.calls, 1)
+	return 7, nil
+}
+
+func (r callsResult) RowsAffected() (int64, error) {
+	atomic.AddInt32(r.calls, 1)
+	return 3, nil
+}
+
+func TestAsyncLoggerSnapshotResult(t *testing.T) {
+	var (
+		calls   int32
+		events  []Event
+		release = make(chan struct{})
+	)
+
+	l := AsyncLogger(EventLogger{Callback: func(e Event) {
+		<-release
+		events = append(events, e)
+	}}, 1, 1, nil)
+
+	l.ConnExec(42, "INSERT INTO foo VALUES (1)", nil, callsResult{calls: &calls}, nil)
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("unexpected calls of the result before the log, expected: %d, recieved: %d", 2, n)
+	}
+
+	close(release)
+	l.(io.Closer).Close()
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("unexpected calls of the result after the log, expected: %d, recieved: %d", 2, n)
+	}
+
+	if len(events) != 1 || events[0].LastInsertId != 7 || events[0].RowsAffected != 3 {
+		t.Errorf("unexpected events, expected: last insert id 7 and rows affected 3, recieved: %+v", events)
+	}
+}
+
+func TestAsyncLoggerDriver(t *testing.T) {
+	var topics []string
+	l := AsyncLogger(EventLogger{
+		Callback: func(e Event) { topics = append(topics, e.Topic) },
+		NewTimer: func() Timer { return fakeTimer{} },
+	}, 1, 64, func(e Event) { t.Errorf("unexpected drop: %+v", e) })
+
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("TestAsyncLoggerDriver")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	db.Close()
+	drv.Close()
+
+	expected := []string{"driver-open", "conn-exec-context", "conn-prepare-context", "stmt-exec-context", "stmt-close", "conn-close"}
+	if !reflect.DeepEqual(topics, expected) {
+		t.Errorf("unexpected topics, expected: %v, recieved: %v", expected, topics)
+	}
+}
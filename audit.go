@@ -0,0 +1,183 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+// auditVerbs are the first keywords of the mutating statements.
+var auditVerbs = map[string]bool{
+	"INSERT":   true,
+	"UPDATE":   true,
+	"DELETE":   true,
+	"MERGE":    true,
+	"REPLACE":  true,
+	"UPSERT":   true,
+	"CREATE":   true,
+	"ALTER":    true,
+	"DROP":     true,
+	"TRUNCATE": true,
+	"RENAME":   true,
+	"GRANT":    true,
+	"REVOKE":   true,
+}
+
+// auditLogger is a Logger which writes the audit records
+// of the mutating statements to the sink
+// and passes all logs through to the underlying logger.
+type auditLogger struct {
+	forwarder
+	*auditState
+}
+
+// auditState is shared by the auditLogger
+// and its decorators of the tagged inner loggers,
+// so the tagged loggers write to the same chain of the records.
+type auditState struct {
+	mu    sync.Mutex
+	sink  io.Writer
+	clock Clock
+	hash  [sha256.Size]byte // hash of the previous record
+}
+
+// AuditLogger returns a logger which decorates the inner logger by the audit
+// trail of the mutating statements (INSERT, UPDATE, DELETE, MERGE, REPLACE,
+// UPSERT and DDL) executed or queried through the connections and the statements.
+// Each statement is written to the sink as one line record of the timestamp,
+// the verb, the interpolated query, the rows affected and the error:
+//
+//	time=2021-01-02T03:04:05.000000006Z verb=UPDATE query="UPDATE foo SET bar = 42" rows=1 error="" hash=5d2f...
+//
+// The rows affected is -1 if it is unknown (for example of the query
+// or the failed statement). The hash is the hex encoded SHA-256
+// of the hash of the previous record and the current record
+// up to the hash (hash of the first record is chained to zeros),
+// so the removal or the modification of any record is evident.
+// Records are not written for the skipped operations (driver.ErrSkip)
+// nor for the fallbacks of the connections without the context
+// interfaces (see Fallback), so each executed statement is audited once.
+// The optional tagging interfaces (for example RoleLogger)
+// tag the inner logger.
+func AuditLogger(inner Logger, sink io.Writer) Logger {
+	return newAuditLogger(inner, &auditState{sink: sink, clock: realClock{}})
+}
+
+func newAuditLogger(inner Logger, s *auditState) *auditLogger {
+	l := &auditLogger{auditState: s}
+	l.forwarder = forward(inner, func(inner Logger) Logger { return newAuditLogger(inner, s) })
+	return l
+}
+
+func (l *auditLogger) ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.audit(query, dargs, nil, res, err)
+	l.Logger.ConnExec(d, query, dargs, res, err)
+}
+
+func (l *auditLogger) ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	if !Fallback(ctx) {
+		l.audit(query, nil, nvdargs, res, err)
+	}
+	l.Logger.ConnExecContext(ctx, d, query, nvdargs, res, err)
+}
+
+func (l *auditLogger) ConnQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.audit(query, dargs, nil, nil, err)
+	l.Logger.ConnQuery(d, query, dargs, err)
+}
+
+func (l *auditLogger) ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	if !Fallback(ctx) {
+		l.audit(query, nil, nvdargs, nil, err)
+	}
+	l.Logger.ConnQueryContext(ctx, d, query, nvdargs, err)
+}
+
+func (l *auditLogger) StmtExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.audit(query, dargs, nil, res, err)
+	l.Logger.StmtExec(d, query, dargs, res, err)
+}
+
+func (l *auditLogger) StmtExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.audit(query, nil, nvdargs, res, err)
+	l.Logger.StmtExecContext(ctx, d, query, nvdargs, res, err)
+}
+
+func (l *auditLogger) StmtQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.audit(query, dargs, nil, nil, err)
+	l.Logger.StmtQuery(d, query, dargs, err)
+}
+
+func (l *auditLogger) StmtQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.audit(query, nil, nvdargs, nil, err)
+	l.Logger.StmtQueryContext(ctx, d, query, nvdargs, err)
+}
+
+// audit writes the audit record of the statement
+// if the statement is mutating and not skipped.
+func (l *auditLogger) audit(query string, dargs []driver.Value, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	verb := queryVerb(query)
+	if !auditVerbs[verb] {
+		return
+	}
+
+	s, ierr := sqlteescan.Interpolate(query, "", dargs, nvdargs)
+	if ierr != nil || s == "" {
+		s = query
+	}
+
+	rows := int64(-1)
+	if res != nil && err == nil {
+		n, rerr := res.RowsAffected()
+		if rerr == nil {
+			rows = n
+		}
+	}
+
+	var msg string
+	if err != nil {
+		msg = err.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := make([]byte, 0, len(s)+len(msg)+160)
+	b = append(b, "time="...)
+	b = l.clock.Now().UTC().AppendFormat(b, time.RFC3339Nano)
+	b = append(b, " verb="...)
+	b = append(b, verb...)
+	b = append(b, " query="...)
+	b = strconv.AppendQuote(b, s)
+	b = append(b, " rows="...)
+	b = strconv.AppendInt(b, rows, 10)
+	b = append(b, " error="...)
+	b = strconv.AppendQuote(b, msg)
+
+	h := sha256.New()
+	h.Write(l.hash[:])
+	h.Write(b)
+	h.Sum(l.hash[:0])
+
+	b = append(b, " hash="...)
+	b = append(b, hex.EncodeToString(l.hash[:])...)
+	b = append(b, '\n')
+
+	l.sink.Write(b)
+}
@@ -0,0 +1,238 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestAuditLogger(t *testing.T) {
+	var tests = []struct {
+		name string
+		line string
+		log  func(Logger)
+		want string
+	}{
+		{
+			name: "update",
+			line: line(),
+			log: func(l Logger) {
+				l.StmtExecContext(context.Background(), time.Millisecond, "UPDATE foo SET bar = $1 WHERE id = $2", []driver.NamedValue{{Ordinal: 1, Value: "baz"}, {Ordinal: 2, Value: int64(42)}}, driver.RowsAffected(3), nil)
+			},
+			want: `time=2021-01-02T03:04:05.000000006Z verb=UPDATE query="UPDATE foo SET bar = 'baz' WHERE id = 42" rows=3 error=""`,
+		},
+		{
+			name: "failed delete",
+			line: line(),
+			log: func(l Logger) {
+				l.ConnExec(time.Millisecond, "/* cleanup */ delete from foo", nil, nil, errors.New("permission denied"))
+			},
+			want: `time=2021-01-02T03:04:05.000000006Z verb=DELETE query="/* cleanup */ delete from foo" rows=-1 error="permission denied"`,
+		},
+		{
+			name: "ddl",
+			line: line(),
+			log: func(l Logger) {
+				l.ConnExecContext(context.Background(), time.Millisecond, "DROP TABLE foo", nil, driver.ResultNoRows, nil)
+			},
+			want: `time=2021-01-02T03:04:05.000000006Z verb=DROP query="DROP TABLE foo" rows=-1 error=""`,
+		},
+		{
+			name: "insert returning",
+			line: line(),
+			log: func(l Logger) {
+				l.StmtQuery(time.Millisecond, "INSERT INTO foo (bar) VALUES (?) RETURNING id", []driver.Value{"baz"}, nil)
+			},
+			want: `time=2021-01-02T03:04:05.000000006Z verb=INSERT query="INSERT INTO foo (bar) VALUES ('baz') RETURNING id" rows=-1 error=""`,
+		},
+		{
+			name: "select",
+			line: line(),
+			log: func(l Logger) {
+				l.StmtQueryContext(context.Background(), time.Millisecond, "SELECT * FROM foo WHERE id = $1", []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}, nil)
+			},
+		},
+		{
+			name: "skip",
+			line: line(),
+			log: func(l Logger) {
+				l.ConnExecContext(context.Background(), time.Millisecond, "UPDATE foo SET bar = 1", nil, nil, driver.ErrSkip)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				topics []string
+				sink   bytes.Buffer
+			)
+
+			l := AuditLogger(EventLogger{Callback: func(e Event) { topics = append(topics, e.Topic) }}, &sink)
+			l.(*auditLogger).clock = fakeClock{now: time.Date(2021, 1, 2, 3, 4, 5, 6, time.UTC)}
+
+			tt.log(l)
+
+			if len(topics) != 1 {
+				t.Errorf("unexpected logs, expected: 1, recieved: %v %s", topics, tt.line)
+			}
+
+			record := sink.String()
+			if tt.want == "" {
+				if record != "" {
+					t.Errorf("unexpected audit record, expected: none, recieved: %q %s", record, tt.line)
+				}
+				return
+			}
+
+			i := strings.LastIndex(record, " hash=")
+			if i == -1 || record[:i] != tt.want {
+				t.Errorf("unexpected audit record, expected: %q, recieved: %q %s", tt.want, record, tt.line)
+			}
+		})
+	}
+}
+
+func TestAuditLoggerHashChain(t *testing.T) {
+	var sink bytes.Buffer
+	l := AuditLogger(NopLogger{}, &sink)
+
+	l.ConnExec(time.Millisecond, "INSERT INTO foo VALUES (1)", nil, driver.RowsAffected(1), nil)
+	l.ConnExec(time.Millisecond, "SELECT * FROM foo", nil, nil, nil)
+	l.ConnExec(time.Millisecond, "UPDATE foo SET bar = 2", nil, driver.RowsAffected(1), nil)
+
+	records := strings.Split(strings.TrimSuffix(sink.String(), "\n"), "\n")
+	if len(records) != 2 {
+		t.Fatalf("unexpected audit records, expected: 2, recieved: %q", records)
+	}
+
+	var prev [sha256.Size]byte
+	for i, record := range records {
+		j := strings.LastIndex(record, " hash=")
+		if j == -1 {
+			t.Fatalf("unexpected audit record %d, expected: hash, recieved: %q", i, record)
+		}
+
+		h := sha256.New()
+		h.Write(prev[:])
+		h.Write([]byte(record[:j]))
+		h.Sum(prev[:0])
+
+		if record[j+len(" hash="):] != hex.EncodeToString(prev[:]) {
+			t.Errorf("unexpected hash of audit record %d, expected: %x, recieved: %q", i, prev, record)
+		}
+	}
+}
+
+func TestAuditLoggerDriver(t *testing.T) {
+	var sink bytes.Buffer
+	drv := &Driver{Driver: fakedb.Driver, Logger: AuditLogger(NopLogger{}, &sink)}
+
+	c, err := drv.OpenConnector("TestAuditLoggerDriver")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|t1|name=?", "foo")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	rows, err := db.Query("SELECT|t1|name|")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+	rows.Close()
+
+	records := strings.Split(strings.TrimSuffix(sink.String(), "\n"), "\n")
+	if len(records) != 2 {
+		t.Fatalf("unexpected audit records, expected: 2, recieved: %q", records)
+	}
+
+	if !strings.Contains(records[0], ` verb=CREATE query="CREATE|t1|name=string" rows=`) {
+		t.Errorf("unexpected create audit record, recieved: %q", records[0])
+	}
+
+	if !strings.Contains(records[1], ` verb=INSERT query="INSERT|t1|name=?" rows=1 error=""`) {
+		t.Errorf("unexpected insert audit record, recieved: %q", records[1])
+	}
+}
+
+// noContextDriver opens the fakedb connections
+// without driver.ExecerContext and driver.QueryerContext.
+type noContextDriver struct{}
+
+func (noContextDriver) Open(name string) (driver.Conn, error) {
+	c, err := fakedb.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return noContextConn{Conn: c, ConnPrepareContext: c.(driver.ConnPrepareContext), SessionResetter: c.(driver.SessionResetter)}, nil
+}
+
+// noContextConn is a connection with the prepared statements only.
+type noContextConn struct {
+	driver.Conn
+	driver.ConnPrepareContext
+	driver.SessionResetter
+}
+
+func TestAuditLoggerFallback(t *testing.T) {
+	var sink bytes.Buffer
+	drv := &Driver{Driver: noContextDriver{}, Logger: AuditLogger(NopLogger{}, &sink)}
+
+	c, err := drv.OpenConnector("TestAuditLoggerFallback")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|t1|name=?", "foo")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	records := strings.Split(strings.TrimSuffix(sink.String(), "\n"), "\n")
+	if len(records) != 2 {
+		t.Fatalf("unexpected audit records, expected: 2, recieved: %q", records)
+	}
+
+	if !strings.Contains(records[0], ` verb=CREATE query="CREATE|t1|name=string" rows=`) {
+		t.Errorf("unexpected create audit record, recieved: %q", records[0])
+	}
+
+	if !strings.Contains(records[1], ` verb=INSERT query="INSERT|t1|name=?" rows=1 error=""`) {
+		t.Errorf("unexpected insert audit record, recieved: %q", records[1])
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+func init() {
+	// time.Time is the driver.Value which gob does not register
+	gob.Register(time.Time{})
+}
+
+// captureRecord is the gob encoded record of the captured statement.
+type captureRecord struct {
+	Query string
+	Args  []driver.NamedValue
+}
+
+// captureLogger is a Logger which writes the captured statements to the sink.
+type captureLogger struct {
+	NopLogger
+	mu   sync.Mutex
+	sink io.Writer
+}
+
+// CaptureLogger returns a logger which writes the mutating statements
+// (see AuditLogger) executed or queried successfully through the connections
+// and the statements to the sink for the replay. Each statement is written
+// as the self-contained record of the raw parameterized query and the typed
+// parameters (the positional parameters are captured as the ordinal ones)
+// encoded by the encoding/gob and prefixed by the varint length of the record,
+// so the records are read back one by one by the ReadCapture.
+// The statements of the parameters which gob can not encode
+// (for example the driver-specific types) are not written.
+func CaptureLogger(sink io.Writer) Logger {
+	return &captureLogger{sink: sink}
+}
+
+func (l *captureLogger) ConnExec(_ time.Duration, query string, dargs []driver.Value, _ driver.Result, err error) {
+	l.capture(query, dargs, nil, err)
+}
+
+func (l *captureLogger) ConnExecContext(_ context.Context, _ time.Duration, query string, nvdargs []driver.NamedValue, _ driver.Result, err error) {
+	l.capture(query, nil, nvdargs, err)
+}
+
+func (l *captureLogger) ConnQuery(_ time.Duration, query string, dargs []driver.Value, err error) {
+	l.capture(query, dargs, nil, err)
+}
+
+func (l *captureLogger) ConnQueryContext(_ context.Context, _ time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.capture(query, nil, nvdargs, err)
+}
+
+func (l *captureLogger) StmtExec(_ time.Duration, query string, dargs []driver.Value, _ driver.Result, err error) {
+	l.capture(query, dargs, nil, err)
+}
+
+func (l *captureLogger) StmtExecContext(_ context.Context, _ time.Duration, query string, nvdargs []driver.NamedValue, _ driver.Result, err error) {
+	l.capture(query, nil, nvdargs, err)
+}
+
+func (l *captureLogger) StmtQuery(_ time.Duration, query string, dargs []driver.Value, err error) {
+	l.capture(query, dargs, nil, err)
+}
+
+func (l *captureLogger) StmtQueryContext(_ context.Context, _ time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.capture(query, nil, nvdargs, err)
+}
+
+// capture writes the record of the statement
+// if the statement is mutating and succeeded.
+func (l *captureLogger) capture(query string, dargs []driver.Value, nvdargs []driver.NamedValue, err error) {
+	if err != nil || !auditVerbs[queryVerb(query)] {
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.Write(make([]byte, binary.MaxVarintLen64)) // room for the length
+
+	// a new encoder per record makes the record self-contained
+	err = gob.NewEncoder(&buf).Encode(captureRecord{Query: query, Args: namedValues(dargs, nvdargs)})
+	if err != nil {
+		return
+	}
+
+	b := buf.Bytes()
+	var p [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(p[:], uint64(len(b)-binary.MaxVarintLen64))
+	b = b[binary.MaxVarintLen64-n:]
+	copy(b, p[:n])
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sink.Write(b)
+}
+
+// ReadCapture reads the next statement written by the CaptureLogger
+// and returns the parameterized query and the parameters of the statement,
+// so the statement may be replayed (for example by sql.DB.ExecContext
+// with the values of the parameters). ReadCapture returns io.EOF
+// at the end of the records and does not read beyond the record.
+func ReadCapture(r io.Reader) (query string, args []driver.NamedValue, err error) {
+	n, err := binary.ReadUvarint(byteReader{r})
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", nil, io.EOF
+		}
+		return "", nil, fmt.Errorf("sqltee: capture length: %w", err)
+	}
+
+	p := make([]byte, n)
+	_, err = io.ReadFull(r, p)
+	if err != nil {
+		return "", nil, fmt.Errorf("sqltee: capture record: %w", err)
+	}
+
+	var rec captureRecord
+	err = gob.NewDecoder(bytes.NewReader(p)).Decode(&rec)
+	if err != nil {
+		return "", nil, fmt.Errorf("sqltee: capture decode: %w", err)
+	}
+
+	return rec.Query, rec.Args, nil
+}
+
+// byteReader reads one byte at a time, so the reader is not read ahead.
+type byteReader struct{ io.Reader }
+
+func (r byteReader) ReadByte() (byte, error) {
+	if br, ok := r.Reader.(io.ByteReader); ok {
+		return br.ReadByte()
+	}
+
+	var p [1]byte
+	_, err := io.ReadFull(r.Reader, p[:])
+	return p[0], err
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// onlyReader hides the io.ByteReader of the reader.
+type onlyReader struct{ r io.Reader }
+
+func (r onlyReader) Read(p []byte) (int, error) { return r.r.Read(p) }
+
+func TestCaptureLoggerRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	l := CaptureLogger(&buf)
+
+	at := time.Date(2021, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	l.ConnExecContext(context.Background(), 42, "INSERT INTO foo VALUES ($1, $2, $3, $4, $5, $6, $7)", []driver.NamedValue{
+		{Ordinal: 1, Value: int64(42)},
+		{Ordinal: 2, Value: 4.2},
+		{Ordinal: 3, Value: true},
+		{Ordinal: 4, Value: []byte("foo")},
+		{Ordinal: 5, Value: "bar"},
+		{Ordinal: 6, Value: at},
+		{Ordinal: 7, Value: nil},
+	}, nil, nil)
+	l.StmtExec(42, "UPDATE foo SET bar = ?", []driver.Value{"baz"}, nil, nil)
+	l.ConnQueryContext(context.Background(), 42, "SELECT * FROM foo WHERE id = $1", []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}, nil)
+	l.StmtExecContext(context.Background(), 42, "DELETE FROM foo WHERE id = $1", []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}, nil, errors.New("bad connection"))
+	l.StmtExecContext(context.Background(), 42, "DELETE FROM foo WHERE id = @id", []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(43)}}, nil, nil)
+
+	var expected = []struct {
+		query string
+		args  []driver.NamedValue
+	}{
+		{
+			query: "INSERT INTO foo VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			args: []driver.NamedValue{
+				{Ordinal: 1, Value: int64(42)},
+				{Ordinal: 2, Value: 4.2},
+				{Ordinal: 3, Value: true},
+				{Ordinal: 4, Value: []byte("foo")},
+				{Ordinal: 5, Value: "bar"},
+				{Ordinal: 6, Value: at},
+				{Ordinal: 7, Value: nil},
+			},
+		},
+		{query: "UPDATE foo SET bar = ?", args: []driver.NamedValue{{Ordinal: 1, Value: "baz"}}},
+		{query: "DELETE FROM foo WHERE id = @id", args: []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(43)}}},
+	}
+
+	r := onlyReader{&buf}
+	for i, e := range expected {
+		query, args, err := ReadCapture(r)
+		if err != nil {
+			t.Fatalf("read capture %d error: %#v", i, err)
+		}
+
+		if query != e.query {
+			t.Errorf("unexpected query %d, expected: %q, recieved: %q", i, e.query, query)
+		}
+
+		if !reflect.DeepEqual(args, e.args) {
+			t.Errorf("unexpected args %d, expected: %#v, recieved: %#v", i, e.args, args)
+		}
+	}
+
+	_, _, err := ReadCapture(r)
+	if err != io.EOF {
+		t.Errorf("unexpected error at the end, expected: %v, recieved: %v", io.EOF, err)
+	}
+}
+
+func TestCaptureLoggerReplay(t *testing.T) {
+	var buf bytes.Buffer
+	drv := &Driver{Driver: fakedb.Driver, Logger: CaptureLogger(&buf)}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_capture_replay")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|capture|id=int64,name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|capture|id=?,name=?", 42, "foo")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	replay := sql.OpenDB(dsnConnector{dsn: "fakedb_sqltee_test_capture_replay_target", driver: fakedb.Driver})
+	defer replay.Close()
+
+	for {
+		query, args, err := ReadCapture(&buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read capture error: %#v", err)
+		}
+
+		values := make([]interface{}, len(args))
+		for i, a := range args {
+			values[i] = a.Value
+		}
+
+		_, err = replay.Exec(query, values...)
+		if err != nil {
+			t.Fatalf("replay exec error: %#v", err)
+		}
+	}
+
+	var name string
+	err = replay.QueryRow("SELECT|capture|name|id=?", 42).Scan(&name)
+	if err != nil {
+		t.Fatalf("replay query error: %#v", err)
+	}
+
+	if name != "foo" {
+		t.Errorf("unexpected replayed name, expected: foo, recieved: %s", name)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+// ChannelPolicy is a policy of the ChannelLogger when the channel is full.
+type ChannelPolicy int
+
+const (
+	ChannelBlock      ChannelPolicy = iota // blocks the operation until the event is received
+	ChannelDrop                            // drops the new event
+	ChannelDropOldest                      // drops the oldest event from the channel and sends the new one
+)
+
+// ChannelLogger returns a logger which sends the events to the channel
+// and blocks the operation while the channel is full.
+//
+// The consumer goroutine which receives the events from the channel
+// is the responsibility of the caller, so the caller may process
+// the events asynchronously in the way it needs (for example by batches).
+func ChannelLogger(ch chan<- Event) Logger {
+	return EventLogger{Callback: func(e Event) { ch <- copyArgs(e) }}
+}
+
+// ChannelLoggerPolicy returns a logger which sends the events to the channel
+// and follows the policy while the channel is full.
+// The channel is bidirectional because ChannelDropOldest receives
+// the oldest event from the channel to drop it.
+//
+// The consumer goroutine which receives the events from the channel
+// is the responsibility of the caller.
+func ChannelLoggerPolicy(ch chan Event, policy ChannelPolicy) Logger {
+	return EventLogger{Callback: func(e Event) {
+		e = copyArgs(e)
+
+		switch policy {
+		case ChannelDrop:
+			select {
+			case ch <- e:
+			default:
+			}
+
+		case ChannelDropOldest:
+			for {
+				select {
+				case ch <- e:
+					return
+				default:
+				}
+
+				select {
+				case <-ch:
+				default:
+				}
+			}
+
+		default:
+			ch <- e
+		}
+	}}
+}
+
+// copyArgs returns the event with the copy of the arguments
+// and of the byte slices of the arguments, so the event may outlive
+// the operation (the database/sql and the drivers reuse the buffers).
+func copyArgs(e Event) Event {
+	if len(e.Args) != 0 {
+		e.Args = copyNamedValues(e.Args)
+	}
+	return e
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChannelLoggerPolicy(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		policy   ChannelPolicy
+		expected []time.Duration
+	}{
+		{
+			name:     "drop",
+			line:     line(),
+			policy:   ChannelDrop,
+			expected: []time.Duration{1, 2},
+		},
+		{
+			name:     "drop oldest",
+			line:     line(),
+			policy:   ChannelDropOldest,
+			expected: []time.Duration{3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			ch := make(chan Event, 2)
+			l := ChannelLoggerPolicy(ch, tt.policy)
+
+			for d := time.Duration(1); d <= 4; d++ {
+				l.ConnClose(d, nil)
+			}
+			close(ch)
+
+			var durations []time.Duration
+			for e := range ch {
+				if e.Topic != "conn-close" {
+					t.Errorf("unexpected topic, expected: conn-close, recieved: %s %s", e.Topic, tt.line)
+				}
+				durations = append(durations, e.Duration)
+			}
+
+			if len(durations) != len(tt.expected) || durations[0] != tt.expected[0] || durations[1] != tt.expected[1] {
+				t.Errorf("unexpected events, expected: %v, recieved: %v %s", tt.expected, durations, tt.line)
+			}
+		})
+	}
+}
+
+func TestChannelLoggerBlock(t *testing.T) {
+	for _, newLogger := range []func(chan Event) Logger{
+		func(ch chan Event) Logger { return ChannelLogger(ch) },
+		func(ch chan Event) Logger { return ChannelLoggerPolicy(ch, ChannelBlock) },
+	} {
+		ch := make(chan Event, 1)
+		l := newLogger(ch)
+
+		l.ConnClose(1, nil)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			l.ConnClose(2, nil)
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("unexpected send to the full channel, expected blocking")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		<-ch
+		<-done
+
+		if e := <-ch; e.Duration != 2 {
+			t.Errorf("unexpected event, expected duration: 2ns, recieved: %s", e.Duration)
+		}
+	}
+}
+
+func TestChannelLoggerArgs(t *testing.T) {
+	ch := make(chan Event, 1)
+	l := ChannelLogger(ch)
+
+	nvdargs := []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}
+	l.ConnExecContext(context.Background(), 1, "SELECT * FROM foo WHERE id = $1", nvdargs, nil, nil)
+	nvdargs[0].Value = int64(7)
+
+	e := <-ch
+	if len(e.Args) != 1 || e.Args[0].Value != int64(42) {
+		t.Errorf("unexpected args, expected: 42, recieved: %v", e.Args)
+	}
+
+	if e.Interpolated != "SELECT * FROM foo WHERE id = 42" {
+		t.Errorf("unexpected interpolation, expected: %q, recieved: %q", "SELECT * FROM foo WHERE id = 42", e.Interpolated)
+	}
+}
+
+func TestChannelLoggerBytes(t *testing.T) {
+	ch := make(chan Event, 1)
+	l := ChannelLogger(ch)
+
+	buf := []byte("foo")
+	l.RowsNext(1, []driver.Value{buf}, nil)
+	copy(buf, "bar")
+
+	e := <-ch
+	if len(e.Args) != 1 || !reflect.DeepEqual(e.Args[0].Value, []byte("foo")) {
+		t.Errorf("unexpected args, expected: foo, recieved: %v", e.Args)
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"time"
+)
+
+// Clock provides the current wall clock time,
+// for example the fake clock makes the tests deterministic.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is a Clock of the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
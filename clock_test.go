@@ -0,0 +1,76 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// fakeClock is a clock which always returns the same time.
+type fakeClock struct{ now time.Time }
+
+func (c fakeClock) Now() time.Time { return c.now }
+
+func TestClockDeadlineBudget(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		budgets = map[string]time.Duration{}
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			if budget, ok := DeadlineBudget(e.Ctx); ok {
+				mu.Lock()
+				defer mu.Unlock()
+				budgets[e.Topic] = budget
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+
+	deadline := time.Now().Add(time.Hour)
+	clock := fakeClock{now: deadline.Add(-1500 * time.Millisecond)}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l, Clock: clock}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_clock")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	_, err = db.ExecContext(ctx, `CREATE|tbl|id=int64`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.ExecContext(ctx, "INSERT|tbl|id=?", 42)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(budgets) == 0 {
+		t.Fatal("expected deadline budget")
+	}
+
+	for topic, budget := range budgets {
+		if budget != 1500*time.Millisecond {
+			t.Errorf("unexpected %s deadline budget, expected: %s, recieved: %s", topic, 1500*time.Millisecond, budget)
+		}
+	}
+}
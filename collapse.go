@@ -0,0 +1,254 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// collapseLogger is a Logger which suppresses the repeats
+// of the consecutive identical errors (see CollapseErrors).
+type collapseLogger struct {
+	forwarder
+	*collapseState
+}
+
+// collapseState is shared by the collapseLogger
+// and its decorators of the tagged inner loggers.
+type collapseState struct {
+	window time.Duration
+	clock  Clock
+
+	mu      sync.Mutex
+	key     string          // topic and message of the last logged error
+	since   time.Time       // time of the last logged error
+	repeats int             // number of the suppressed repeats of the last logged error
+	err     error           // last suppressed repeat
+	summary func(err error) // logs the last suppressed repeat
+	timer   *time.Timer     // logs the summary when the window elapses
+	timers  int             // number of the started timers
+}
+
+// CollapseErrors returns a logger which decorates the inner logger
+// by the deduplication of the consecutive identical errors
+// (for example of every query while the database is down).
+// The errors are identical if their topics (for example stmt-query-context)
+// and messages are equal, the successful operations and the operations
+// failed by io.EOF, driver.ErrSkip or ErrPingUnsupported
+// are logged as is and do not break the repeats.
+// The first error is logged and its repeats within the window
+// are suppressed, the repeats are summarized by the log of the last
+// repeat which error is suffixed by " repeated Nx" (for example
+// bad connection repeated 42x) when the next error differs,
+// the window of the first error elapses or the logger is closed.
+// The repeats are collapsed until the error changes
+// or the logger is closed if the window is not positive.
+// The returned logger implements io.Closer which logs the pending summary
+// and closes the inner logger if the inner logger implements io.Closer.
+// The optional tagging interfaces (for example RoleLogger)
+// tag the inner logger and the tagged loggers share the repeats.
+func CollapseErrors(inner Logger, window time.Duration) Logger {
+	return newCollapseLogger(inner, &collapseState{window: window, clock: realClock{}})
+}
+
+func newCollapseLogger(inner Logger, s *collapseState) *collapseLogger {
+	l := &collapseLogger{collapseState: s}
+	l.forwarder = forward(inner, func(inner Logger) Logger { return newCollapseLogger(inner, s) })
+	return l
+}
+
+// collapse logs the error of the topic by the log function unless
+// the error is the repeat of the last logged error within the window.
+func (l *collapseLogger) collapse(topic string, err error, log func(err error)) {
+	if !genuine(err) {
+		log(err)
+		return
+	}
+
+	now := l.clock.Now()
+	key := topic + "\x00" + err.Error()
+
+	l.mu.Lock()
+
+	if key == l.key && (l.window <= 0 || now.Sub(l.since) < l.window) {
+		l.repeats++
+		l.err, l.summary = err, log
+		if l.window > 0 && l.timer == nil {
+			l.timers++
+			n := l.timers
+			l.timer = time.AfterFunc(l.window-now.Sub(l.since), func() { l.expire(n) })
+		}
+		l.mu.Unlock()
+		return
+	}
+
+	l.stop()
+	repeats, repeated, summary := l.repeats, l.err, l.summary
+	l.key, l.since, l.repeats, l.err, l.summary = key, now, 0, nil, nil
+
+	l.mu.Unlock()
+
+	if repeats != 0 {
+		summary(fmt.Errorf("%w repeated %dx", repeated, repeats))
+	}
+	log(err)
+}
+
+// stop stops the timer of the summary, the mutex must be locked.
+func (s *collapseState) stop() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+// expire logs the summary of the suppressed repeats when the window
+// of the n-th timer elapses, the error is logged again on the next repeat.
+func (s *collapseState) expire(n int) {
+	s.mu.Lock()
+	if s.timer == nil || s.timers != n {
+		s.mu.Unlock()
+		return
+	}
+	s.timer, s.key = nil, ""
+	s.mu.Unlock()
+	s.flush()
+}
+
+// flush logs the summary of the suppressed repeats if any.
+func (s *collapseState) flush() {
+	s.mu.Lock()
+	s.stop()
+	repeats, repeated, summary := s.repeats, s.err, s.summary
+	s.repeats, s.err, s.summary = 0, nil, nil
+	s.mu.Unlock()
+
+	if repeats != 0 {
+		summary(fmt.Errorf("%w repeated %dx", repeated, repeats))
+	}
+}
+
+// Close logs the summary of the suppressed repeats
+// and closes the inner logger if it implements io.Closer.
+func (l *collapseLogger) Close() error {
+	l.flush()
+	if c, ok := l.Logger.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (l *collapseLogger) DriverOpen(name string, d time.Duration, err error) {
+	l.collapse("driver-open", err, func(err error) { l.Logger.DriverOpen(name, d, err) })
+}
+
+func (l *collapseLogger) ConnPrepare(d time.Duration, query string, err error) {
+	l.collapse("conn-prepare", err, func(err error) { l.Logger.ConnPrepare(d, query, err) })
+}
+
+func (l *collapseLogger) ConnClose(d time.Duration, err error) {
+	l.collapse("conn-close", err, func(err error) { l.Logger.ConnClose(d, err) })
+}
+
+func (l *collapseLogger) ConnBegin(d time.Duration, err error) {
+	l.collapse("conn-begin", err, func(err error) { l.Logger.ConnBegin(d, err) })
+}
+
+func (l *collapseLogger) ConnBeginTx(ctx context.Context, d time.Duration, opts driver.TxOptions, err error) {
+	l.collapse("conn-begin-tx", err, func(err error) { l.Logger.ConnBeginTx(ctx, d, opts, err) })
+}
+
+func (l *collapseLogger) ConnPrepareContext(ctx context.Context, d time.Duration, query string, err error) {
+	l.collapse("conn-prepare-context", err, func(err error) { l.Logger.ConnPrepareContext(ctx, d, query, err) })
+}
+
+func (l *collapseLogger) ConnPrepareFallback(ctx context.Context, d time.Duration, query string, err error) {
+	l.collapse("conn-prepare-fallback", err, func(err error) { logPrepareFallback(l.Logger, ctx, d, query, err) })
+}
+
+func (l *collapseLogger) ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.collapse("conn-exec", err, func(err error) { l.Logger.ConnExec(d, query, dargs, res, err) })
+}
+
+func (l *collapseLogger) ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.collapse("conn-exec-context", err, func(err error) { l.Logger.ConnExecContext(ctx, d, query, nvdargs, res, err) })
+}
+
+func (l *collapseLogger) ConnPing(ctx context.Context, d time.Duration, err error) {
+	l.collapse("conn-ping", err, func(err error) { l.Logger.ConnPing(ctx, d, err) })
+}
+
+func (l *collapseLogger) ConnExplain(ctx context.Context, d time.Duration, query string, plan []string, err error) {
+	l.collapse("conn-explain", err, func(err error) { logExplain(l.Logger, ctx, d, query, plan, err) })
+}
+
+func (l *collapseLogger) ConnQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.collapse("conn-query", err, func(err error) { l.Logger.ConnQuery(d, query, dargs, err) })
+}
+
+func (l *collapseLogger) ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.collapse("conn-query-context", err, func(err error) { l.Logger.ConnQueryContext(ctx, d, query, nvdargs, err) })
+}
+
+func (l *collapseLogger) StmtClose(d time.Duration, err error) {
+	l.collapse("stmt-close", err, func(err error) { l.Logger.StmtClose(d, err) })
+}
+
+func (l *collapseLogger) StmtCloseTotal(d, total time.Duration, err error) {
+	l.collapse("stmt-close-total", err, func(err error) { logStmtClose(l.Logger, d, total, err) })
+}
+
+func (l *collapseLogger) StmtExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.collapse("stmt-exec", err, func(err error) { l.Logger.StmtExec(d, query, dargs, res, err) })
+}
+
+func (l *collapseLogger) StmtExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.collapse("stmt-exec-context", err, func(err error) { l.Logger.StmtExecContext(ctx, d, query, nvdargs, res, err) })
+}
+
+func (l *collapseLogger) StmtQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.collapse("stmt-query", err, func(err error) { l.Logger.StmtQuery(d, query, dargs, err) })
+}
+
+func (l *collapseLogger) StmtQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.collapse("stmt-query-context", err, func(err error) { l.Logger.StmtQueryContext(ctx, d, query, nvdargs, err) })
+}
+
+func (l *collapseLogger) RowsNext(d time.Duration, dest []driver.Value, err error) {
+	l.collapse("rows-next", err, func(err error) { l.Logger.RowsNext(d, dest, err) })
+}
+
+func (l *collapseLogger) RowsNextRow(d time.Duration, row int, columns []string, dest []driver.Value, err error) {
+	l.collapse("rows-next-row", err, func(err error) { logRowsNext(l.Logger, d, row, columns, dest, err) })
+}
+
+func (l *collapseLogger) TxCommit(d time.Duration, err error) {
+	l.collapse("tx-commit", err, func(err error) { l.Logger.TxCommit(d, err) })
+}
+
+func (l *collapseLogger) TxRollback(d time.Duration, err error) {
+	l.collapse("tx-rollback", err, func(err error) { l.Logger.TxRollback(d, err) })
+}
+
+func (l *collapseLogger) TxSavepoint(ctx context.Context, d time.Duration, query, command, name string, err error) {
+	l.collapse("tx-savepoint", err, func(err error) { l.Logger.TxSavepoint(ctx, d, query, command, name, err) })
+}
+
+func (l *collapseLogger) ConnRetry(name string, d time.Duration, attempt int, backoff time.Duration, err error) {
+	if rl, ok := l.Logger.(RetryLogger); ok {
+		l.collapse("conn-retry", err, func(err error) { rl.ConnRetry(name, d, attempt, backoff, err) })
+	}
+}
+
+func (l *collapseLogger) ConnectorConnect(ctx context.Context, d time.Duration, err error) {
+	if cl, ok := l.Logger.(ConnectorLogger); ok {
+		l.collapse("connector-connect", err, func(err error) { cl.ConnectorConnect(ctx, d, err) })
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestCollapseErrors(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+
+	l := CollapseErrors(EventLogger{
+		Callback: func(e Event) {
+			if !genuine(e.Err) {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e.Topic+": "+e.Err.Error())
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}, time.Minute)
+
+	clock := &fakeClock{now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}
+	l.(*collapseLogger).clock = clock
+
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_collapse_errors")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	query := func(q string, n int) {
+		for i := 0; i < n; i++ {
+			_, err := db.Query(q)
+			if err == nil {
+				t.Fatalf("expected db query error: %s", q)
+			}
+		}
+	}
+
+	query("SELECT|collapse_foo|id|", 5)
+	query("SELECT|collapse_bar|id|", 1)
+	query("SELECT|collapse_foo|id|", 3)
+
+	// the repeats after the window are summarized
+	// and the error is logged again
+	clock.now = clock.now.Add(time.Minute)
+	query("SELECT|collapse_foo|id|", 2)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := []string{
+		`stmt-query-context: fakedb: table "collapse_foo" doesn't exist`,
+		`stmt-query-context: fakedb: table "collapse_foo" doesn't exist repeated 4x`,
+		`stmt-query-context: fakedb: table "collapse_bar" doesn't exist`,
+		`stmt-query-context: fakedb: table "collapse_foo" doesn't exist`,
+		`stmt-query-context: fakedb: table "collapse_foo" doesn't exist repeated 2x`,
+		`stmt-query-context: fakedb: table "collapse_foo" doesn't exist`,
+	}
+
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("unexpected events, expected: %q, recieved: %q", expected, events)
+	}
+}
+
+var collapseErrorsTests = []struct {
+	name     string
+	line     string
+	window   time.Duration
+	log      func(l Logger)
+	expected []string
+}{
+	{
+		name:   "different topics are not collapsed",
+		line:   line(),
+		window: time.Minute,
+		log: func(l Logger) {
+			err := errors.New("bad connection")
+			l.ConnPrepare(0, "SELECT 1", err)
+			l.ConnBegin(0, err)
+			l.ConnBegin(0, err)
+			l.ConnPrepare(0, "SELECT 1", err)
+		},
+		expected: []string{
+			"conn-prepare: bad connection",
+			"conn-begin: bad connection",
+			"conn-begin: bad connection repeated 1x",
+			"conn-prepare: bad connection",
+		},
+	},
+	{
+		name:   "end of rows is not collapsed",
+		line:   line(),
+		window: time.Minute,
+		log: func(l Logger) {
+			l.RowsNext(0, nil, io.EOF)
+			l.RowsNext(0, nil, io.EOF)
+			l.RowsNext(0, nil, io.EOF)
+		},
+		expected: []string{
+			"rows-next: EOF",
+			"rows-next: EOF",
+			"rows-next: EOF",
+		},
+	},
+	{
+		name:   "close summarizes repeats",
+		line:   line(),
+		window: time.Minute,
+		log: func(l Logger) {
+			err := errors.New("bad connection")
+			l.TxCommit(0, err)
+			l.TxCommit(0, err)
+			l.TxCommit(0, err)
+		},
+		expected: []string{
+			"tx-commit: bad connection",
+			"tx-commit: bad connection repeated 2x",
+		},
+	},
+	{
+		name:   "close summarizes repeats without window",
+		line:   line(),
+		window: 0,
+		log: func(l Logger) {
+			err := errors.New("bad connection")
+			l.TxCommit(0, err)
+			l.TxCommit(0, err)
+		},
+		expected: []string{
+			"tx-commit: bad connection",
+			"tx-commit: bad connection repeated 1x",
+		},
+	},
+}
+
+func TestCollapseErrorsTopics(t *testing.T) {
+	for _, tt := range collapseErrorsTests {
+		tt := tt
+
+		t.Run(tt.line+"/"+tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var events []string
+
+			l := CollapseErrors(EventLogger{
+				Callback: func(e Event) {
+					if e.Err != nil {
+						events = append(events, e.Topic+": "+e.Err.Error())
+					}
+				},
+				NewTimer: func() Timer { return fakeTimer{} },
+			}, tt.window)
+
+			tt.log(l)
+
+			err := l.(io.Closer).Close()
+			if err != nil {
+				t.Fatalf("close error: %#v", err)
+			}
+
+			if !reflect.DeepEqual(events, tt.expected) {
+				t.Errorf("unexpected events, expected: %q, recieved: %q", tt.expected, events)
+			}
+		})
+	}
+}
+
+func TestCollapseErrorsWindowElapses(t *testing.T) {
+	events := make(chan string, 10)
+
+	l := CollapseErrors(EventLogger{
+		Callback: func(e Event) {
+			if e.Err != nil {
+				events <- e.Topic + ": " + e.Err.Error()
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}, 10*time.Millisecond)
+
+	err := errors.New("bad connection")
+	l.TxCommit(0, err)
+	l.TxCommit(0, err)
+	l.TxCommit(0, err)
+
+	expected := []string{
+		"tx-commit: bad connection",
+		"tx-commit: bad connection repeated 2x",
+	}
+
+	// the summary is logged by the timer without the next error
+	var recieved []string
+	for range expected {
+		select {
+		case e := <-events:
+			recieved = append(recieved, e)
+		case <-time.After(time.Second):
+			t.Fatalf("unexpected events, expected: %q, recieved: %q", expected, recieved)
+		}
+	}
+
+	if !reflect.DeepEqual(recieved, expected) {
+		t.Errorf("unexpected events, expected: %q, recieved: %q", expected, recieved)
+	}
+
+	// the error is logged again after the window
+	l.TxCommit(0, err)
+
+	select {
+	case e := <-events:
+		if e != expected[0] {
+			t.Errorf("unexpected event, expected: %q, recieved: %q", expected[0], e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected error after the window")
+	}
+}
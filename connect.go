@@ -0,0 +1,26 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"time"
+)
+
+// ConnectorLogger may be implemented by the Logger to log
+// each Connector.Connect (see Driver.LogConnect). The database/sql
+// connects when the pool needs the new physical connection, so the
+// frequency and the duration of the connector-connect are the proxy
+// for the pressure of the connection pool.
+type ConnectorLogger interface {
+	ConnectorConnect(ctx context.Context, d time.Duration, err error)
+}
+
+func (l recordLogger) ConnectorConnect(ctx context.Context, d time.Duration, err error) {
+	l.recorder.record("connector-connect", d, err)
+	if cl, ok := l.Logger.(ConnectorLogger); ok {
+		cl.ConnectorConnect(ctx, d, err)
+	}
+}
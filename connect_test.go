@@ -0,0 +1,68 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestConnectorConnect(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		topics []string
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			topics = append(topics, e.Topic)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l, LogConnect: true}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_connector_connect")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("db conn error: %#v", err)
+	}
+
+	// the idle connection is reused without the connect
+	conn.Close()
+
+	conn, err = db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("db conn error: %#v", err)
+	}
+
+	conn.Close()
+	db.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := []string{"driver-open", "connector-connect", "conn-close"}
+	if !reflect.DeepEqual(topics, expected) {
+		t.Errorf("unexpected topics, expected: %v, recieved: %v", expected, topics)
+	}
+
+	stat, ok := drv.Stats()["connector-connect"]
+	if !ok || stat.Count != 1 {
+		t.Errorf("unexpected statistic of the connector-connect, expected: 1 operation, recieved: %+v", stat)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type considerTxKey struct{}
+
+// ConsiderTransaction returns the number of the consecutive writes
+// (INSERT, UPDATE or DELETE) executed outside the transaction under
+// the same request context and true if the number reaches
+// the Driver.ConsiderTxThreshold, so the loggers may hint that
+// the writes autocommitted each are likely to belong to one transaction.
+func ConsiderTransaction(ctx context.Context) (int, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	n, ok := ctx.Value(considerTxKey{}).(int)
+	return n, ok
+}
+
+// considerTxIdle is the time after the last write of the request context
+// when the count of the writes is forgotten.
+const considerTxIdle = time.Minute
+
+// considerTx is the detection of the writes outside the transaction of the connection.
+type considerTx struct {
+	threshold int
+	counts    *considerTxCounts // shared by the connections of the driver
+}
+
+// considerTxCounts counts the consecutive writes outside the transaction per request context.
+type considerTxCounts struct {
+	mu     sync.Mutex
+	counts map[context.Context]*considerTxCount
+	swept  time.Time // time of the last removal of the idle counts
+}
+
+type considerTxCount struct {
+	last time.Time // time of the last write
+	n    int
+}
+
+// count counts the write of the request context at the time
+// and returns the number of the consecutive writes.
+func (d *considerTxCounts) count(ctx context.Context, now time.Time) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.counts == nil {
+		d.counts = make(map[context.Context]*considerTxCount)
+	}
+
+	if now.Sub(d.swept) > considerTxIdle {
+		for k, c := range d.counts {
+			if now.Sub(c.last) > considerTxIdle {
+				delete(d.counts, k)
+			}
+		}
+		d.swept = now
+	}
+
+	c, ok := d.counts[ctx]
+	if !ok {
+		c = &considerTxCount{}
+		d.counts[ctx] = c
+	}
+	c.last = now
+	c.n++
+
+	return c.n
+}
+
+// reset forgets the writes of the request context.
+func (d *considerTxCounts) reset(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.counts, ctx)
+}
+
+// withConsiderTx counts the successful write outside the transaction
+// under the request context and returns a copy of the parent context
+// which stores the number of the consecutive writes or the parent context
+// as is if the detection is disabled, the query is not a write
+// or the number is below the threshold. The write in the transaction
+// resets the number.
+func (c connection) withConsiderTx(ctx, rctx context.Context, query string, err error) context.Context {
+	if c.considerTx.threshold <= 0 || rctx == nil || err != nil || !isWrite(query) {
+		return ctx
+	}
+
+	if c.inTx != nil && atomic.LoadInt32(c.inTx) != 0 {
+		c.considerTx.counts.reset(rctx)
+		return ctx
+	}
+
+	n := c.considerTx.counts.count(rctx, c.clock.Now())
+	if n < c.considerTx.threshold {
+		return ctx
+	}
+
+	return context.WithValue(ctx, considerTxKey{}, n)
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestConsiderTransaction(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		flagged []int
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			if n, ok := ConsiderTransaction(e.Ctx); ok {
+				mu.Lock()
+				defer mu.Unlock()
+				flagged = append(flagged, n)
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l, ConsiderTxThreshold: 5}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_consider_transaction")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err = db.ExecContext(ctx, "CREATE|consider_tx|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	for i := 1; i <= 4; i++ {
+		_, err = db.ExecContext(ctx, "INSERT|consider_tx|id=?", i)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+
+		// the reads do not break the writes
+		rows, err := db.QueryContext(ctx, "SELECT|consider_tx|id|")
+		if err != nil {
+			t.Fatalf("db query error: %#v", err)
+		}
+		rows.Close()
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("db begin error: %#v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT|consider_tx|id=?", 5)
+	if err != nil {
+		t.Fatalf("tx exec error: %#v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf("tx commit error: %#v", err)
+	}
+
+	for i := 6; i <= 11; i++ {
+		_, err = db.ExecContext(ctx, "INSERT|consider_tx|id=?", i)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := []int{5, 6}
+	if !reflect.DeepEqual(flagged, expected) {
+		t.Errorf("unexpected consecutive writes, expected: %v, recieved: %v", expected, flagged)
+	}
+}
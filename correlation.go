@@ -0,0 +1,145 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql/driver"
+	"strings"
+	"sync/atomic"
+)
+
+// correlationIDs is the last generated correlation id.
+var correlationIDs uint64
+
+// CorrelationLogger may be implemented by the Logger to tag the logs
+// of one logical query or execution (for example the conn-query-context
+// skipped by the driver, the prepare, the stmt-query-context,
+// each rows-next and the stmt-close of one db.Query)
+// by the shared correlation id (see Driver.Correlate).
+type CorrelationLogger interface {
+	WithCorrelation(id uint64) Logger
+}
+
+// correlation is a correlation id of the query skipped by the driver
+// (for example by driver.ErrSkip of the conn-query-context) which is
+// shared with the subsequent prepare of the same query by the database/sql.
+type correlation struct {
+	query string
+	id    uint64
+}
+
+// correlated returns a copy of the connection which logs through the logger
+// tagged by the new correlation id or the connection as is
+// if the correlation is disabled. The connection is used by
+// one goroutine at a time so the skipped query is stored without locking.
+func (c connection) correlated() (connection, uint64) {
+	if c.correlation == nil {
+		return c, 0
+	}
+
+	*c.correlation = correlation{}
+
+	return c.withCorrelation(atomic.AddUint64(&correlationIDs, 1))
+}
+
+// correlatedPrepare returns a copy of the connection which logs through
+// the logger tagged by the correlation id of the skipped query
+// if the query is prepared after the skip or by the new correlation id.
+func (c connection) correlatedPrepare(query string) connection {
+	if c.correlation == nil {
+		return c
+	}
+
+	id := c.correlation.id
+	if id == 0 || c.correlation.query != query {
+		id = atomic.AddUint64(&correlationIDs, 1)
+	}
+
+	*c.correlation = correlation{}
+
+	c, _ = c.withCorrelation(id)
+	return c
+}
+
+func (c connection) withCorrelation(id uint64) (connection, uint64) {
+	if l, ok := c.Logger.(CorrelationLogger); ok {
+		c.Logger = l.WithCorrelation(id)
+	}
+	return c, id
+}
+
+// skipped stores the correlation id of the query skipped by the driver.
+func (c connection) skipped(query string, id uint64, err error) {
+	if c.correlation != nil && err == driver.ErrSkip {
+		*c.correlation = correlation{query: query, id: id}
+	}
+}
+
+// EventNode is a node of the tree of the correlated events.
+type EventNode struct {
+	Event    Event
+	Children []*EventNode
+}
+
+// EventTree is a tree of the events of one logical query or execution.
+type EventTree struct {
+	Correlation uint64       // correlation id shared by the events
+	Nodes       []*EventNode // top level events in order of logging
+}
+
+// EventTrees reconstructs the trees of the events grouped by the correlation
+// id in order of the first event of each tree (for example for tracing UIs).
+// The statement events (for example stmt-query-context and stmt-close)
+// are the children of the preceding prepare and the rows-next events
+// are the children of the preceding query, other events are at the top level.
+// Events without the correlation id are skipped.
+func EventTrees(events []Event) []EventTree {
+	var (
+		trees   []EventTree
+		indexes = map[uint64]int{}
+		prepare = map[uint64]*EventNode{} // last prepare of the tree
+		query   = map[uint64]*EventNode{} // last query of the tree
+	)
+
+	for _, e := range events {
+		if e.Correlation == 0 {
+			continue
+		}
+
+		i, ok := indexes[e.Correlation]
+		if !ok {
+			i = len(trees)
+			indexes[e.Correlation] = i
+			trees = append(trees, EventTree{Correlation: e.Correlation})
+		}
+
+		n := &EventNode{Event: e}
+
+		var parent *EventNode
+		switch {
+		case e.Topic == "rows-next":
+			parent = query[e.Correlation]
+		case strings.HasPrefix(e.Topic, "stmt-"):
+			parent = prepare[e.Correlation]
+		}
+
+		if parent != nil {
+			parent.Children = append(parent.Children, n)
+		} else {
+			trees[i].Nodes = append(trees[i].Nodes, n)
+		}
+
+		switch e.Topic {
+		case "conn-prepare", "conn-prepare-context", "conn-prepare-fallback":
+			prepare[e.Correlation] = n
+		case "conn-query", "conn-query-context", "stmt-query", "stmt-query-context":
+			if e.Err == nil {
+				query[e.Correlation] = n
+			}
+		}
+	}
+
+	return trees
+}
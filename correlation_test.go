@@ -0,0 +1,193 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// treeString returns the topics of the tree
+// where the children are enclosed in the brackets.
+func treeString(nodes []*EventNode) string {
+	var topics []string
+	for _, n := range nodes {
+		topic := n.Event.Topic
+		if len(n.Children) != 0 {
+			topic += " [" + treeString(n.Children) + "]"
+		}
+		topics = append(topics, topic)
+	}
+	return strings.Join(topics, ", ")
+}
+
+func TestCorrelation(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+
+	drv := &Driver{Driver: fakedb.Driver, Logger: l, Correlate: true}
+
+	c, err := drv.OpenConnector("TestCorrelation")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|t1|name=string,age=int32")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	for _, name := range []string{"foo", "bar", "foo"} {
+		_, err = db.Exec("INSERT|t1|name=?,age=?", name, 42)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+	}
+
+	rows, err := db.Query("SELECT|t1|age|name=?", "foo")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+
+	var n int
+	for rows.Next() {
+		n++
+	}
+
+	err = rows.Close()
+	if err != nil {
+		t.Fatalf("rows close error: %#v", err)
+	}
+
+	if n != 2 {
+		t.Fatalf("unexpected rows, expected: 2, recieved: %d", n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var query []Event
+	for _, e := range events {
+		if e.Correlation == 0 && e.Topic != "driver-open" {
+			t.Errorf("unexpected uncorrelated event: %s %s", e.Topic, e.Query)
+		}
+		if len(query) == 0 && e.Topic == "conn-query-context" {
+			query = append(query, e)
+		} else if len(query) != 0 && e.Correlation == query[0].Correlation {
+			query = append(query, e)
+		}
+	}
+
+	var topics []string
+	for _, e := range query {
+		topics = append(topics, e.Topic)
+	}
+
+	expected := "conn-query-context conn-prepare-context stmt-query-context rows-next rows-next rows-next stmt-close"
+	if strings.Join(topics, " ") != expected {
+		t.Errorf("unexpected correlated events, expected: %s, recieved: %s", expected, strings.Join(topics, " "))
+	}
+
+	if query[0].Err != driver.ErrSkip {
+		t.Errorf("unexpected conn-query-context error, expected: %v, recieved: %v", driver.ErrSkip, query[0].Err)
+	}
+
+	trees := EventTrees(events)
+
+	// create and three inserts each of the skipped conn-exec-context,
+	// the conn-prepare-context, the stmt-exec-context and the stmt-close
+	// and the query
+	if len(trees) != 5 {
+		t.Fatalf("unexpected trees, expected: 5, recieved: %d", len(trees))
+	}
+
+	ids := map[uint64]bool{}
+	for i, tree := range trees {
+		if ids[tree.Correlation] {
+			t.Errorf("unexpected duplicate correlation id of tree %d: %d", i, tree.Correlation)
+		}
+		ids[tree.Correlation] = true
+
+		expected := "conn-exec-context, conn-prepare-context [stmt-exec-context, stmt-close]"
+		if i == len(trees)-1 {
+			expected = "conn-query-context, conn-prepare-context [stmt-query-context [rows-next, rows-next, rows-next], stmt-close]"
+		}
+
+		if s := treeString(tree.Nodes); s != expected {
+			t.Errorf("unexpected tree %d, expected: %s, recieved: %s", i, expected, s)
+		}
+	}
+}
+
+func TestCorrelationDisabled(t *testing.T) {
+	var correlations []uint64
+	l := EventLogger{
+		Callback: func(e Event) { correlations = append(correlations, e.Correlation) },
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("TestCorrelationDisabled")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	if fmt.Sprint(correlations) != "[0 0 0 0 0]" {
+		t.Errorf("unexpected correlation ids, expected: [0 0 0 0 0], recieved: %v", correlations)
+	}
+}
+
+func TestEventTrees(t *testing.T) {
+	events := []Event{
+		{Topic: "conn-query-context", Correlation: 1, Err: driver.ErrSkip},
+		{Topic: "conn-exec-context", Correlation: 2},
+		{Topic: "conn-prepare-context", Correlation: 1},
+		{Topic: "conn-begin-tx"},
+		{Topic: "stmt-query-context", Correlation: 1},
+		{Topic: "rows-next", Correlation: 1},
+		{Topic: "stmt-close", Correlation: 1},
+		{Topic: "conn-query-context", Correlation: 3},
+		{Topic: "rows-next", Correlation: 3},
+	}
+
+	var s []string
+	for _, tree := range EventTrees(events) {
+		s = append(s, fmt.Sprintf("%d: %s", tree.Correlation, treeString(tree.Nodes)))
+	}
+
+	expected := "1: conn-query-context, conn-prepare-context [stmt-query-context [rows-next], stmt-close]; 2: conn-exec-context; 3: conn-query-context [rows-next]"
+	if strings.Join(s, "; ") != expected {
+		t.Errorf("unexpected trees, expected: %s, recieved: %s", expected, strings.Join(s, "; "))
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+// ContextLoggerFunc returns a Logger which forwards each log of the operation
+// with the context to the logger extracted from the context (for example
+// to the request-scoped logger carried by the context of the request).
+//
+// The logs of the operations without the context and the timers
+// are provided by the fallback logger extracted from context.Background
+// once by ContextLoggerFunc, so the extract function should return
+// the default logger if the context carries no logger. The timing
+// of all the operations is measured by the timer of the fallback logger
+// because the context of the operation is unknown when the timer is started.
+// NopLogger is used if the extract function returns nil.
+// The optional tagging interfaces (for example RoleLogger)
+// tag the extracted loggers and the fallback logger.
+func ContextLoggerFunc(extract func(ctx context.Context) Logger) Logger {
+	fallback := extract(context.Background())
+	if fallback == nil {
+		fallback = NopLogger{}
+	}
+
+	return newContextLogger(extract, fallback)
+}
+
+func newContextLogger(extract func(ctx context.Context) Logger, fallback Logger) contextLogger {
+	l := contextLogger{extract: extract, fallback: fallback}
+	l.forwarder = forwarder{Logger: fallback, with: l.with}
+	return l
+}
+
+type contextLogger struct {
+	forwarder
+	extract  func(ctx context.Context) Logger
+	fallback Logger
+}
+
+// with returns the context logger of the tagged extracted loggers.
+func (l contextLogger) with(tag func(Logger) Logger) Logger {
+	return newContextLogger(func(ctx context.Context) Logger {
+		if logger := l.extract(ctx); logger != nil {
+			return tag(logger)
+		}
+		return nil
+	}, tag(l.fallback))
+}
+
+// logger returns the logger extracted from the context or the fallback logger.
+func (l contextLogger) logger(ctx context.Context) Logger {
+	if ctx == nil {
+		return l.fallback
+	}
+
+	if logger := l.extract(ctx); logger != nil {
+		return logger
+	}
+
+	return l.fallback
+}
+
+func (l contextLogger) DriverOpen(name string, d time.Duration, err error) {
+	l.fallback.DriverOpen(name, d, err)
+}
+
+func (l contextLogger) ConnPrepare(d time.Duration, query string, err error) {
+	l.fallback.ConnPrepare(d, query, err)
+}
+
+func (l contextLogger) ConnClose(d time.Duration, err error) {
+	l.fallback.ConnClose(d, err)
+}
+
+func (l contextLogger) ConnBegin(d time.Duration, err error) {
+	l.fallback.ConnBegin(d, err)
+}
+
+func (l contextLogger) ConnBeginTx(ctx context.Context, d time.Duration, opts driver.TxOptions, err error) {
+	l.logger(ctx).ConnBeginTx(ctx, d, opts, err)
+}
+
+func (l contextLogger) ConnPrepareContext(ctx context.Context, d time.Duration, query string, err error) {
+	l.logger(ctx).ConnPrepareContext(ctx, d, query, err)
+}
+
+func (l contextLogger) ConnPrepareFallback(ctx context.Context, d time.Duration, query string, err error) {
+	logPrepareFallback(l.logger(ctx), ctx, d, query, err)
+}
+
+func (l contextLogger) ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.fallback.ConnExec(d, query, dargs, res, err)
+}
+
+func (l contextLogger) ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.logger(ctx).ConnExecContext(ctx, d, query, nvdargs, res, err)
+}
+
+func (l contextLogger) ConnPing(ctx context.Context, d time.Duration, err error) {
+	l.logger(ctx).ConnPing(ctx, d, err)
+}
+
+func (l contextLogger) ConnRaw() {
+	logRaw(l.fallback)
+}
+
+func (l contextLogger) ConnExplain(ctx context.Context, d time.Duration, query string, plan []string, err error) {
+	logExplain(l.logger(ctx), ctx, d, query, plan, err)
+}
+
+func (l contextLogger) ConnQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.fallback.ConnQuery(d, query, dargs, err)
+}
+
+func (l contextLogger) ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.logger(ctx).ConnQueryContext(ctx, d, query, nvdargs, err)
+}
+
+func (l contextLogger) StmtClose(d time.Duration, err error) {
+	l.fallback.StmtClose(d, err)
+}
+
+func (l contextLogger) StmtCloseTotal(d, total time.Duration, err error) {
+	logStmtClose(l.fallback, d, total, err)
+}
+
+func (l contextLogger) StmtExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.fallback.StmtExec(d, query, dargs, res, err)
+}
+
+func (l contextLogger) StmtExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.logger(ctx).StmtExecContext(ctx, d, query, nvdargs, res, err)
+}
+
+func (l contextLogger) StmtQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.fallback.StmtQuery(d, query, dargs, err)
+}
+
+func (l contextLogger) StmtQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.logger(ctx).StmtQueryContext(ctx, d, query, nvdargs, err)
+}
+
+func (l contextLogger) RowsNext(d time.Duration, dest []driver.Value, err error) {
+	l.fallback.RowsNext(d, dest, err)
+}
+
+func (l contextLogger) RowsNextRow(d time.Duration, row int, columns []string, dest []driver.Value, err error) {
+	logRowsNext(l.fallback, d, row, columns, dest, err)
+}
+
+func (l contextLogger) TxCommit(d time.Duration, err error) {
+	l.fallback.TxCommit(d, err)
+}
+
+func (l contextLogger) TxRollback(d time.Duration, err error) {
+	l.fallback.TxRollback(d, err)
+}
+
+func (l contextLogger) TxSavepoint(ctx context.Context, d time.Duration, query, command, name string, err error) {
+	l.logger(ctx).TxSavepoint(ctx, d, query, command, name, err)
+}
+
+func (l contextLogger) ConnectorConnect(ctx context.Context, d time.Duration, err error) {
+	if cl, ok := l.logger(ctx).(ConnectorLogger); ok {
+		cl.ConnectorConnect(ctx, d, err)
+	}
+}
+
+func (l contextLogger) Timer() Timer {
+	return l.fallback.Timer()
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+type requestLoggerKey struct{}
+
+// topicRecorder is a logger which records the topics of the events.
+type topicRecorder struct {
+	mu     sync.Mutex
+	topics []string
+}
+
+func (r *topicRecorder) logger() Logger {
+	return EventLogger{
+		Callback: func(e Event) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.topics = append(r.topics, e.Topic)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+}
+
+func (r *topicRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.topics, " ")
+}
+
+func TestContextLoggerFunc(t *testing.T) {
+	var fallback, request topicRecorder
+
+	l := ContextLoggerFunc(func(ctx context.Context) Logger {
+		if logger, ok := ctx.Value(requestLoggerKey{}).(Logger); ok {
+			return logger
+		}
+		return fallback.logger()
+	})
+
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("TestContextLoggerFunc")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), requestLoggerKey{}, request.logger())
+
+	_, err = db.ExecContext(ctx, "INSERT|t1|name=?", "foo")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("db close error: %#v", err)
+	}
+
+	expected := "conn-exec-context conn-prepare-context stmt-exec-context"
+	if request.String() != expected {
+		t.Errorf("unexpected request logs, expected: %s, recieved: %s", expected, request.String())
+	}
+
+	expected = "driver-open conn-exec-context conn-prepare-context stmt-exec-context stmt-close stmt-close conn-close"
+	if fallback.String() != expected {
+		t.Errorf("unexpected fallback logs, expected: %s, recieved: %s", expected, fallback.String())
+	}
+}
+
+func TestContextLoggerFuncNil(t *testing.T) {
+	l := ContextLoggerFunc(func(context.Context) Logger { return nil })
+
+	l.ConnExecContext(context.Background(), 42, "SELECT 1", nil, nil, nil)
+	l.TxCommit(42, nil)
+
+	if _, ok := l.Timer().(nopTimer); !ok {
+		t.Errorf("unexpected timer, expected: NopLogger timer, recieved: %T", l.Timer())
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"time"
+)
+
+type deadlineBudgetKey struct{}
+
+// DeadlineBudget returns the time remaining until the deadline
+// of the context at the start of the operation (for example
+// sql.DB.ExecContext with context.WithTimeout) and true
+// or false if the context of the operation has no deadline.
+// Loggers are expected to compare the budget with the duration
+// of the operation on the context.DeadlineExceeded error.
+func DeadlineBudget(ctx context.Context) (time.Duration, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	budget, ok := ctx.Value(deadlineBudgetKey{}).(time.Duration)
+	return budget, ok
+}
+
+// withDeadlineBudget returns a copy of the parent context which stores
+// the time remaining until the deadline by the clock or the parent context
+// as is if it has no deadline.
+func withDeadlineBudget(ctx context.Context, clock Clock) context.Context {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx
+	}
+
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	return context.WithValue(ctx, deadlineBudgetKey{}, deadline.Sub(clock.Now()))
+}
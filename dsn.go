@@ -0,0 +1,115 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SanitizeDSN returns the data source name with credentials stripped.
+// Supported forms are key=value (password=secret or password = secret is removed),
+// URL (password is removed from the userinfo and from the query)
+// and user:password@tcp(host)/dbname (password is removed,
+// the password may contain the @ characters).
+// SanitizeDSN returns an empty string if URL can not be parsed.
+func SanitizeDSN(name string) string {
+	if strings.Contains(name, "://") {
+		u, err := url.Parse(name)
+		if err != nil {
+			return ""
+		}
+
+		if u.User != nil {
+			u.User = url.User(u.User.Username())
+		}
+
+		if q := u.Query(); q.Get("password") != "" {
+			q.Del("password")
+			u.RawQuery = q.Encode()
+		}
+
+		return u.String()
+	}
+
+	// the password may contain the @ characters,
+	// so the userinfo ends at the last @ before the database name
+	end := len(name)
+	if i := strings.LastIndex(name, "/"); i != -1 {
+		end = i
+	}
+	if i := strings.LastIndex(name[:end], "@"); i != -1 {
+		user := name[:i]
+		if j := strings.Index(user, ":"); j != -1 {
+			user = user[:j]
+		}
+
+		if !strings.Contains(user, "=") {
+			return user + name[i:]
+		}
+	}
+
+	var b strings.Builder
+
+	for _, kv := range splitDSN(name) {
+		if i := strings.Index(kv, "="); i != -1 && strings.EqualFold(strings.TrimSpace(kv[:i]), "password") {
+			continue
+		}
+
+		if b.Len() != 0 {
+			b.WriteByte(' ')
+		}
+
+		b.WriteString(kv)
+	}
+
+	return b.String()
+}
+
+// splitDSN splits key=value data source name into pairs
+// with respect to single-quoted values and backslash escapes.
+func splitDSN(name string) []string {
+	var (
+		pairs  []string
+		quoted bool
+		escape bool
+		start  = -1
+	)
+
+	for i, r := range name {
+		switch {
+		case escape:
+			escape = false
+
+		case r == '\\':
+			escape = true
+
+		case r == '\'':
+			quoted = !quoted
+
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			// spaces around the = are the part of the pair (for example password = secret)
+			if start != -1 && (strings.HasSuffix(strings.TrimRight(name[start:i], " \t\n"), "=") ||
+				strings.HasPrefix(strings.TrimLeft(name[i:], " \t\n"), "=")) {
+				continue
+			}
+			if start != -1 {
+				pairs = append(pairs, name[start:i])
+				start = -1
+			}
+			continue
+		}
+
+		if start == -1 {
+			start = i
+		}
+	}
+
+	if start != -1 {
+		pairs = append(pairs, strings.TrimRight(name[start:], " \t\n"))
+	}
+
+	return pairs
+}
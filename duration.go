@@ -0,0 +1,41 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+type durationRecorderKey struct{}
+
+// WithDurationRecorder returns a copy of the parent context in which
+// the d is used to record the measured execution time of the operation
+// (for example sql.DB.ExecContext) called with the context.
+//
+// The context is expected to be used for a single operation at a time:
+// if database/sql performs several driver calls (for example prepare
+// and execution of the statement) then the duration of the last
+// (outermost) call is recorded. Concurrent operations with the same
+// context race on the d.
+func WithDurationRecorder(ctx context.Context, d *time.Duration) context.Context {
+	return context.WithValue(ctx, durationRecorderKey{}, d)
+}
+
+// recordDuration writes the duration into the recorder of the context
+// unless the operation is skipped by driver.ErrSkip and returns the duration.
+func recordDuration(ctx context.Context, d time.Duration, err error) time.Duration {
+	if ctx == nil || errors.Is(err, driver.ErrSkip) {
+		return d
+	}
+
+	if p, ok := ctx.Value(durationRecorderKey{}).(*time.Duration); ok && p != nil {
+		*p = d
+	}
+
+	return d
+}
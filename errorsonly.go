@@ -0,0 +1,194 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrorsOnly returns a Logger which forwards to the inner logger
+// only the logs of the operations failed by the genuine errors,
+// the logs of the successful operations, of the operations
+// failed by driver.ErrSkip or ErrPingUnsupported and of the end
+// of the rows (io.EOF of the rows-next) are dropped
+// before the inner logger formats them (for example interpolates
+// the parameters), so the successful operations cost almost nothing.
+// The optional tagging interfaces (for example RoleLogger)
+// tag the inner logger.
+func ErrorsOnly(inner Logger) Logger {
+	return errorsOnlyLogger{forward(inner, ErrorsOnly)}
+}
+
+type errorsOnlyLogger struct {
+	forwarder
+}
+
+// genuine reports whether the error is the genuine failure of the operation,
+// the end of the rows (io.EOF of the rows-next) is not a failure.
+func genuine(err error) bool {
+	return err != nil && err != io.EOF && !errors.Is(err, driver.ErrSkip) && !errors.Is(err, ErrPingUnsupported)
+}
+
+func (l errorsOnlyLogger) DriverOpen(name string, d time.Duration, err error) {
+	if genuine(err) {
+		l.Logger.DriverOpen(name, d, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnPrepare(d time.Duration, query string, err error) {
+	if genuine(err) {
+		l.Logger.ConnPrepare(d, query, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnClose(d time.Duration, err error) {
+	if genuine(err) {
+		l.Logger.ConnClose(d, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnBegin(d time.Duration, err error) {
+	if genuine(err) {
+		l.Logger.ConnBegin(d, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnBeginTx(ctx context.Context, d time.Duration, opts driver.TxOptions, err error) {
+	if genuine(err) {
+		l.Logger.ConnBeginTx(ctx, d, opts, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnPrepareContext(ctx context.Context, d time.Duration, query string, err error) {
+	if genuine(err) {
+		l.Logger.ConnPrepareContext(ctx, d, query, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnPrepareFallback(ctx context.Context, d time.Duration, query string, err error) {
+	if genuine(err) {
+		logPrepareFallback(l.Logger, ctx, d, query, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	if genuine(err) {
+		l.Logger.ConnExec(d, query, dargs, res, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	if genuine(err) {
+		l.Logger.ConnExecContext(ctx, d, query, nvdargs, res, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnPing(ctx context.Context, d time.Duration, err error) {
+	if genuine(err) {
+		l.Logger.ConnPing(ctx, d, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnRaw() {}
+
+func (l errorsOnlyLogger) ConnExplain(ctx context.Context, d time.Duration, query string, plan []string, err error) {
+	if genuine(err) {
+		logExplain(l.Logger, ctx, d, query, plan, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	if genuine(err) {
+		l.Logger.ConnQuery(d, query, dargs, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	if genuine(err) {
+		l.Logger.ConnQueryContext(ctx, d, query, nvdargs, err)
+	}
+}
+
+func (l errorsOnlyLogger) StmtClose(d time.Duration, err error) {
+	if genuine(err) {
+		l.Logger.StmtClose(d, err)
+	}
+}
+
+func (l errorsOnlyLogger) StmtCloseTotal(d, total time.Duration, err error) {
+	if genuine(err) {
+		logStmtClose(l.Logger, d, total, err)
+	}
+}
+
+func (l errorsOnlyLogger) StmtExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	if genuine(err) {
+		l.Logger.StmtExec(d, query, dargs, res, err)
+	}
+}
+
+func (l errorsOnlyLogger) StmtExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	if genuine(err) {
+		l.Logger.StmtExecContext(ctx, d, query, nvdargs, res, err)
+	}
+}
+
+func (l errorsOnlyLogger) StmtQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	if genuine(err) {
+		l.Logger.StmtQuery(d, query, dargs, err)
+	}
+}
+
+func (l errorsOnlyLogger) StmtQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	if genuine(err) {
+		l.Logger.StmtQueryContext(ctx, d, query, nvdargs, err)
+	}
+}
+
+func (l errorsOnlyLogger) RowsNext(d time.Duration, dest []driver.Value, err error) {
+	if genuine(err) {
+		l.Logger.RowsNext(d, dest, err)
+	}
+}
+
+func (l errorsOnlyLogger) RowsNextRow(d time.Duration, row int, columns []string, dest []driver.Value, err error) {
+	if genuine(err) {
+		logRowsNext(l.Logger, d, row, columns, dest, err)
+	}
+}
+
+func (l errorsOnlyLogger) TxCommit(d time.Duration, err error) {
+	if genuine(err) {
+		l.Logger.TxCommit(d, err)
+	}
+}
+
+func (l errorsOnlyLogger) TxRollback(d time.Duration, err error) {
+	if genuine(err) {
+		l.Logger.TxRollback(d, err)
+	}
+}
+
+func (l errorsOnlyLogger) TxSavepoint(ctx context.Context, d time.Duration, query, command, name string, err error) {
+	if genuine(err) {
+		l.Logger.TxSavepoint(ctx, d, query, command, name, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnRetry(name string, d time.Duration, attempt int, backoff time.Duration, err error) {
+	if rl, ok := l.Logger.(RetryLogger); ok && genuine(err) {
+		rl.ConnRetry(name, d, attempt, backoff, err)
+	}
+}
+
+func (l errorsOnlyLogger) ConnectorConnect(ctx context.Context, d time.Duration, err error) {
+	if cl, ok := l.Logger.(ConnectorLogger); ok && genuine(err) {
+		cl.ConnectorConnect(ctx, d, err)
+	}
+}
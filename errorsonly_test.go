@@ -0,0 +1,147 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestErrorsOnly(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+
+	l := ErrorsOnly(EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	})
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_errors_only")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|errors_only|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|errors_only|id=?", 42)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	mu.Lock()
+	if len(events) != 0 {
+		t.Errorf("unexpected events of the successful executions, expected: none, recieved: %+v", events)
+	}
+	mu.Unlock()
+
+	stmt, err := db.Prepare("INSERT|errors_only|id=?")
+	if err != nil {
+		t.Fatalf("db prepare error: %#v", err)
+	}
+	defer stmt.Close()
+
+	// the table is wiped after the statement is prepared,
+	// so the execution of the statement fails
+	_, err = db.Exec("WIPE")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = stmt.Exec(42)
+	if err == nil {
+		t.Fatal("expected stmt exec error")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(events) != 1 {
+		t.Fatalf("unexpected number of the events, expected: 1, recieved: %d", len(events))
+	}
+
+	e := events[0]
+	if e.Topic != "stmt-exec-context" || e.Query != "INSERT|errors_only|id=?" || e.Err == nil {
+		t.Errorf("unexpected event, expected: failed stmt-exec-context, recieved: %+v", e)
+	}
+
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}
+	if !reflect.DeepEqual(e.Args, args) {
+		t.Errorf("unexpected args, expected: %v, recieved: %v", args, e.Args)
+	}
+}
+
+func TestErrorsOnlyRowsEOF(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+
+	l := ErrorsOnly(EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	})
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_errors_only_rows_eof")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|errors_only_rows|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|errors_only_rows|id=?", 42)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	rows, err := db.Query("SELECT|errors_only_rows|id|")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+
+	var n int
+	for rows.Next() {
+		n++
+	}
+	if err = rows.Err(); err != nil || n != 1 {
+		t.Fatalf("unexpected rows, expected: 1 row, recieved: %d %#v", n, err)
+	}
+	rows.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(events) != 0 {
+		t.Errorf("unexpected events of the drained rows, expected: none, recieved: %+v", events)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+// Event is a structured log record passed to the EventLogger callback.
+type Event struct {
+	Ctx          context.Context     // context of the operation, nil if operation has no context
+	Topic        string              // name of the operation (for example stmt-exec-context)
+	Duration     time.Duration       // execution time
+	Query        string              // query, blank for the driver-open and the conn-retry
+	DSN          string              // data source name of the driver-open and the conn-retry sanitized by the SanitizeDSN, blank otherwise
+	Interpolated string              // query with interpolated parameters, blank if nothing was substituted
+	Args         []driver.NamedValue // parameters of the query or destination values of the rows-next named by the columns
+	Plan         []string            // plan of the conn-explain (see ExplainLogger), one line per row of the EXPLAIN
+	Err          error               // error of the operation
+	RowsAffected int64               // number of rows affected by the execution
+	LastInsertId int64               // last inserted id
+	Role         string              // database role of the driver (see Driver.Role), blank if not tagged
+	Correlation  uint64              // correlation id of one logical query or execution (see Driver.Correlate), zero if not tagged
+	Version      string              // version of the driver logged by the first driver-open (see DriverVersionLogger), blank otherwise
+	Attempt      int                 // number of the next attempt of the conn-retry (see RetryLogger), zero otherwise
+	Backoff      time.Duration       // time slept before the next attempt of the conn-retry
+	Host         string              // hostname of the process (see HostInfo), blank if not tagged
+	PID          int                 // id of the process (see HostInfo), zero if not tagged
+	StmtLeak     int                 // number of the unclosed prepared statements of the conn-close (see StmtLeakLogger), zero otherwise
+}
+
+// EventLogger is a Logger which invokes the callback with the structured
+// event instead of formatting text, for example to add span events
+// to the user's own tracer.
+type EventLogger struct {
+	Callback    func(Event)  // receives each event
+	Placeholder string       // if not blank then used as explicit placeholder instead of placeholder from parameters
+	NewTimer    func() Timer // returns a timer that measures a query execution time, wall clock timer if nil
+	Role        string       // if not blank then the events are tagged by the database role (see Driver.Role)
+	Correlation uint64       // if not zero then the events are tagged by the correlation id (see Driver.Correlate)
+	Version     string       // if not blank then the driver-open event holds the version of the driver (see DriverVersionLogger)
+	Host        string       // if not blank then the events are tagged by the hostname (see HostInfo)
+	PID         int          // if not zero then the events are tagged by the process id (see HostInfo)
+	stmtLeak    int          // if positive then the conn-close event holds the number of the unclosed prepared statements (see StmtLeakLogger)
+}
+
+func (l EventLogger) DriverOpen(name string, d time.Duration, err error) {
+	l.callback(Event{Topic: "driver-open", Duration: d, DSN: SanitizeDSN(name), Err: err, Version: l.Version})
+}
+
+func (l EventLogger) ConnPrepare(d time.Duration, query string, err error) {
+	l.callback(Event{Topic: "conn-prepare", Duration: d, Query: query, Err: err})
+}
+
+func (l EventLogger) ConnClose(d time.Duration, err error) {
+	l.callback(Event{Topic: "conn-close", Duration: d, Err: err, StmtLeak: l.stmtLeak})
+}
+
+func (l EventLogger) ConnBegin(d time.Duration, err error) {
+	l.callback(Event{Topic: "conn-begin", Duration: d, Err: err})
+}
+
+func (l EventLogger) ConnBeginTx(ctx context.Context, d time.Duration, _ driver.TxOptions, err error) {
+	l.callback(Event{Ctx: ctx, Topic: "conn-begin-tx", Duration: d, Err: err})
+}
+
+func (l EventLogger) ConnPrepareContext(ctx context.Context, d time.Duration, query string, err error) {
+	l.callback(Event{Ctx: ctx, Topic: "conn-prepare-context", Duration: d, Query: query, Err: err})
+}
+
+func (l EventLogger) ConnPrepareFallback(ctx context.Context, d time.Duration, query string, err error) {
+	l.callback(Event{Ctx: ctx, Topic: "conn-prepare-fallback", Duration: d, Query: query, Err: err})
+}
+
+func (l EventLogger) ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.event(nil, "conn-exec", d, query, dargs, nil, res, err)
+}
+
+func (l EventLogger) ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.event(ctx, "conn-exec-context", d, query, nil, nvdargs, res, err)
+}
+
+func (l EventLogger) ConnPing(ctx context.Context, d time.Duration, err error) {
+	l.callback(Event{Ctx: ctx, Topic: "conn-ping", Duration: d, Err: err})
+}
+
+func (l EventLogger) ConnRaw() {
+	l.callback(Event{Topic: "conn-raw"})
+}
+
+func (l EventLogger) ConnExplain(ctx context.Context, d time.Duration, query string, plan []string, err error) {
+	l.callback(Event{Ctx: ctx, Topic: "conn-explain", Duration: d, Query: query, Plan: plan, Err: err})
+}
+
+func (l EventLogger) ConnQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.event(nil, "conn-query", d, query, dargs, nil, nil, err)
+}
+
+func (l EventLogger) ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.event(ctx, "conn-query-context", d, query, nil, nvdargs, nil, err)
+}
+
+func (l EventLogger) StmtClose(d time.Duration, err error) {
+	l.callback(Event{Topic: "stmt-close", Duration: d, Err: err})
+}
+
+func (l EventLogger) StmtExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.event(nil, "stmt-exec", d, query, dargs, nil, res, err)
+}
+
+func (l EventLogger) StmtExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.event(ctx, "stmt-exec-context", d, query, nil, nvdargs, res, err)
+}
+
+func (l EventLogger) StmtQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.event(nil, "stmt-query", d, query, dargs, nil, nil, err)
+}
+
+func (l EventLogger) StmtQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.event(ctx, "stmt-query-context", d, query, nil, nvdargs, nil, err)
+}
+
+func (l EventLogger) RowsNext(d time.Duration, dest []driver.Value, err error) {
+	l.RowsNextRow(d, 0, nil, dest, err)
+}
+
+func (l EventLogger) RowsNextRow(d time.Duration, _ int, columns []string, dest []driver.Value, err error) {
+	args := namedValues(dest, nil)
+	if len(columns) == len(args) {
+		for i := range args {
+			args[i].Name = columns[i]
+		}
+	}
+	l.callback(Event{Topic: "rows-next", Duration: d, Args: args, Err: err})
+}
+
+func (l EventLogger) TxCommit(d time.Duration, err error) {
+	l.callback(Event{Topic: "tx-commit", Duration: d, Err: err})
+}
+
+func (l EventLogger) TxRollback(d time.Duration, err error) {
+	l.callback(Event{Topic: "tx-rollback", Duration: d, Err: err})
+}
+
+func (l EventLogger) TxSavepoint(ctx context.Context, d time.Duration, query, _, _ string, err error) {
+	l.callback(Event{Ctx: ctx, Topic: "tx-savepoint", Duration: d, Query: query, Err: err})
+}
+
+func (l EventLogger) Timer() Timer {
+	if l.NewTimer != nil {
+		return l.NewTimer()
+	}
+	return timer{start: time.Now()}
+}
+
+func (l EventLogger) event(ctx context.Context, topic string, d time.Duration, query string, dargs []driver.Value, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	e := Event{Ctx: ctx, Topic: topic, Duration: d, Query: query, Args: namedValues(dargs, nvdargs), Err: err}
+
+	if layout, ok := QueryLayout(ctx); ok {
+		e.Interpolated, _ = sqlteescan.InterpolateLayout(layout, query, l.Placeholder, dargs, nvdargs)
+	} else {
+		e.Interpolated, _ = sqlteescan.Interpolate(query, l.Placeholder, dargs, nvdargs)
+	}
+
+	if res != nil {
+		if id, err := res.LastInsertId(); err == nil {
+			e.LastInsertId = id
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			e.RowsAffected = n
+		}
+	}
+
+	l.callback(e)
+}
+
+func (l EventLogger) ConnRetry(name string, d time.Duration, attempt int, backoff time.Duration, err error) {
+	l.callback(Event{Topic: "conn-retry", Duration: d, DSN: SanitizeDSN(name), Err: err, Attempt: attempt, Backoff: backoff})
+}
+
+func (l EventLogger) ConnectorConnect(ctx context.Context, d time.Duration, err error) {
+	l.callback(Event{Ctx: ctx, Topic: "connector-connect", Duration: d, Err: err})
+}
+
+// WithRole returns a copy of the logger which tags the events by the role.
+func (l EventLogger) WithRole(role string) Logger {
+	l.Role = role
+	return l
+}
+
+// WithDriverVersion returns a copy of the logger which tags
+// the driver-open event by the version of the driver.
+func (l EventLogger) WithDriverVersion(version string) Logger {
+	l.Version = version
+	return l
+}
+
+// WithCorrelation returns a copy of the logger which tags the events by the correlation id.
+func (l EventLogger) WithCorrelation(id uint64) Logger {
+	l.Correlation = id
+	return l
+}
+
+// WithStmtLeak returns a copy of the logger which tags
+// the conn-close event by the number of the unclosed statements.
+func (l EventLogger) WithStmtLeak(unclosed int) Logger {
+	l.stmtLeak = unclosed
+	return l
+}
+
+// WithHostInfo returns a copy of the logger which tags the events by the hostname and the process id.
+func (l EventLogger) WithHostInfo(host string, pid int) Logger {
+	l.Host = host
+	l.PID = pid
+	return l
+}
+
+// callback invokes the callback with the event tagged by the role,
+// the correlation id, the hostname and the process id.
+func (l EventLogger) callback(e Event) {
+	e.Role = l.Role
+	e.Correlation = l.Correlation
+	e.Host = l.Host
+	e.PID = l.PID
+	l.Callback(e)
+}
+
+// namedValues returns the named values or the values converted
+// into the ordinal named values.
+func namedValues(dargs []driver.Value, nvdargs []driver.NamedValue) []driver.NamedValue {
+	if len(nvdargs) != 0 || len(dargs) == 0 {
+		return nvdargs
+	}
+
+	nvdargs = make([]driver.NamedValue, len(dargs))
+	for i, v := range dargs {
+		nvdargs[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return nvdargs
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+type fakeTimer struct{}
+
+func (fakeTimer) Stop() time.Duration { return 42 }
+
+func TestEventLogger(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		},
+		Placeholder: "?",
+		NewTimer:    func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_event_logger")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE|tbl|id=int64,name=string`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	mu.Lock()
+	events = nil
+	mu.Unlock()
+
+	_, err = db.Exec("INSERT|tbl|id=?,name=?", 42, "foo")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	var x int64
+	err = db.QueryRow(`SELECT|nonexistent_table|nonexistent_column|nonexistent_column=42`).Scan(&x)
+	if err == nil {
+		t.Fatal("expected query error")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var insert, failure *Event
+	for i, e := range events {
+		switch {
+		case e.Topic == "stmt-exec-context":
+			insert = &events[i]
+		case e.Topic == "conn-prepare-context" && e.Err != nil:
+			failure = &events[i]
+		}
+	}
+
+	if insert == nil {
+		t.Fatalf("stmt-exec-context event not found: %+v", events)
+	}
+
+	if insert.Ctx == nil {
+		t.Error("unexpected nil context of the insert event")
+	}
+	if insert.Duration != 42 {
+		t.Errorf("unexpected duration, expected: 42ns, recieved: %s", insert.Duration)
+	}
+	if insert.Query != "INSERT|tbl|id=?,name=?" {
+		t.Errorf("unexpected query: %q", insert.Query)
+	}
+	if insert.Interpolated != "INSERT|tbl|id=42,name='foo'" {
+		t.Errorf("unexpected interpolated query: %q", insert.Interpolated)
+	}
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "foo"}}
+	if !reflect.DeepEqual(insert.Args, args) {
+		t.Errorf("unexpected args, expected: %+v, recieved: %+v", args, insert.Args)
+	}
+	if insert.Err != nil {
+		t.Errorf("unexpected error: %#v", insert.Err)
+	}
+	if insert.RowsAffected != 1 {
+		t.Errorf("unexpected rows affected, expected: 1, recieved: %d", insert.RowsAffected)
+	}
+
+	if failure == nil {
+		t.Fatalf("failed conn-prepare-context event not found: %+v", events)
+	}
+
+	if failure.Query != "SELECT|nonexistent_table|nonexistent_column|nonexistent_column=42" {
+		t.Errorf("unexpected query: %q", failure.Query)
+	}
+	if failure.Err.Error() != `fakedb: SELECT on table "nonexistent_table" references non-existent column "nonexistent_column"` {
+		t.Errorf("unexpected error: %v", failure.Err)
+	}
+}
+
+func TestEventLoggerPlan(t *testing.T) {
+	var events []Event
+
+	l := EventLogger{Callback: func(e Event) { events = append(events, e) }}
+
+	plan := []string{"Seq Scan on foo", "  Filter: (id = 42)"}
+	l.ConnExplain(context.Background(), 42, "SELECT id FROM foo WHERE id = 42", plan, nil)
+
+	if len(events) != 1 {
+		t.Fatalf("unexpected number of the events, expected: 1, recieved: %d", len(events))
+	}
+
+	if !reflect.DeepEqual(events[0].Plan, plan) {
+		t.Errorf("unexpected plan, expected: %q, recieved: %q", plan, events[0].Plan)
+	}
+
+	if len(events[0].Args) != 0 {
+		t.Errorf("unexpected args of the plan, expected: none, recieved: %+v", events[0].Args)
+	}
+}
+
+func TestEventLoggerDSN(t *testing.T) {
+	var events []Event
+
+	l := EventLogger{
+		Callback: func(e Event) { events = append(events, e) },
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+
+	l.DriverOpen("host=db1 password=secret dbname=app", 42, nil)
+	l.ConnRetry("host=db1 password=secret dbname=app", 42, 2, time.Millisecond, driver.ErrBadConn)
+
+	if len(events) != 2 {
+		t.Fatalf("unexpected number of the events, expected: %d, recieved: %d", 2, len(events))
+	}
+
+	for _, e := range events {
+		if e.DSN != "host=db1 dbname=app" {
+			t.Errorf("unexpected dsn of the %s, expected: %q, recieved: %q", e.Topic, "host=db1 dbname=app", e.DSN)
+		}
+		if e.Query != "" {
+			t.Errorf("unexpected query of the %s, expected: blank, recieved: %q", e.Topic, e.Query)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteegob
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Record is a decoded log record of the Gob logger.
+type Record struct {
+	Duration    time.Duration
+	Description string
+	Time        time.Time // start time of the operation, zero unless Gob.Timestamp is true
+}
+
+// Decoder decodes the log records written by the Gob logger
+// from the stream one record at a time.
+type Decoder struct {
+	r        *bufio.Reader
+	prefixed bool // records are prefixed by the lengths (see LengthPrefixedWriter)
+}
+
+// NewDecoder returns a new decoder that reads from the r.
+func NewDecoder(r io.Reader) *Decoder {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	return &Decoder{r: br}
+}
+
+// NewLengthPrefixedDecoder returns a new decoder that reads
+// from the r the records written by the LengthPrefixedWriter.
+// Next of the decoder returns io.ErrUnexpectedEOF if the stream
+// ends in the middle of the record and ErrFrameTooLarge
+// if the length prefix is larger than 16 MiB.
+//
+// The decoder resynchronizes after the corrupt record, so the next call
+// of the Next continues with the following record: the record which
+// fails to decode is skipped by its length and the length prefix larger
+// than 16 MiB is skipped by one byte per call of the Next until
+// the next plausible length prefix.
+func NewLengthPrefixedDecoder(r io.Reader) *Decoder {
+	d := NewDecoder(r)
+	d.prefixed = true
+	return d
+}
+
+// Next decodes the next record from the stream.
+// At the end of the stream Next returns io.EOF.
+func (d *Decoder) Next() (Record, error) {
+	// Each record is encoded by its own gob encoder (with its own
+	// type definitions) so each record is decoded by the new gob decoder.
+	// The gob decoder does not read beyond the record
+	// from the reader which implements io.ByteReader.
+	var (
+		b   bin
+		dec *gob.Decoder
+	)
+
+	if d.prefixed {
+		p, err := d.frame()
+		if err != nil {
+			return Record{}, err
+		}
+		dec = gob.NewDecoder(bytes.NewReader(p))
+
+	} else {
+		dec = gob.NewDecoder(d.r)
+	}
+
+	err := dec.Decode(&b)
+	if err == io.EOF && d.prefixed {
+		// the empty frame is not the end of the stream
+		return Record{}, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return Record{}, err
+	}
+
+	return Record{Duration: b.Duration, Description: string(b.Description), Time: b.Time}, nil
+}
+
+// frame reads exactly one length prefixed record
+// or skips one byte of the length prefix larger than maxFrame.
+func (d *Decoder) frame() ([]byte, error) {
+	h, err := d.r.Peek(lengthPrefixSize)
+	if err == io.EOF && len(h) != 0 {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(h)
+	if n > maxFrame {
+		_, _ = d.r.Discard(1)
+		return nil, fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, n)
+	}
+
+	_, _ = d.r.Discard(lengthPrefixSize)
+
+	p := make([]byte, n)
+
+	_, err = io.ReadFull(d.r, p)
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,293 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteegob_test
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/gob"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee"
+	"github.com/danil/sqltee/examples/sqlteegob"
+)
+
+func TestDecoder(t *testing.T) {
+	var buf bytes.Buffer
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	g.DriverOpen("", 1, nil)
+	g.ConnExec(2, "SELECT * FROM foo WHERE id = ?", []driver.Value{int64(42)}, nil, nil)
+	g.ConnClose(3, errors.New("bad connection"))
+
+	expected := []sqlteegob.Record{
+		{Duration: 1, Description: "fakedb driver-open 1ns"},
+		{Duration: 2, Description: "fakedb conn-exec 2ns query interpolation: SELECT * FROM foo WHERE id = 42"},
+		{Duration: 3, Description: "fakedb conn-close 3ns error: bad connection"},
+	}
+
+	dec := sqlteegob.NewDecoder(&buf)
+
+	var records []sqlteegob.Record
+	for {
+		rec, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("decode error: %#v", err)
+		}
+		records = append(records, rec)
+	}
+
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("unexpected records, expected: %+v, recieved: %+v", expected, records)
+	}
+}
+
+func TestDecoderUnexpectedEOF(t *testing.T) {
+	var buf bytes.Buffer
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr}
+
+	g.ConnClose(1, nil)
+
+	dec := sqlteegob.NewDecoder(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+
+	_, err := dec.Next()
+	if err == nil || err == io.EOF {
+		t.Errorf("unexpected error of the truncated record: %#v", err)
+	}
+}
+
+func TestLengthPrefixedDecoder(t *testing.T) {
+	var buf bytes.Buffer
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: sqlteegob.LengthPrefixedWriter(&buf), Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	g.DriverOpen("", 1, nil)
+	g.ConnExec(2, "SELECT * FROM foo WHERE id = ?", []driver.Value{int64(42)}, nil, nil)
+	g.ConnClose(3, nil)
+
+	full := buf.Len()
+	g.ConnClose(4, nil)
+
+	expected := []sqlteegob.Record{
+		{Duration: 1, Description: "fakedb driver-open 1ns"},
+		{Duration: 2, Description: "fakedb conn-exec 2ns query interpolation: SELECT * FROM foo WHERE id = 42"},
+		{Duration: 3, Description: "fakedb conn-close 3ns"},
+		{Duration: 4, Description: "fakedb conn-close 4ns"},
+	}
+
+	var tests = []struct {
+		name     string
+		line     string
+		size     int
+		expected []sqlteegob.Record
+		err      error
+	}{
+		{
+			name:     "round trip",
+			line:     line(),
+			size:     buf.Len(),
+			expected: expected,
+			err:      io.EOF,
+		},
+		{
+			name:     "truncated record",
+			line:     line(),
+			size:     buf.Len() - 1,
+			expected: expected[:3],
+			err:      io.ErrUnexpectedEOF,
+		},
+		{
+			name:     "truncated length prefix",
+			line:     line(),
+			size:     full + 2,
+			expected: expected[:3],
+			err:      io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			dec := sqlteegob.NewLengthPrefixedDecoder(bytes.NewReader(buf.Bytes()[:tt.size]))
+
+			var (
+				records []sqlteegob.Record
+				err     error
+			)
+			for {
+				var rec sqlteegob.Record
+				rec, err = dec.Next()
+				if err != nil {
+					break
+				}
+				records = append(records, rec)
+			}
+
+			if err != tt.err {
+				t.Errorf("unexpected error, expected: %#v, recieved: %#v %s", tt.err, err, tt.line)
+			}
+
+			if !reflect.DeepEqual(records, tt.expected) {
+				t.Errorf("unexpected records, expected: %+v, recieved: %+v %s", tt.expected, records, tt.line)
+			}
+		})
+	}
+}
+
+func TestLengthPrefixedDecoderFrameTooLarge(t *testing.T) {
+	dec := sqlteegob.NewLengthPrefixedDecoder(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff}))
+
+	_, err := dec.Next()
+	if !errors.Is(err, sqlteegob.ErrFrameTooLarge) {
+		t.Errorf("unexpected error, expected: %#v, recieved: %#v", sqlteegob.ErrFrameTooLarge, err)
+	}
+}
+
+func TestLengthPrefixedDecoderResync(t *testing.T) {
+	var first, second bytes.Buffer
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+
+	sqlteegob.Gob{Writer: sqlteegob.LengthPrefixedWriter(&first), Topic: "fakedb", NewTimer: tmr}.ConnClose(1, nil)
+	sqlteegob.Gob{Writer: sqlteegob.LengthPrefixedWriter(&second), Topic: "fakedb", NewTimer: tmr}.ConnClose(2, nil)
+
+	expected := []sqlteegob.Record{
+		{Duration: 1, Description: "fakedb conn-close 1ns"},
+		{Duration: 2, Description: "fakedb conn-close 2ns"},
+	}
+
+	var tests = []struct {
+		name    string
+		line    string
+		corrupt []byte
+		errs    int
+	}{
+		{
+			name:    "corrupt length prefix",
+			line:    line(),
+			corrupt: []byte{0xff, 0xff, 0xff, 0xff},
+			errs:    4,
+		},
+		{
+			name:    "corrupt record",
+			line:    line(),
+			corrupt: []byte{0, 0, 0, 3, 'f', 'o', 'o'},
+			errs:    1,
+		},
+		{
+			name:    "empty record",
+			line:    line(),
+			corrupt: []byte{0, 0, 0, 0},
+			errs:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			stream := append(append(append([]byte(nil), first.Bytes()...), tt.corrupt...), second.Bytes()...)
+			dec := sqlteegob.NewLengthPrefixedDecoder(bytes.NewReader(stream))
+
+			var (
+				records []sqlteegob.Record
+				errs    int
+			)
+			for i := 0; i < 100; i++ {
+				rec, err := dec.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					errs++
+					continue
+				}
+				records = append(records, rec)
+			}
+
+			if errs != tt.errs {
+				t.Errorf("unexpected number of the errors, expected: %d, recieved: %d %s", tt.errs, errs, tt.line)
+			}
+
+			if !reflect.DeepEqual(records, expected) {
+				t.Errorf("unexpected records, expected: %+v, recieved: %+v %s", expected, records, tt.line)
+			}
+		})
+	}
+}
+
+func TestLengthPrefixedWriterFrameTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+
+	_, err := sqlteegob.LengthPrefixedWriter(&buf).Write(make([]byte, 16<<20+1))
+	if err != sqlteegob.ErrFrameTooLarge {
+		t.Errorf("unexpected error, expected: %#v, recieved: %#v", sqlteegob.ErrFrameTooLarge, err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("unexpected write, expected: nothing, recieved: %d bytes", buf.Len())
+	}
+}
+
+func TestDecoderTimestamp(t *testing.T) {
+	var buf bytes.Buffer
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, Timestamp: true}
+
+	before := time.Now()
+	g.ConnClose(time.Millisecond, nil)
+	after := time.Now()
+
+	g.Timestamp = false
+	g.ConnClose(time.Millisecond, nil)
+
+	p := append([]byte(nil), buf.Bytes()...)
+
+	dec := sqlteegob.NewDecoder(&buf)
+
+	rec, err := dec.Next()
+	if err != nil {
+		t.Fatalf("decode error: %#v", err)
+	}
+
+	if rec.Time.Before(before.Add(-time.Millisecond)) || rec.Time.After(after) {
+		t.Errorf("unexpected time, expected: between %s and %s, recieved: %s", before.Add(-time.Millisecond), after, rec.Time)
+	}
+
+	rec, err = dec.Next()
+	if err != nil {
+		t.Fatalf("decode error: %#v", err)
+	}
+
+	if !rec.Time.IsZero() {
+		t.Errorf("unexpected time, expected: zero time, recieved: %s", rec.Time)
+	}
+
+	// The records with time are decodable into the records without time.
+	var old struct {
+		Duration    time.Duration
+		Description []byte
+	}
+
+	err = gob.NewDecoder(bytes.NewReader(p)).Decode(&old)
+	if err != nil {
+		t.Fatalf("decode error: %#v", err)
+	}
+
+	if old.Duration != time.Millisecond || string(old.Description) != "fakedb conn-close 1ms" {
+		t.Errorf("unexpected record, expected: 1ms fakedb conn-close 1ms, recieved: %s %s", old.Duration, old.Description)
+	}
+}
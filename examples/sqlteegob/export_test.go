@@ -0,0 +1,21 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteegob
+
+import (
+	"bytes"
+)
+
+// PooledCapacities gets n buffers and n binaries from the pools
+// and returns the capacities of the buffers and of the binary descriptions.
+func PooledCapacities(n int) (bufs, bins []int) {
+	for i := 0; i < n; i++ {
+		bufs = append(bufs, bufPool.Get().(*bytes.Buffer).Cap())
+		bins = append(bins, cap(binPool.Get().(*bin).Description))
+	}
+	return bufs, bins
+}
+
+const MaxPooledSize = maxPooledSize
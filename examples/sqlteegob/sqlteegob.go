@@ -8,192 +8,746 @@ import (
 	"bytes"
 	"context"
 	"database/sql/driver"
+	"encoding/binary"
 	"encoding/gob"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/danil/sqltee"
 	"github.com/danil/sqltee/sqlteescan"
 )
 
 type Gob struct {
-	Writer      io.Writer           // destination for output
-	Topic       string              // prefix for all logs
-	Placeholder string              // if not blank then used as explicit placeholder instead of placeholder from parameters
-	NewTimer    func() sqltee.Timer // retrurs a timer that measures a query execution time
+	Writer               io.Writer                   // destination for output, should be safe for concurrent use (see SyncWriter)
+	Topic                string                      // prefix for all logs
+	Placeholder          string                      // if not blank then used as explicit placeholder instead of placeholder from parameters
+	NewTimer             func() sqltee.Timer         // retrurs a timer that measures a query execution time
+	DSN                  bool                        // if true then driver open logs data source name sanitized by sqltee.SanitizeDSN
+	DurationRound        time.Duration               // if positive then durations are rounded to the multiple of DurationRound
+	MaxValueSize         int                         // if positive then []byte and string parameters longer than MaxValueSize bytes are logged as size markers
+	Dialect              sqlteescan.Dialect          // SQL dialect of the interpolated parameters
+	NoInterpolate        bool                        // if true then the parameterized query and the parameters are logged without interpolation
+	JSONArgs             bool                        // if true then the parameters are logged as JSON array (byte slices are base64 encoded)
+	MaxArgs              int                         // if positive then only first MaxArgs parameters are interpolated and the rest are marked as …(+N more args)
+	IncludeGoroutineID   bool                        // if true then the ID of the goroutine is logged (costs about a microsecond per log because of runtime.Stack)
+	Normalize            bool                        // if true then the query normalized by sqlteescan.Normalize is logged for grouping by the query shape
+	Timestamp            bool                        // if true then the record contains the wall clock start time of the operation (see Record.Time)
+	MaxLoggedRows        int                         // if positive then the rows-next destination values are logged only for first MaxLoggedRows rows and the number of rows is logged at the end
+	Clock                sqltee.Clock                // if not nil then used instead of the wall clock for the start time of the operation
+	ParamMap             bool                        // if true then the parameters are logged as name=value list (for example params: {id=42, name='foo'}) instead of args
+	OnWriteError         func(error)                 // if not nil then called when the record is not written to the Writer (for example to alert or to switch the sink)
+	CompressInLists      bool                        // if true then the IN lists of many placeholders are logged compactly (for example IN (1, 2, 3, … 500 values …))
+	IncludeSequence      bool                        // if true then the connection id and the sequence number of the operation are logged (see sqltee.Sequence)
+	ResultFetchThreshold time.Duration               // if positive then the LastInsertId and RowsAffected calls of the result taking at least ResultFetchThreshold are logged as result-fetch duration
+	Role                 string                      // if not blank then the database role is logged (see sqltee.Driver.Role)
+	Host                 string                      // if not blank then the hostname is logged (see sqltee.HostInfo)
+	PID                  int                         // if not zero then the process id is logged (see sqltee.HostInfo)
+	TimeFormat           sqlteescan.TimeFormat       // format of the time.Time parameters (for example sqlteescan.TimeEpochMillis), RFC 3339 if blank
+	DriverVersion        string                      // if not blank then the version of the driver is logged by the driver-open (see sqltee.DriverVersionLogger)
+	Template             string                      // if not blank then the order of the description fields (for example {duration} {query} {error}), DefaultTemplate if blank
+	PlaceholderStyle     sqlteescan.PlaceholderStyle // style of the parameter identifiers of the interpolation if the Placeholder is blank (for example sqlteescan.PlaceholderAtP)
+	ErrorsOnly           bool                        // if true then only the operations failed by the genuine errors are logged (not driver.ErrSkip nor sqltee.ErrPingUnsupported nor io.EOF of the rows), the rest are skipped without the interpolation
+	RowsNextSampleN      int                         // if greater than one then only the first, each RowsNextSampleN-th and the last (eof) rows-next are logged and the number of rows is logged at the end
+	FormatError          func(error) string          // if not nil then renders the errors of the operations (for example with the chain of the wrapped errors), the errors are rendered by %v if nil
+	StackOnError         bool                        // if true then the genuine errors (not driver.ErrSkip nor io.EOF of the rows) are followed by the stack of the operation without the frames of the sqltee and the database/sql (costs a few microseconds per failed operation)
+	stmtLeak             int                         // if positive then the conn-close logs the number of the unclosed prepared statements (see sqltee.StmtLeakLogger)
+	static               string                      // static fields formatted once by the WithStaticFields
 }
 
-func (g Gob) DriverOpen(d time.Duration, derr error) {
-	g.error("driver-open", d, derr)
+func (g Gob) DriverOpen(name string, d time.Duration, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, "driver-open", d)
+	if err != nil {
+		return
+	}
+
+	if derr != nil { // && derr != driver.ErrSkip {
+		err = f.write(buf, fieldError, g.errorString(derr))
+		if err != nil {
+			return
+		}
+	}
+
+	if g.DSN {
+		if dsn := sqltee.SanitizeDSN(name); dsn != "" {
+			_, err = buf.Write([]byte(fmt.Sprintf(" dsn: %s", dsn)))
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	if g.DriverVersion != "" {
+		_, err = buf.Write([]byte(fmt.Sprintf(" driver-version: %s", g.DriverVersion)))
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (g Gob) ConnRetry(name string, d time.Duration, attempt int, backoff time.Duration, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, "conn-retry", d)
+	if err != nil {
+		return
+	}
+
+	_, err = buf.Write([]byte(fmt.Sprintf(" retry: attempt=%d backoff=%s", attempt, backoff)))
+	if err != nil {
+		return
+	}
+
+	if derr != nil {
+		err = f.write(buf, fieldError, g.errorString(derr))
+		if err != nil {
+			return
+		}
+	}
+
+	if g.DSN {
+		if dsn := sqltee.SanitizeDSN(name); dsn != "" {
+			_, err = buf.Write([]byte(fmt.Sprintf(" dsn: %s", dsn)))
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (g Gob) ConnectorConnect(ctx context.Context, d time.Duration, derr error) {
+	g.error(ctx, "connector-connect", d, derr)
 }
 
 func (g Gob) ConnPrepare(d time.Duration, query string, derr error) {
-	g.query("conn-prepare", d, query, derr)
+	g.query(nil, "conn-prepare", d, query, derr)
 }
 
 func (g Gob) ConnClose(d time.Duration, derr error) {
-	g.error("conn-close", d, derr)
+	if g.stmtLeak <= 0 {
+		g.error(nil, "conn-close", d, derr)
+		return
+	}
+
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, "conn-close", d)
+	if err != nil {
+		return
+	}
+
+	if derr != nil {
+		err = f.write(buf, fieldError, g.errorString(derr))
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = buf.Write([]byte(fmt.Sprintf(" stmt-leak: %d unclosed", g.stmtLeak)))
+	if err != nil {
+		return
+	}
 }
 
 func (g Gob) ConnBegin(d time.Duration, derr error) {
-	g.error("conn-begin", d, derr)
+	g.error(nil, "conn-begin", d, derr)
 }
 
 var bufPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
 
-func (g Gob) ConnBeginTx(_ context.Context, d time.Duration, opts driver.TxOptions, derr error) {
+// maxPooledSize is a maximum capacity of the buffers returned into the pools,
+// so the buffer grown by the one-off huge query is collected
+// instead of being retained by the pool forever.
+const maxPooledSize = 64 << 10
+
+// putBuf returns the buffer into the pool if it is not oversized.
+func putBuf(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledSize {
+		bufPool.Put(buf)
+	}
+}
+
+func (g Gob) ConnBeginTx(ctx context.Context, d time.Duration, opts driver.TxOptions, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
 	buf := bufPool.Get().(*bytes.Buffer)
 	buf.Reset()
-	defer bufPool.Put(buf)
-	defer func() { io.Copy(g.Writer, newReader(d, buf.Bytes())) }()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, "conn-begin-tx", d)
+	if err != nil {
+		return
+	}
 
-	_, err := buf.Write([]byte(fmt.Sprintf("%s %s %s", g.Topic, "conn-begin-tx", d)))
+	err = g.sequence(ctx, buf)
 	if err != nil {
 		return
 	}
 
 	if derr != nil { // && derr != driver.ErrSkip {
-		_, err = buf.Write([]byte(fmt.Sprintf(" error: %v", derr)))
+		err = f.write(buf, fieldError, g.errorString(derr))
 		if err != nil {
 			return
 		}
 	}
 
 	if (opts != driver.TxOptions{}) {
-		_, err = buf.Write([]byte(fmt.Sprintf(" opts: %+v", opts)))
+		_, err = buf.Write([]byte(fmt.Sprintf(" opts: {Isolation:%s ReadOnly:%t}", sqltee.IsolationName(opts.Isolation), opts.ReadOnly)))
 		if err != nil {
 			return
 		}
 	}
 }
 
-func (g Gob) ConnPrepareContext(_ context.Context, d time.Duration, query string, derr error) {
-	g.query("conn-prepare-context", d, query, derr)
+func (g Gob) ConnPrepareContext(ctx context.Context, d time.Duration, query string, derr error) {
+	g.query(ctx, "conn-prepare-context", d, query, derr)
+}
+
+func (g Gob) ConnPrepareFallback(ctx context.Context, d time.Duration, query string, derr error) {
+	g.query(ctx, "conn-prepare-fallback", d, query, derr)
 }
 
 func (g Gob) ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, derr error) {
-	g.interpolation("conn-exec", d, query, dargs, nil, res, derr)
+	g.interpolation(nil, "conn-exec", d, query, dargs, nil, res, derr)
 }
 
-func (g Gob) ConnExecContext(_ context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, derr error) {
-	g.interpolation("conn-exec-context", d, query, nil, nvdargs, res, derr)
+func (g Gob) ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, derr error) {
+	g.interpolation(ctx, "conn-exec-context", d, query, nil, nvdargs, res, derr)
 }
 
-func (g Gob) ConnPing(d time.Duration, derr error) {
-	// g.error("conn-ping", d, derr)
+func (g Gob) ConnPing(ctx context.Context, d time.Duration, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	if !errors.Is(derr, sqltee.ErrPingUnsupported) {
+		g.error(ctx, "conn-ping", d, derr)
+		return
+	}
+
+	d = g.round(d)
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, "conn-ping", d)
+	if err != nil {
+		return
+	}
+
+	err = g.sequence(ctx, buf)
+	if err != nil {
+		return
+	}
+
+	buf.Write([]byte(" ping: unsupported"))
+}
+
+func (g Gob) ConnRaw() {
+	g.error(nil, "conn-raw", 0, nil)
+}
+
+func (g Gob) ConnExplain(_ context.Context, d time.Duration, query string, plan []string, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, "conn-explain", d)
+	if err != nil {
+		return
+	}
+
+	if derr != nil { // && derr != driver.ErrSkip {
+		err = f.write(buf, fieldError, g.errorString(derr))
+		if err != nil {
+			return
+		}
+	}
+
+	if query != "" {
+		err = f.write(buf, fieldQuery, fmt.Sprintf(" query: %s", query))
+		if err != nil {
+			return
+		}
+	}
+
+	if len(plan) != 0 {
+		_, err = buf.Write([]byte(fmt.Sprintf(" plan: %s", strings.Join(plan, "\n"))))
+		if err != nil {
+			return
+		}
+	}
 }
 
 func (g Gob) ConnQuery(d time.Duration, query string, dargs []driver.Value, derr error) {
-	g.interpolation("conn-query", d, query, dargs, nil, nil, derr)
+	g.interpolation(nil, "conn-query", d, query, dargs, nil, nil, derr)
 }
 
-func (g Gob) ConnQueryContext(_ context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, derr error) {
-	g.interpolation("conn-query-context", d, query, nil, nvdargs, nil, derr)
+func (g Gob) ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, derr error) {
+	g.interpolation(ctx, "conn-query-context", d, query, nil, nvdargs, nil, derr)
 }
 
 func (g Gob) StmtClose(d time.Duration, derr error) {
-	g.error("stmt-close", d, derr)
+	g.StmtCloseTotal(d, 0, derr)
+}
+
+// StmtCloseTotal logs the stmt-close with the total time of the statement
+// from the prepare start to the close end (see sqltee.StmtTotalLogger).
+func (g Gob) StmtCloseTotal(d, total time.Duration, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, "stmt-close", d)
+	if err != nil {
+		return
+	}
+
+	if derr != nil { // && derr != driver.ErrSkip {
+		err = f.write(buf, fieldError, g.errorString(derr))
+		if err != nil {
+			return
+		}
+	}
+
+	if total != 0 {
+		_, err = buf.Write([]byte(fmt.Sprintf(" stmt-total: %s", g.round(total))))
+		if err != nil {
+			return
+		}
+	}
 }
 
 func (g Gob) StmtExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, derr error) {
-	g.interpolation("stmt-exec", d, query, dargs, nil, res, derr)
+	g.interpolation(nil, "stmt-exec", d, query, dargs, nil, res, derr)
 }
 
-func (g Gob) StmtExecContext(_ context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, derr error) {
-	g.interpolation("stmt-exec-context", d, query, nil, nvdargs, res, derr)
+func (g Gob) StmtExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, derr error) {
+	g.interpolation(ctx, "stmt-exec-context", d, query, nil, nvdargs, res, derr)
 }
 
 func (g Gob) StmtQuery(d time.Duration, query string, dargs []driver.Value, derr error) {
-	g.interpolation("stmt-query", d, query, dargs, nil, nil, derr)
+	g.interpolation(nil, "stmt-query", d, query, dargs, nil, nil, derr)
 }
 
-func (g Gob) StmtQueryContext(_ context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, derr error) {
-	g.interpolation("stmt-query-context", d, query, nil, nvdargs, nil, derr)
+func (g Gob) StmtQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, derr error) {
+	g.interpolation(ctx, "stmt-query-context", d, query, nil, nvdargs, nil, derr)
 }
 
 func (g Gob) RowsNext(d time.Duration, dest []driver.Value, derr error) {
+	g.RowsNextRow(d, 0, nil, dest, derr)
+}
+
+// RowsNextRow logs the rows-next with the number of the row
+// and the columns (see sqltee.RowLogger), the zero row is unknown.
+func (g Gob) RowsNextRow(d time.Duration, row int, columns []string, dest []driver.Value, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	// the first row, the end of the rows and the errors are never sampled out
+	if g.RowsNextSampleN > 1 && derr == nil && row != 1 && row%g.RowsNextSampleN != 0 {
+		return
+	}
+
+	d = g.round(d)
 	buf := bufPool.Get().(*bytes.Buffer)
 	buf.Reset()
-	defer bufPool.Put(buf)
-	defer func() { io.Copy(g.Writer, newReader(d, buf.Bytes())) }()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
 
-	_, err := buf.Write([]byte(fmt.Sprintf("%s %s %s", g.Topic, "rows-next", d)))
+	err := f.header(buf, g.Topic, "rows-next", d)
 	if err != nil {
 		return
 	}
 
-	if derr != nil { // && derr != driver.ErrSkip {
-		_, err = buf.Write([]byte(fmt.Sprintf(" error: %v", derr)))
+	if derr == io.EOF { // end of the rows is not a failure
+		_, err = buf.Write([]byte(" eof"))
 		if err != nil {
 			return
 		}
-	}
 
-	if len(dest) != 0 {
-		_, err = buf.Write([]byte(fmt.Sprintf(" dest: %+v", dest)))
+		if row > 0 && (g.MaxLoggedRows > 0 || g.RowsNextSampleN > 1) {
+			_, err = buf.Write([]byte(fmt.Sprintf(" rows: %d", row-1)))
+			if err != nil {
+				return
+			}
+		}
+	} else if derr != nil { // && derr != driver.ErrSkip {
+		err = f.write(buf, fieldError, g.errorString(derr))
 		if err != nil {
 			return
 		}
 	}
+
+	if len(dest) != 0 && (g.MaxLoggedRows <= 0 || row <= g.MaxLoggedRows) {
+		if len(columns) == len(dest) {
+			_, err = buf.Write([]byte(" dest: {"))
+			if err != nil {
+				return
+			}
+			for i, v := range g.dest(dest) {
+				if i != 0 {
+					_, err = buf.Write([]byte(", "))
+					if err != nil {
+						return
+					}
+				}
+				_, err = buf.Write([]byte(fmt.Sprintf("%s:%+v", columns[i], v)))
+				if err != nil {
+					return
+				}
+			}
+			_, err = buf.Write([]byte("}"))
+			if err != nil {
+				return
+			}
+
+		} else {
+			// the driver returned more or less values than the declared columns
+			// (or the columns are unknown) so the values are not labeled
+			_, err = buf.Write([]byte(fmt.Sprintf(" dest: %+v", g.dest(dest))))
+			if err != nil {
+				return
+			}
+
+			if len(columns) != 0 {
+				_, err = buf.Write([]byte(fmt.Sprintf(" column-mismatch: columns=%d dest=%d", len(columns), len(dest))))
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
 }
 
 func (g Gob) TxCommit(d time.Duration, derr error) {
-	g.error("tx-commit", d, derr)
+	g.error(nil, "tx-commit", d, derr)
 }
 
 func (g Gob) TxRollback(d time.Duration, derr error) {
-	g.error("tx-rollback", d, derr)
+	g.error(nil, "tx-rollback", d, derr)
+}
+
+func (g Gob) TxSavepoint(ctx context.Context, d time.Duration, _, command, name string, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, "tx-savepoint", d)
+	if err != nil {
+		return
+	}
+
+	err = g.sequence(ctx, buf)
+	if err != nil {
+		return
+	}
+
+	if derr != nil { // && derr != driver.ErrSkip {
+		err = f.write(buf, fieldError, g.errorString(derr))
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = buf.Write([]byte(fmt.Sprintf(" %s: %s", strings.ToLower(command), name)))
+	if err != nil {
+		return
+	}
+}
+
+// WithRole returns a copy of the logger which logs the database role.
+func (g Gob) WithRole(role string) sqltee.Logger {
+	g.Role = role
+	return g
+}
+
+// WithDriverVersion returns a copy of the logger which logs the version of the driver.
+func (g Gob) WithDriverVersion(version string) sqltee.Logger {
+	g.DriverVersion = version
+	return g
+}
+
+// WithStmtLeak returns a copy of the logger which logs
+// the number of the unclosed statements by the conn-close.
+func (g Gob) WithStmtLeak(unclosed int) sqltee.Logger {
+	g.stmtLeak = unclosed
+	return g
+}
+
+// WithHostInfo returns a copy of the logger which logs the hostname and the process id.
+func (g Gob) WithHostInfo(host string, pid int) sqltee.Logger {
+	g.Host = host
+	g.PID = pid
+	return g
 }
 
 func (g Gob) Timer() sqltee.Timer {
 	return g.NewTimer()
 }
 
+// write appends the role, the hostname, the process id, the static fields and the goroutine ID
+// (if IncludeGoroutineID is true) to the description,
+// reorders the fields of the description by the Template
+// and writes the record with the start time of the operation if Timestamp is true
+// to the Writer.
+func (g Gob) write(d time.Duration, buf *bytes.Buffer, f *fields) {
+	if g.Role != "" {
+		buf.Write([]byte(" role: "))
+		buf.Write([]byte(g.Role))
+	}
+
+	if g.Host != "" {
+		buf.Write([]byte(" host: "))
+		buf.Write([]byte(g.Host))
+	}
+
+	if g.PID != 0 {
+		buf.Write([]byte(" pid: "))
+		buf.Write(strconv.AppendInt(nil, int64(g.PID), 10))
+	}
+
+	buf.WriteString(g.static)
+
+	if g.IncludeGoroutineID {
+		if id, ok := goroutineID(); ok {
+			buf.Write([]byte(" gid: "))
+			buf.Write(strconv.AppendUint(nil, id, 10))
+		}
+	}
+
+	var start time.Time
+	if g.Timestamp {
+		if g.Clock != nil {
+			start = g.Clock.Now().Add(-d)
+		} else {
+			start = time.Now().Add(-d)
+		}
+	}
+
+	desc := buf.Bytes()
+	if t := g.template(); t != nil {
+		tbuf := bufPool.Get().(*bytes.Buffer)
+		tbuf.Reset()
+		defer putBuf(tbuf)
+
+		t.render(tbuf, desc, f)
+		desc = tbuf.Bytes()
+	}
+
+	_, err := io.Copy(g.Writer, newReader(d, start, desc))
+	if err != nil && g.OnWriteError != nil {
+		g.OnWriteError(err)
+	}
+}
+
+// goroutineID returns the ID of the current goroutine parsed from
+// the header of the goroutine stack trace (for example goroutine 1234 [running]:).
+// It is intended only for the correlation of the logs because the Go runtime
+// does not expose the ID deliberately.
+func goroutineID() (uint64, bool) {
+	var p [64]byte
+
+	b := p[:runtime.Stack(p[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i != -1 {
+		b = b[:i]
+	}
+
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// skip reports whether the operation is not logged because
+// the ErrorsOnly is true and the operation is not failed by the genuine error
+// (the end of the rows is not a failure).
+func (g Gob) skip(derr error) bool {
+	return g.ErrorsOnly && (derr == nil || derr == io.EOF || errors.Is(derr, driver.ErrSkip) || errors.Is(derr, sqltee.ErrPingUnsupported))
+}
+
+// round returns the duration rounded to the multiple of DurationRound
+// or the duration unchanged if DurationRound is not positive.
+func (g Gob) round(d time.Duration) time.Duration {
+	if g.DurationRound > 0 {
+		return d.Round(g.DurationRound)
+	}
+	return d
+}
+
+// sequence writes the connection id and the sequence number
+// of the operation if the IncludeSequence is true and the operation is numbered.
+func (g Gob) sequence(ctx context.Context, buf *bytes.Buffer) error {
+	if !g.IncludeSequence {
+		return nil
+	}
+
+	conn, seq, ok := sqltee.Sequence(ctx)
+	if !ok {
+		return nil
+	}
+
+	_, err := buf.Write([]byte(fmt.Sprintf(" conn: %d seq: %d", conn, seq)))
+	return err
+}
+
 // error is a log function of the sql driver errors.
-func (g Gob) error(topic string, d time.Duration, derr error) {
+func (g Gob) error(ctx context.Context, topic string, d time.Duration, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
 	buf := bufPool.Get().(*bytes.Buffer)
 	buf.Reset()
-	defer bufPool.Put(buf)
-	defer func() { io.Copy(g.Writer, newReader(d, buf.Bytes())) }()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
 
-	_, err := buf.Write([]byte(fmt.Sprintf("%s %s %s", g.Topic, topic, d)))
+	err := f.header(buf, g.Topic, topic, d)
+	if err != nil {
+		return
+	}
+
+	err = g.sequence(ctx, buf)
 	if err != nil {
 		return
 	}
 
 	if derr != nil { // && derr != driver.ErrSkip {
-		_, err = buf.Write([]byte(fmt.Sprintf(" error: %v", derr)))
+		err = f.write(buf, fieldError, g.errorString(derr))
 		if err != nil {
 			return
 		}
 	}
 }
 
+// errorString returns the error field of the description,
+// the driver.ErrSkip is logged as the fast-path which the underlying
+// connection does not implement (see sqltee.ErrSkipUnsupported)
+// or which the driver skipped for the query, the rest of the errors
+// are rendered by the FormatError if not nil and followed
+// by the stack of the operation if the StackOnError is true.
+func (g Gob) errorString(err error) string {
+	if errors.Is(err, sqltee.ErrSkipUnsupported) {
+		return " fast-path: unsupported"
+	}
+	if errors.Is(err, driver.ErrSkip) {
+		return " fast-path: driver-skip"
+	}
+	var s string
+	if g.FormatError != nil {
+		s = " error: " + g.FormatError(err)
+	} else {
+		s = fmt.Sprintf(" error: %v", err)
+	}
+	if g.StackOnError {
+		s += " stack: " + stack()
+	}
+	return s
+}
+
 // query is a log function of the sql queries without parameters.
-func (g Gob) query(topic string, d time.Duration, query string, derr error) {
+func (g Gob) query(ctx context.Context, topic string, d time.Duration, query string, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
 	buf := bufPool.Get().(*bytes.Buffer)
 	buf.Reset()
-	defer bufPool.Put(buf)
-	defer func() { io.Copy(g.Writer, newReader(d, buf.Bytes())) }()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, topic, d)
+	if err != nil {
+		return
+	}
 
-	_, err := buf.Write([]byte(fmt.Sprintf("%s %s %s", g.Topic, topic, d)))
+	err = g.sequence(ctx, buf)
 	if err != nil {
 		return
 	}
 
 	if derr != nil { // && derr != driver.ErrSkip {
-		_, err = buf.Write([]byte(fmt.Sprintf(" error: %v", derr)))
+		err = f.write(buf, fieldError, g.errorString(derr))
 		if err != nil {
 			return
 		}
 	}
 
 	if query != "" {
-		_, err = buf.Write([]byte(fmt.Sprintf(" query: %s", query)))
+		err = f.write(buf, fieldQuery, fmt.Sprintf(" query: %s", query))
 		if err != nil {
 			return
 		}
@@ -201,93 +755,150 @@ func (g Gob) query(topic string, d time.Duration, query string, derr error) {
 }
 
 // interpolation is a log function of the sql query interpolations or queries with parameters.
-func (g Gob) interpolation(topic string, d time.Duration, query string, dargs []driver.Value, nvdargs []driver.NamedValue, res driver.Result, derr error) {
+func (g Gob) interpolation(ctx context.Context, topic string, d time.Duration, query string, dargs []driver.Value, nvdargs []driver.NamedValue, res driver.Result, derr error) {
+	if g.skip(derr) {
+		return
+	}
+
+	d = g.round(d)
 	buf := bufPool.Get().(*bytes.Buffer)
 	buf.Reset()
-	defer bufPool.Put(buf)
-	defer func() { io.Copy(g.Writer, newReader(d, buf.Bytes())) }()
+	defer putBuf(buf)
+	var f fields
+	defer func() { g.write(d, buf, &f) }()
+
+	err := f.header(buf, g.Topic, topic, d)
+	if err != nil {
+		return
+	}
 
-	_, err := buf.Write([]byte(fmt.Sprintf("%s %s %s", g.Topic, topic, d)))
+	err = g.sequence(ctx, buf)
 	if err != nil {
 		return
 	}
 
 	if derr != nil { // && derr != driver.ErrSkip {
-		_, err = buf.Write([]byte(fmt.Sprintf(" error: %v", derr)))
+		err = f.write(buf, fieldError, g.errorString(derr))
 		if err != nil {
 			return
 		}
 	}
 
-	var interpolation string
+	if budget, ok := sqltee.DeadlineBudget(ctx); ok && errors.Is(derr, context.DeadlineExceeded) {
+		_, err = buf.Write([]byte(fmt.Sprintf(" timeout: budget=%s elapsed=%s", g.round(budget), d)))
+		if err != nil {
+			return
+		}
+	}
 
-	scan := sqlteescan.GetScanner()
-	scan.Values = dargs
-	scan.NamedValues = nvdargs
-	scan.Reverse = true
-	defer sqlteescan.PutScanner(scan)
+	if g.CompressInLists {
+		query, dargs, nvdargs = sqlteescan.CompressInLists(query, g.Placeholder, dargs, nvdargs)
+	}
 
-	for scan.Scan() {
-		if interpolation == "" {
-			interpolation = query
+	if sqltee.ReadOnlyViolation(ctx) {
+		_, err = buf.Write([]byte(" readonly-violation"))
+		if err != nil {
+			return
 		}
+	}
 
-		placeholder, ordinal, value := scan.Param()
-		if placeholder == "" && ordinal != 0 {
-			placeholder = fmt.Sprintf("$%d", ordinal)
+	if sqltee.ImplicitCommit(ctx) {
+		_, err = buf.Write([]byte(" implicit-commit-warning"))
+		if err != nil {
+			return
 		}
+	}
 
-		if g.Placeholder == "" && placeholder != "" {
-			interpolation = strings.Replace(interpolation, placeholder, value, -1)
-
-		} else {
-			if g.Placeholder != "" {
-				placeholder = g.Placeholder
-			} else if placeholder == "" {
-				placeholder = "?"
-			}
+	if n, ok := sqltee.NPlusOne(ctx); ok {
+		_, err = fmt.Fprintf(buf, " n+1-suspected: %dx", n)
+		if err != nil {
+			return
+		}
+	}
 
-			i := strings.LastIndex(interpolation, placeholder)
-			if i != -1 {
-				interpolation = interpolation[:i] + string(value) + interpolation[i+1:]
-			}
+	if n, ok := sqltee.ConsiderTransaction(ctx); ok {
+		_, err = fmt.Fprintf(buf, " consider-transaction: %d writes without BEGIN", n)
+		if err != nil {
+			return
 		}
+	}
 
-		if interpolation == query {
-			interpolation = ""
-			break
+	if ratio, ok := sqltee.PrepareRatio(ctx); ok {
+		_, err = fmt.Fprintf(buf, " prepare-ratio: %.2f", ratio)
+		if err != nil {
+			return
 		}
 	}
 
-	err = scan.Err()
-	if err != nil {
-		interpolation = ""
-		_, err = buf.Write([]byte(fmt.Sprintf(" parameters scan error: %s", err)))
+	interpolation, serr := g.interpolate(ctx, query, dargs, nvdargs)
+	if serr != nil {
+		_, err = buf.Write([]byte(fmt.Sprintf(" parameters scan error: %s", serr)))
 		if err != nil {
 			return
 		}
 	}
 
 	if interpolation != "" {
-		_, err = buf.Write([]byte(fmt.Sprintf(" query interpolation: %s", interpolation)))
+		err = f.write(buf, fieldQuery, fmt.Sprintf(" query interpolation: %s", interpolation))
 		if err != nil {
 			return
 		}
 	} else if query != "" {
-		_, err = buf.Write([]byte(fmt.Sprintf(" query: %s", query)))
+		err = f.write(buf, fieldQuery, fmt.Sprintf(" query: %s", query))
 		if err != nil {
 			return
 		}
 	}
 
-	if interpolation == "" {
-		if len(dargs) != 0 {
-			_, err = buf.Write([]byte(fmt.Sprintf(" args: %+v", dargs)))
+	if placeholders, args, ok := sqlteescan.PlaceholderMismatch(query, g.Placeholder, dargs, nvdargs); ok {
+		_, err = buf.Write([]byte(fmt.Sprintf(" placeholder-arg-mismatch: %d placeholders, %d args", placeholders, args)))
+		if err != nil {
+			return
+		}
+	}
+
+	// the batches of the multi-statements (for example of the MySQL multiStatements)
+	// are executed, the query without the semicolon is not scanned
+	if strings.Contains(topic, "-exec") && strings.IndexByte(query, ';') != -1 {
+		if n := sqlteescan.StatementCount(query); n > 1 {
+			_, err = fmt.Fprintf(buf, " statements: %d", n)
 			if err != nil {
 				return
 			}
-		} else if len(nvdargs) != 0 {
-			_, err = buf.Write([]byte(fmt.Sprintf(" args: %+v", nvdargs)))
+		}
+	}
+
+	if g.Normalize && query != "" {
+		_, err = buf.Write([]byte(fmt.Sprintf(" normalized: %s", sqlteescan.Normalize(query))))
+		if err != nil {
+			return
+		}
+	}
+
+	if params, ok := g.paramMap(dargs, nvdargs); ok {
+		_, err = buf.Write([]byte(fmt.Sprintf(" params: %s", params)))
+		if err != nil {
+			return
+		}
+	} else if interpolation == "" {
+		if p, ok := g.jsonArgs(g.sized(dargs, nvdargs)); ok {
+			_, err = buf.Write([]byte(" args: "))
+			if err != nil {
+				return
+			}
+			_, err = buf.Write(p)
+			if err != nil {
+				return
+			}
+		} else if len(dargs) != 0 || len(nvdargs) != 0 {
+			assert := g.assert()
+			if assert == nil {
+				assert = g.Dialect.ValueString
+			}
+
+			types, _ := sqltee.ParamTypes(ctx)
+
+			_, err = buf.Write([]byte(" args: " + sqlteescan.FormatTypedArgs(assert, types, dargs, nvdargs)))
 			if err != nil {
 				return
 			}
@@ -295,34 +906,278 @@ func (g Gob) interpolation(topic string, d time.Duration, query string, dargs []
 	}
 
 	if res != nil {
-		if id, err := res.LastInsertId(); err == nil && id != 0 {
+		// result calls may round-trip to the database
+		// so they are timed before the fields are written
+		var t sqltee.Timer
+		if g.ResultFetchThreshold > 0 {
+			t = g.NewTimer()
+		}
+
+		id, idErr := res.LastInsertId()
+
+		var (
+			all  []int64
+			n    int64
+			nErr error
+		)
+		if r, ok := res.(sqltee.ResultAllRowsAffected); ok {
+			all = r.AllRowsAffected()
+		}
+		if len(all) <= 1 {
+			n, nErr = res.RowsAffected()
+		}
+
+		var warnings []string
+		if r, ok := res.(sqltee.ResultWarnings); ok {
+			warnings = r.Warnings()
+		}
+
+		var fetch time.Duration
+		if t != nil {
+			fetch = t.Stop()
+		}
+
+		if idErr == nil && id != 0 {
 			_, err = buf.Write([]byte(fmt.Sprintf(" last-insert-id: %s", strconv.FormatInt(id, 10))))
 			if err != nil {
 				return
 			}
 		}
 
-		if n, err := res.RowsAffected(); err == nil && n != 0 {
+		if len(all) > 1 {
+			_, err = buf.Write([]byte(fmt.Sprintf(" rows-affected: %v", all)))
+			if err != nil {
+				return
+			}
+		} else if nErr == nil && n != 0 {
 			_, err = buf.Write([]byte(fmt.Sprintf(" rows-affected: %s", strconv.FormatInt(n, 10))))
 			if err != nil {
 				return
 			}
 		}
+
+		if len(warnings) != 0 {
+			_, err = buf.Write([]byte(fmt.Sprintf(" warnings: %q", warnings)))
+			if err != nil {
+				return
+			}
+		}
+
+		if t != nil && fetch >= g.ResultFetchThreshold {
+			_, err = buf.Write([]byte(fmt.Sprintf(" result-fetch: %s", g.round(fetch))))
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// interpolate returns the query with interpolated parameters
+// (reusing the placeholder layout of the prepared statement if any)
+// or an empty string if nothing was substituted or if NoInterpolate is true.
+func (g Gob) interpolate(ctx context.Context, query string, dargs []driver.Value, nvdargs []driver.NamedValue) (string, error) {
+	if g.NoInterpolate {
+		return "", nil
+	}
+
+	scan := sqlteescan.GetScanner()
+	scan.Values = dargs
+	scan.NamedValues = nvdargs
+	scan.Dialect = g.Dialect
+	scan.MaxArgs = g.MaxArgs
+	scan.Placeholder = g.PlaceholderStyle
+	scan.Assert = g.assert()
+	scan.Types, _ = sqltee.ParamTypes(ctx)
+	defer sqlteescan.PutScanner(scan)
+
+	if layout, ok := sqltee.QueryLayout(ctx); ok {
+		return layout.Interpolate(scan, query, g.Placeholder)
+	}
+
+	return scan.Interpolate(query, g.Placeholder)
+}
+
+// jsonArg is a JSON representation of the parameter.
+type jsonArg struct {
+	Name    string      `json:"name,omitempty"`
+	Ordinal int         `json:"ordinal"`
+	Value   interface{} `json:"value"`
+}
+
+// jsonArgs returns the parameters marshaled to the JSON array
+// if JSONArgs is true and the parameters are not empty.
+func (g Gob) jsonArgs(dargs []driver.Value, nvdargs []driver.NamedValue) ([]byte, bool) {
+	if !g.JSONArgs || (len(dargs) == 0 && len(nvdargs) == 0) {
+		return nil, false
+	}
+
+	args := make([]jsonArg, 0, len(dargs)+len(nvdargs))
+	for i, v := range dargs {
+		args = append(args, jsonArg{Ordinal: i + 1, Value: v})
+	}
+	for _, v := range nvdargs {
+		args = append(args, jsonArg{Name: v.Name, Ordinal: v.Ordinal, Value: v.Value})
+	}
+
+	p, err := json.Marshal(args)
+	if err != nil {
+		return nil, false
+	}
+
+	return p, true
+}
+
+// assert returns the type assertion function of the parameter values
+// or nil if the values are rendered by the dialect as is.
+func (g Gob) assert() sqlteescan.AssertFunc {
+	var assert sqlteescan.AssertFunc
+
+	if g.TimeFormat != sqlteescan.TimeRFC3339 {
+		assert = sqlteescan.TimeString(g.TimeFormat, g.Dialect.ValueString)
+	}
+
+	if g.MaxValueSize > 0 {
+		if assert == nil {
+			assert = g.Dialect.ValueString
+		}
+		assert = sqlteescan.SizeString(g.MaxValueSize, assert)
+	}
+
+	return assert
+}
+
+// paramMap returns the parameters rendered as the name=value list
+// (for example {id=42, name='foo'} or {$1=42, $2='foo'} for the positional
+// parameters) if ParamMap is true and the parameters are not empty.
+func (g Gob) paramMap(dargs []driver.Value, nvdargs []driver.NamedValue) (string, bool) {
+	if !g.ParamMap || (len(dargs) == 0 && len(nvdargs) == 0) {
+		return "", false
+	}
+
+	assert := g.assert()
+	if assert == nil {
+		assert = g.Dialect.ValueString
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+
+	param := func(name string, value interface{}) {
+		if b.Len() > 1 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+
+		s, err := assert(value)
+		if err != nil {
+			s = fmt.Sprintf("%v", value)
+		}
+		b.WriteString(s)
+	}
+
+	for i, v := range dargs {
+		param("$"+strconv.Itoa(i+1), v)
+	}
+	for _, v := range nvdargs {
+		name := v.Name
+		if name == "" {
+			name = "$" + strconv.Itoa(v.Ordinal)
+		}
+		param(name, v.Value)
+	}
+
+	b.WriteByte('}')
+
+	return b.String(), true
+}
+
+// dest returns the row values with the byte slices rendered
+// as the quoted strings if they are valid UTF-8 and as the hex otherwise
+// (or the size markers if the byte slices exceeds MaxValueSize).
+func (g Gob) dest(dest []driver.Value) []driver.Value {
+	var values []driver.Value
+
+	for i, v := range dest {
+		p, ok := v.([]byte)
+		if !ok {
+			continue
+		}
+
+		if values == nil {
+			values = make([]driver.Value, len(dest))
+			copy(values, dest)
+		}
+
+		if marker, ok := sqlteescan.SizeMarker(p, g.MaxValueSize); g.MaxValueSize > 0 && ok {
+			values[i] = marker
+		} else if utf8.Valid(p) {
+			values[i] = strconv.Quote(string(p))
+		} else {
+			values[i] = fmt.Sprintf("0x%x", p)
+		}
+	}
+
+	if values == nil {
+		return dest
+	}
+
+	return values
+}
+
+// sized returns copies of the parameters where []byte and string values
+// longer than MaxValueSize bytes are replaced by size markers.
+func (g Gob) sized(dargs []driver.Value, nvdargs []driver.NamedValue) ([]driver.Value, []driver.NamedValue) {
+	if g.MaxValueSize <= 0 {
+		return dargs, nvdargs
+	}
+
+	if len(dargs) != 0 {
+		values := make([]driver.Value, len(dargs))
+		for i, v := range dargs {
+			values[i] = v
+			if marker, ok := sqlteescan.SizeMarker(v, g.MaxValueSize); ok {
+				values[i] = marker
+			}
+		}
+		dargs = values
+	}
+
+	if len(nvdargs) != 0 {
+		values := make([]driver.NamedValue, len(nvdargs))
+		for i, v := range nvdargs {
+			values[i] = v
+			if marker, ok := sqlteescan.SizeMarker(v.Value, g.MaxValueSize); ok {
+				values[i].Value = marker
+			}
+		}
+		nvdargs = values
 	}
+
+	return dargs, nvdargs
 }
 
 type bin struct {
 	Duration    time.Duration
 	Description []byte
+	Time        time.Time // zero time is not transmitted by gob, so the records without time are unchanged
 }
 
 var binPool = sync.Pool{New: func() interface{} { return new(bin) }}
 
-func newReader(d time.Duration, desc []byte) io.Reader {
+// putBin returns the binary into the pool if its description is not oversized.
+func putBin(b *bin) {
+	if cap(b.Description) <= maxPooledSize {
+		binPool.Put(b)
+	}
+}
+
+func newReader(d time.Duration, t time.Time, desc []byte) io.Reader {
 	b := binPool.Get().(*bin)
 	b.Duration = d
+	b.Time = t
 	b.Description = append(b.Description[:0], desc...)
-	return reader{binary: b}
+	return &reader{binary: b}
 }
 
 type reader struct {
@@ -331,30 +1186,149 @@ type reader struct {
 	done   bool          // Read has finished.
 }
 
-func (r reader) Read(p []byte) (int, error) {
+func (r *reader) Read(p []byte) (int, error) {
 	if r.done {
 		return 0, io.EOF
 
 	} else if r.buf == nil {
-		buf := bufPool.Get().(*bytes.Buffer)
-		buf.Reset()
-		enc := gob.NewEncoder(buf)
-
-		err := enc.Encode(*r.binary)
-		binPool.Put(r.binary)
+		err := r.encode()
 		if err != nil {
 			return 0, err
 		}
-
-		r.buf = buf
 	}
 
 	n, err := r.buf.Read(p)
 	if err == io.EOF {
 		r.done = true
-		bufPool.Put(r.buf)
+		putBuf(r.buf)
 		r.buf = nil
 	}
 
 	return n, err
 }
+
+// WriteTo writes the whole record by the single Write call
+// so the concurrent records are never interleaved by io.Copy.
+func (r *reader) WriteTo(w io.Writer) (int64, error) {
+	if r.done {
+		return 0, nil
+
+	} else if r.buf == nil {
+		err := r.encode()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// The rest of the record partially written without the error
+	// is written again while the writer makes progress, so the short write
+	// does not corrupt the framing of the subsequent records.
+	// The failed write is returned immediately.
+	var n int
+	var err error
+	for p := r.buf.Bytes(); len(p) != 0; {
+		var m int
+		m, err = w.Write(p)
+		n += m
+		p = p[m:]
+		if err != nil {
+			break
+		}
+		if m == 0 {
+			err = io.ErrShortWrite
+			break
+		}
+	}
+
+	r.done = true
+	putBuf(r.buf)
+	r.buf = nil
+
+	return int64(n), err
+}
+
+// encode encodes the binary into the buffer.
+func (r *reader) encode() error {
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	enc := gob.NewEncoder(buf)
+
+	err := enc.Encode(*r.binary)
+	putBin(r.binary)
+	if err != nil {
+		putBuf(buf)
+		return err
+	}
+
+	r.buf = buf
+
+	return nil
+}
+
+// SyncWriter returns a writer which serializes the calls of the Write method,
+// so the Gob loggers used concurrently may share the writer
+// which is not safe for concurrent use (for example bytes.Buffer).
+func SyncWriter(w io.Writer) io.Writer {
+	return &syncWriter{w: w}
+}
+
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// lengthPrefixSize is a size of the length prefix of the record.
+const lengthPrefixSize = 4
+
+// maxFrame is a maximum length of the length prefixed record,
+// so the corrupt length prefix does not make the decoder allocate
+// (and wait for) up to 4 GiB.
+const maxFrame = 16 << 20
+
+// ErrFrameTooLarge is returned by the LengthPrefixedWriter for the record
+// and by the Next of the length prefixed decoder for the length prefix
+// larger than 16 MiB.
+var ErrFrameTooLarge = errors.New("sqlteegob: frame is too large")
+
+// LengthPrefixedWriter returns a writer which prefixes each record
+// (each call of the Write method) with the 4-byte big-endian length
+// of the record, so the reader of the stream (see NewLengthPrefixedDecoder)
+// may read exactly one record at a time and detect the incomplete one.
+func LengthPrefixedWriter(w io.Writer) io.Writer {
+	return lengthPrefixedWriter{w: w}
+}
+
+type lengthPrefixedWriter struct {
+	w io.Writer
+}
+
+func (l lengthPrefixedWriter) Write(p []byte) (int, error) {
+	if len(p) > maxFrame {
+		return 0, ErrFrameTooLarge
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer putBuf(buf)
+
+	var h [lengthPrefixSize]byte
+	binary.BigEndian.PutUint32(h[:], uint32(len(p)))
+	buf.Write(h[:])
+	buf.Write(p)
+
+	n, err := l.w.Write(buf.Bytes())
+	if n -= lengthPrefixSize; n < 0 {
+		n = 0
+	}
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+
+	return n, err
+}
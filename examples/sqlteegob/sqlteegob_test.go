@@ -6,22 +6,29 @@ package sqlteegob_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
-	"encoding/gob"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/danil/sqltee"
 	"github.com/danil/sqltee/examples/sqlteegob"
 	"github.com/danil/sqltee/internal/fakedb"
+	"github.com/danil/sqltee/sqlteescan"
 )
 
 var gobTests = []struct {
@@ -35,10 +42,10 @@ var gobTests = []struct {
 		name: "wipe (truncate)",
 		line: line(),
 		expected: `{"Duration":42,"Description":"fakedb driver-open 42ns"}
-{"Duration":42,"Description":"fakedb conn-exec-context 42ns error: driver: skip fast-path; continue as if unimplemented query: WIPE"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns fast-path: driver-skip query: WIPE"}
 {"Duration":42,"Description":"fakedb conn-prepare-context 42ns query: WIPE"}
-{"Duration":42,"Description":"fakedb stmt-exec-context 42ns"}
-{"Duration":42,"Description":"fakedb stmt-close 42ns"}
+{"Duration":42,"Description":"fakedb stmt-exec-context 42ns query: WIPE"}
+{"Duration":42,"Description":"fakedb stmt-close 42ns stmt-total: 126ns"}
 {"Duration":42,"Description":"fakedb conn-close 42ns"}
 `,
 		fetch: func(db *sql.DB) error {
@@ -52,24 +59,24 @@ var gobTests = []struct {
 		name: "query from existing table",
 		line: line(),
 		expected: `{"Duration":42,"Description":"fakedb driver-open 42ns"}
-{"Duration":42,"Description":"fakedb conn-exec-context 42ns error: driver: skip fast-path; continue as if unimplemented query: CREATE|tbl|id=int64,name=string"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns fast-path: driver-skip query: CREATE|tbl|id=int64,name=string"}
 {"Duration":42,"Description":"fakedb conn-prepare-context 42ns query: CREATE|tbl|id=int64,name=string"}
-{"Duration":42,"Description":"fakedb stmt-exec-context 42ns"}
-{"Duration":42,"Description":"fakedb stmt-close 42ns"}
-{"Duration":42,"Description":"fakedb conn-exec-context 42ns error: driver: skip fast-path; continue as if unimplemented query interpolation: INSERT|tbl|id=42,name='foo'"}
+{"Duration":42,"Description":"fakedb stmt-exec-context 42ns query: CREATE|tbl|id=int64,name=string"}
+{"Duration":42,"Description":"fakedb stmt-close 42ns stmt-total: 126ns"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns fast-path: driver-skip query interpolation: INSERT|tbl|id=42,name='foo'"}
 {"Duration":42,"Description":"fakedb conn-prepare-context 42ns query: INSERT|tbl|id=?,name=?"}
-{"Duration":42,"Description":"fakedb stmt-exec-context 42ns args: [{Name: Ordinal:1 Value:42} {Name: Ordinal:2 Value:foo}] rows-affected: 1"}
-{"Duration":42,"Description":"fakedb stmt-close 42ns"}
-{"Duration":42,"Description":"fakedb conn-query-context 42ns error: driver: skip fast-path; continue as if unimplemented query interpolation: SELECT|tbl|id|name='foo'"}
+{"Duration":42,"Description":"fakedb stmt-exec-context 42ns query interpolation: INSERT|tbl|id=42,name='foo' rows-affected: 1"}
+{"Duration":42,"Description":"fakedb stmt-close 42ns stmt-total: 126ns"}
+{"Duration":42,"Description":"fakedb conn-query-context 42ns fast-path: driver-skip query interpolation: SELECT|tbl|id|name='foo'"}
 {"Duration":42,"Description":"fakedb conn-prepare-context 42ns query: SELECT|tbl|id|name=?"}
-{"Duration":42,"Description":"fakedb stmt-query-context 42ns args: [{Name: Ordinal:1 Value:foo}]"}
-{"Duration":42,"Description":"fakedb rows-next 42ns dest: [42]"}
-{"Duration":42,"Description":"fakedb rows-next 42ns error: EOF dest: [42]"}
-{"Duration":42,"Description":"fakedb stmt-close 42ns"}
-{"Duration":42,"Description":"fakedb conn-exec-context 42ns error: driver: skip fast-path; continue as if unimplemented query: WIPE"}
+{"Duration":42,"Description":"fakedb stmt-query-context 42ns query interpolation: SELECT|tbl|id|name='foo'"}
+{"Duration":42,"Description":"fakedb rows-next 42ns dest: {id:42}"}
+{"Duration":42,"Description":"fakedb rows-next 42ns eof dest: {id:42}"}
+{"Duration":42,"Description":"fakedb stmt-close 42ns stmt-total: 126ns"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns fast-path: driver-skip query: WIPE"}
 {"Duration":42,"Description":"fakedb conn-prepare-context 42ns query: WIPE"}
-{"Duration":42,"Description":"fakedb stmt-exec-context 42ns"}
-{"Duration":42,"Description":"fakedb stmt-close 42ns"}
+{"Duration":42,"Description":"fakedb stmt-exec-context 42ns query: WIPE"}
+{"Duration":42,"Description":"fakedb stmt-close 42ns stmt-total: 126ns"}
 {"Duration":42,"Description":"fakedb conn-close 42ns"}
 `,
 		fetch: func(db *sql.DB) error {
@@ -116,7 +123,7 @@ var gobTests = []struct {
 		name: "query non existing table",
 		line: line(),
 		expected: `{"Duration":42,"Description":"fakedb driver-open 42ns"}
-{"Duration":42,"Description":"fakedb conn-query-context 42ns error: driver: skip fast-path; continue as if unimplemented query: SELECT|nonexistent_table|nonexistent_column|nonexistent_column=42"}
+{"Duration":42,"Description":"fakedb conn-query-context 42ns fast-path: driver-skip query: SELECT|nonexistent_table|nonexistent_column|nonexistent_column=42"}
 {"Duration":42,"Description":"fakedb conn-prepare-context 42ns error: fakedb: SELECT on table \"nonexistent_table\" references non-existent column \"nonexistent_column\" query: SELECT|nonexistent_table|nonexistent_column|nonexistent_column=42"}
 {"Duration":42,"Description":"fakedb conn-close 42ns"}
 `,
@@ -202,53 +209,40 @@ func BenchmarkGob(b *testing.B) {
 	}
 }
 
-type buffer struct{ buf bytes.Buffer }
+type buffer struct {
+	buf bytes.Buffer
+	err error // first decode error
+}
 
 func (buf *buffer) String() string {
 	return buf.buf.String()
 }
 
-type bin struct {
-	Duration    time.Duration
-	Description []byte
-}
-
-func (b bin) MarshalJSON() ([]byte, error) {
-	return json.Marshal(
-		struct {
-			Duration    time.Duration
-			Description string
-		}{
-			Duration:    b.Duration,
-			Description: string(b.Description),
-		},
-	)
-}
-
-var pool = sync.Pool{New: func() interface{} { return new(bin) }}
-
 func (buf *buffer) Write(p []byte) (int, error) {
-	b := pool.Get().(*bin)
-	b.Duration = 0
-	b.Description = b.Description[:0]
-	defer pool.Put(b)
-
-	r := bytes.NewReader(p)
-	dec := gob.NewDecoder(r)
-
-	err := dec.Decode(b)
+	rec, err := sqlteegob.NewDecoder(bytes.NewReader(p)).Next()
 	if err != nil {
+		if buf.err == nil {
+			buf.err = err
+		}
 		return 0, err
 	}
 
-	j, err := json.Marshal(b)
+	j, err := json.Marshal(struct {
+		Duration    time.Duration
+		Description string
+	}{rec.Duration, rec.Description})
 	if err != nil {
 		return 0, err
 	}
 
 	j = append(j, '\n')
 
-	return buf.buf.Write(j)
+	_, err = buf.buf.Write(j)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
 }
 
 type timer struct {
@@ -280,10 +274,10 @@ func TestGobSQLOpen(t *testing.T) {
 	}
 
 	expected := `{"Duration":[0-9]+,"Description":"fakedb driver-open [0-9.nµms]+"}
-{"Duration":[0-9]+,"Description":"fakedb conn-exec-context [0-9.nµms]+ error: driver: skip fast-path; continue as if unimplemented query: WIPE"}
+{"Duration":[0-9]+,"Description":"fakedb conn-exec-context [0-9.nµms]+ fast-path: driver-skip query: WIPE"}
 {"Duration":[0-9]+,"Description":"fakedb conn-prepare-context [0-9.nµms]+ query: WIPE"}
-{"Duration":[0-9]+,"Description":"fakedb stmt-exec-context [0-9.nµms]+"}
-{"Duration":[0-9]+,"Description":"fakedb stmt-close [0-9.nµms]+"}
+{"Duration":[0-9]+,"Description":"fakedb stmt-exec-context [0-9.nµms]+ query: WIPE"}
+{"Duration":[0-9]+,"Description":"fakedb stmt-close [0-9.nµms]+ stmt-total: [0-9.nµms]+"}
 $`
 
 	r, err := regexp.Compile(expected)
@@ -318,10 +312,10 @@ func TestGobSQLOpenDB(t *testing.T) {
 	}
 
 	expected := `{"Duration":[0-9]+,"Description":"fakedb driver-open [0-9.nµms]+"}
-{"Duration":[0-9]+,"Description":"fakedb conn-exec-context [0-9.nµms]+ error: driver: skip fast-path; continue as if unimplemented query: WIPE"}
+{"Duration":[0-9]+,"Description":"fakedb conn-exec-context [0-9.nµms]+ fast-path: driver-skip query: WIPE"}
 {"Duration":[0-9]+,"Description":"fakedb conn-prepare-context [0-9.nµms]+ query: WIPE"}
-{"Duration":[0-9]+,"Description":"fakedb stmt-exec-context [0-9.nµms]+"}
-{"Duration":[0-9]+,"Description":"fakedb stmt-close [0-9.nµms]+"}
+{"Duration":[0-9]+,"Description":"fakedb stmt-exec-context [0-9.nµms]+ query: WIPE"}
+{"Duration":[0-9]+,"Description":"fakedb stmt-close [0-9.nµms]+ stmt-total: [0-9.nµms]+"}
 $`
 
 	r, err := regexp.Compile(expected)
@@ -333,6 +327,2177 @@ $`
 	}
 }
 
+func TestGobDSN(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, DSN: true}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("host=db1 password=secret dbname=app")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	err = db.Ping()
+	if err != nil {
+		t.Fatalf("db ping error: %#v", err)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb driver-open 42ns dsn: host=db1 dbname=app"}
+{"Duration":42,"Description":"fakedb conn-ping 42ns ping: unsupported"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+type batchResult []int64
+
+func (r batchResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (r batchResult) RowsAffected() (int64, error) {
+	return r[len(r)-1], nil
+}
+
+func (r batchResult) AllRowsAffected() []int64 {
+	return r
+}
+
+func TestGobAllRowsAffected(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	var _ sqltee.ResultAllRowsAffected = batchResult{}
+
+	g.ConnExec(42, "DELETE FROM foo; UPDATE bar SET baz=1; DELETE FROM xyz", nil, batchResult{3, 0, 7}, nil)
+	g.ConnExec(42, "DELETE FROM foo", nil, batchResult{3}, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec 42ns query: DELETE FROM foo; UPDATE bar SET baz=1; DELETE FROM xyz statements: 3 rows-affected: [3 0 7]"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query: DELETE FROM foo rows-affected: 3"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobDurationRound(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, DurationRound: time.Microsecond}
+
+	g.ConnClose(400*time.Nanosecond, nil)
+	g.ConnPrepare(600*time.Nanosecond, "SELECT 1", nil)
+	g.ConnExec(1499*time.Nanosecond, "SELECT ?", []driver.Value{int64(1)}, nil, nil)
+	g.RowsNextRow(2500*time.Nanosecond, 1, nil, nil, nil)
+	g.DriverOpen("", 999*time.Nanosecond, nil)
+
+	expected := `{"Duration":0,"Description":"fakedb conn-close 0s"}
+{"Duration":1000,"Description":"fakedb conn-prepare 1µs query: SELECT 1"}
+{"Duration":1000,"Description":"fakedb conn-exec 1µs query interpolation: SELECT 1"}
+{"Duration":3000,"Description":"fakedb rows-next 3µs"}
+{"Duration":1000,"Description":"fakedb driver-open 1µs"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobRowsNextEOF(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	g.RowsNextRow(42, 1, nil, []driver.Value{int64(1)}, nil)
+	g.RowsNextRow(42, 1, nil, nil, errors.New("bad connection"))
+	g.RowsNextRow(42, 1, nil, nil, io.EOF)
+
+	expected := `{"Duration":42,"Description":"fakedb rows-next 42ns dest: [1]"}
+{"Duration":42,"Description":"fakedb rows-next 42ns error: bad connection"}
+{"Duration":42,"Description":"fakedb rows-next 42ns eof"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobRowsNextBytes(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, MaxValueSize: 8}
+
+	g.RowsNextRow(42, 1, nil, []driver.Value{int64(1), []byte("foo bar")}, nil)
+	g.RowsNextRow(42, 1, nil, []driver.Value{[]byte{0xde, 0xad, 0xbe, 0xef}, "baz"}, nil)
+	g.RowsNextRow(42, 1, nil, []driver.Value{[]byte("foo bar baz")}, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb rows-next 42ns dest: [1 \"foo bar\"]"}
+{"Duration":42,"Description":"fakedb rows-next 42ns dest: [0xdeadbeef baz]"}
+{"Duration":42,"Description":"fakedb rows-next 42ns dest: [\u003cbytes:11\u003e]"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobRowsNextColumns(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		columns  []string
+		dest     []driver.Value
+		expected string
+	}{
+		{
+			name:     "matching columns",
+			line:     line(),
+			columns:  []string{"id", "name"},
+			dest:     []driver.Value{int64(42), "foo"},
+			expected: `{"Duration":42,"Description":"fakedb rows-next 42ns dest: {id:42, name:foo}"}` + "\n",
+		},
+		{
+			name:     "more dest than columns",
+			line:     line(),
+			columns:  []string{"id"},
+			dest:     []driver.Value{int64(42), "foo"},
+			expected: `{"Duration":42,"Description":"fakedb rows-next 42ns dest: [42 foo] column-mismatch: columns=1 dest=2"}` + "\n",
+		},
+		{
+			name:     "more columns than dest",
+			line:     line(),
+			columns:  []string{"id", "name", "age"},
+			dest:     []driver.Value{int64(42)},
+			expected: `{"Duration":42,"Description":"fakedb rows-next 42ns dest: [42] column-mismatch: columns=3 dest=1"}` + "\n",
+		},
+		{
+			name:     "unknown columns",
+			line:     line(),
+			dest:     []driver.Value{int64(42)},
+			expected: `{"Duration":42,"Description":"fakedb rows-next 42ns dest: [42]"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			buf := buffer{}
+			tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+			g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+			g.RowsNextRow(42, 1, tt.columns, tt.dest, nil)
+
+			if buf.String() != tt.expected {
+				t.Errorf("unexpected log, expected: %v, recieved: %v %s", tt.expected, buf.String(), tt.line)
+			}
+		})
+	}
+}
+
+// legacyDriver is a driver which does not support context-aware interfaces.
+type legacyDriver struct{}
+
+func (legacyDriver) Open(string) (driver.Conn, error) { return legacyConn{}, nil }
+
+type legacyConn struct{}
+
+func (legacyConn) Prepare(string) (driver.Stmt, error) { return legacyStmt{}, nil }
+func (legacyConn) Close() error                        { return nil }
+func (legacyConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("legacy: begin is not supported")
+}
+
+type legacyStmt struct{}
+
+func (legacyStmt) Close() error                               { return nil }
+func (legacyStmt) NumInput() int                              { return -1 }
+func (legacyStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (legacyStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("legacy: query is not supported")
+}
+
+func TestGobPrepareFallback(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "legacy", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: legacyDriver{}, Logger: g}
+
+	c, err := drv.OpenConnector("legacy")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("UPDATE foo SET bar = ?", 42)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	expected := `{"Duration":42,"Description":"legacy driver-open 42ns"}
+{"Duration":42,"Description":"legacy conn-exec 42ns fast-path: unsupported query interpolation: UPDATE foo SET bar = 42"}
+{"Duration":42,"Description":"legacy conn-exec-context 42ns query interpolation: UPDATE foo SET bar = 42"}
+{"Duration":42,"Description":"legacy conn-prepare-fallback 42ns query: UPDATE foo SET bar = ?"}
+{"Duration":42,"Description":"legacy stmt-exec 42ns query interpolation: UPDATE foo SET bar = 42 rows-affected: 1"}
+{"Duration":42,"Description":"legacy stmt-exec-context 42ns query interpolation: UPDATE foo SET bar = 42"}
+{"Duration":42,"Description":"legacy stmt-close 42ns stmt-total: 126ns"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+// pendingWriter holds the records until the flush.
+type pendingWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	pending [][]byte
+}
+
+func (pw *pendingWriter) Write(p []byte) (int, error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.pending = append(pw.pending, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+// bufferedGob is a logger which writes records only on close.
+type bufferedGob struct {
+	sqlteegob.Gob
+	pw *pendingWriter
+}
+
+func (b bufferedGob) Close() error {
+	b.pw.mu.Lock()
+	defer b.pw.mu.Unlock()
+	for _, p := range b.pw.pending {
+		_, err := b.pw.w.Write(p)
+		if err != nil {
+			return err
+		}
+	}
+	b.pw.pending = nil
+	return nil
+}
+
+func TestGobDriverClose(t *testing.T) {
+	buf := buffer{}
+	pw := &pendingWriter{w: &buf}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := bufferedGob{Gob: sqlteegob.Gob{Writer: pw, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}, pw: pw}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_driver_close")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+
+	_, err = db.Exec(`WIPE`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("db close error: %#v", err)
+	}
+
+	if buf.String() != "" {
+		t.Fatalf("unexpected log before driver close: %v", buf.String())
+	}
+
+	err = drv.Close()
+	if err != nil {
+		t.Fatalf("driver close error: %#v", err)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb driver-open 42ns"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns fast-path: driver-skip query: WIPE"}
+{"Duration":42,"Description":"fakedb conn-prepare-context 42ns query: WIPE"}
+{"Duration":42,"Description":"fakedb stmt-exec-context 42ns query: WIPE"}
+{"Duration":42,"Description":"fakedb stmt-close 42ns stmt-total: 126ns"}
+{"Duration":42,"Description":"fakedb conn-close 42ns"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+
+	err = (&sqltee.Driver{Driver: fakedb.Driver, Logger: g.Gob}).Close()
+	if err != nil {
+		t.Errorf("driver close without closer error: %#v", err)
+	}
+}
+
+// planDriver is a driver which returns a canned plan on EXPLAIN.
+type planDriver struct{}
+
+func (planDriver) Open(string) (driver.Conn, error) { return planConn{}, nil }
+
+type planConn struct{}
+
+func (planConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("plan: prepare is not supported")
+}
+func (planConn) Close() error              { return nil }
+func (planConn) Begin() (driver.Tx, error) { return nil, errors.New("plan: begin is not supported") }
+
+func (planConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if strings.HasPrefix(query, "EXPLAIN ") {
+		return &planRows{columns: []string{"QUERY PLAN"}, values: []driver.Value{"Seq Scan on foo", "  Filter: (id = 42)"}}, nil
+	}
+	return &planRows{columns: []string{"id"}, values: []driver.Value{int64(42)}}, nil
+}
+
+type planRows struct {
+	columns []string
+	values  []driver.Value
+	i       int
+}
+
+func (r *planRows) Columns() []string { return r.columns }
+func (r *planRows) Close() error      { return nil }
+
+func (r *planRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.values) {
+		return io.EOF
+	}
+	dest[0] = r.values[r.i]
+	r.i++
+	return nil
+}
+
+func TestGobExplain(t *testing.T) {
+	var tests = []struct {
+		name              string
+		line              string
+		explainSlowerThan time.Duration
+		query             string
+		expected          string
+	}{
+		{
+			name:              "slow select",
+			line:              line(),
+			explainSlowerThan: 10 * time.Nanosecond,
+			query:             "SELECT id FROM foo WHERE id = ?",
+			expected: `{"Duration":42,"Description":"plan driver-open 42ns"}
+{"Duration":42,"Description":"plan conn-query-context 42ns query interpolation: SELECT id FROM foo WHERE id = 42"}
+{"Duration":42,"Description":"plan rows-next 42ns dest: {id:42}"}
+{"Duration":42,"Description":"plan conn-explain 42ns query: SELECT id FROM foo WHERE id = ? plan: Seq Scan on foo\n  Filter: (id = 42)"}
+`,
+		},
+		{
+			name:              "fast select",
+			line:              line(),
+			explainSlowerThan: 100 * time.Nanosecond,
+			query:             "SELECT id FROM foo WHERE id = ?",
+			expected: `{"Duration":42,"Description":"plan driver-open 42ns"}
+{"Duration":42,"Description":"plan conn-query-context 42ns query interpolation: SELECT id FROM foo WHERE id = 42"}
+{"Duration":42,"Description":"plan rows-next 42ns dest: {id:42}"}
+`,
+		},
+		{
+			name:              "slow update",
+			line:              line(),
+			explainSlowerThan: 10 * time.Nanosecond,
+			query:             "UPDATE foo SET bar = 1 WHERE id = ? RETURNING id",
+			expected: `{"Duration":42,"Description":"plan driver-open 42ns"}
+{"Duration":42,"Description":"plan conn-query-context 42ns query interpolation: UPDATE foo SET bar = 1 WHERE id = 42 RETURNING id"}
+{"Duration":42,"Description":"plan rows-next 42ns dest: {id:42}"}
+`,
+		},
+		{
+			name:  "explain disabled",
+			line:  line(),
+			query: "SELECT id FROM foo WHERE id = ?",
+			expected: `{"Duration":42,"Description":"plan driver-open 42ns"}
+{"Duration":42,"Description":"plan conn-query-context 42ns query interpolation: SELECT id FROM foo WHERE id = 42"}
+{"Duration":42,"Description":"plan rows-next 42ns dest: {id:42}"}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			buf := buffer{}
+			tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+			g := sqlteegob.Gob{Writer: &buf, Topic: "plan", Placeholder: "?", NewTimer: tmr}
+			drv := &sqltee.Driver{Driver: planDriver{}, Logger: g, ExplainSlowerThan: tt.explainSlowerThan}
+
+			c, err := drv.OpenConnector("plan")
+			if err != nil {
+				t.Fatalf("driver open connector error: %#v %s", err, tt.line)
+			}
+
+			db := sql.OpenDB(c)
+			defer db.Close()
+
+			var id int64
+			err = db.QueryRow(tt.query, 42).Scan(&id)
+			if err != nil {
+				t.Fatalf("db query error: %#v %s", err, tt.line)
+			}
+
+			if buf.String() != tt.expected {
+				t.Errorf("unexpected log, expected: %v, recieved: %v %s", tt.expected, buf.String(), tt.line)
+			}
+		})
+	}
+}
+
+func TestGobMaxValueSize(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, MaxValueSize: 8}
+
+	g.ConnExec(42, "INSERT INTO foo (bar, baz) VALUES (?, ?)", []driver.Value{make([]byte, 10240), "short"}, nil, nil)
+	g.ConnExec(42, "INSERT INTO foo (bar, baz) VALUES (?, ?)", []driver.Value{[]byte("tiny"), strings.Repeat("x", 4096)}, nil, nil)
+	g.ConnExecContext(context.Background(), 42, "INSERT INTO foo (bar) VALUES (:bar)", []driver.NamedValue{{Name: "nonexistent", Ordinal: 1, Value: make([]byte, 10240)}}, nil, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: INSERT INTO foo (bar, baz) VALUES (\u003cbytes:10240\u003e, 'short')"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: INSERT INTO foo (bar, baz) VALUES (E'\\\\x74696e79', \u003cstring:4096\u003e)"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns query: INSERT INTO foo (bar) VALUES (:bar) placeholder-arg-mismatch: 0 placeholders, 1 args args: [nonexistent=\u003cbytes:10240\u003e]"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobDurationRecorder(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_duration_recorder")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	var d time.Duration
+	ctx := sqltee.WithDurationRecorder(context.Background(), &d)
+
+	_, err = db.ExecContext(ctx, `WIPE`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	if d != 42*time.Nanosecond {
+		t.Errorf("unexpected recorded duration, expected: %s, recieved: %s", 42*time.Nanosecond, d)
+	}
+}
+
+func TestGobDialect(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "mssql", NewTimer: tmr, Dialect: sqlteescan.DialectSQLServer}
+
+	g.ConnExec(42, "UPDATE foo SET bar = ?, baz = ? WHERE name = ?", []driver.Value{true, []byte("foo"), "bar"}, nil, nil)
+
+	expected := `{"Duration":42,"Description":"mssql conn-exec 42ns query interpolation: UPDATE foo SET bar = 1, baz = 0x666f6f WHERE name = N'bar'"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobStmtTotal(t *testing.T) {
+	buf := buffer{}
+	var n int64
+	tmr := func() sqltee.Timer { return timer{duration: time.Duration(atomic.AddInt64(&n, 10))} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_stmt_total")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec(`WIPE`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	expected := `{"Duration":10,"Description":"fakedb driver-open 10ns"}
+{"Duration":20,"Description":"fakedb conn-exec-context 20ns fast-path: driver-skip query: WIPE"}
+{"Duration":30,"Description":"fakedb conn-prepare-context 30ns query: WIPE"}
+{"Duration":40,"Description":"fakedb stmt-exec-context 40ns query: WIPE"}
+{"Duration":50,"Description":"fakedb stmt-close 50ns stmt-total: 120ns"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobNoInterpolate(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, NoInterpolate: true}
+
+	g.ConnExec(42, "SELECT * FROM foo WHERE id = ? AND name = ?", []driver.Value{int64(42), "bar"}, nil, nil)
+	g.StmtQueryContext(context.Background(), 42, "SELECT * FROM foo WHERE id = $1", []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec 42ns query: SELECT * FROM foo WHERE id = ? AND name = ? args: [42, 'bar']"}
+{"Duration":42,"Description":"fakedb stmt-query-context 42ns query: SELECT * FROM foo WHERE id = $1 placeholder-arg-mismatch: 0 placeholders, 1 args args: [42]"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobPlaceholderArgMismatch(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	g.ConnExec(42, "SELECT * FROM foo WHERE id = ? AND name = ? AND age = ?", []driver.Value{int64(42), "bar"}, nil, nil)
+	g.ConnExec(42, "SELECT * FROM foo WHERE id = ?", []driver.Value{int64(42), "bar"}, nil, nil)
+	g.ConnExec(42, "SELECT * FROM foo WHERE id = ? AND name = ?", []driver.Value{int64(42), "bar"}, nil, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: SELECT * FROM foo WHERE id = 42 AND name = 'bar' AND age = ? placeholder-arg-mismatch: 3 placeholders, 2 args"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: SELECT * FROM foo WHERE id = 42 placeholder-arg-mismatch: 1 placeholders, 2 args"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: SELECT * FROM foo WHERE id = 42 AND name = 'bar'"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func BenchmarkGobInterpolate(b *testing.B) {
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: io.Discard, Topic: "fakedb", NewTimer: tmr}
+	dargs := []driver.Value{int64(42), "bar", true, time.Date(2020, time.November, 21, 13, 56, 42, 0, time.UTC)}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g.ConnExec(42, "SELECT * FROM foo WHERE id = ? AND name = ? AND baz = ? AND created_at < ?", dargs, nil, nil)
+	}
+}
+
+func BenchmarkGobNoInterpolate(b *testing.B) {
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: io.Discard, Topic: "fakedb", NewTimer: tmr, NoInterpolate: true}
+	dargs := []driver.Value{int64(42), "bar", true, time.Date(2020, time.November, 21, 13, 56, 42, 0, time.UTC)}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g.ConnExec(42, "SELECT * FROM foo WHERE id = ? AND name = ? AND baz = ? AND created_at < ?", dargs, nil, nil)
+	}
+}
+
+func TestGobStress(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: sqlteegob.SyncWriter(&buf), Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_stress")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+	db.SetMaxOpenConns(8)
+
+	_, err = db.Exec(`WIPE`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec(`CREATE|tbl|id=int64,name=string`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	const n = 200
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := db.Exec("INSERT|tbl|id=?,name=?", i, strings.Repeat("x", i*200))
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			var name string
+			err = db.QueryRow("SELECT|tbl|name|id=?", i).Scan(&name)
+			if err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent query error: %#v", err)
+	}
+
+	db.Close()
+
+	if buf.err != nil {
+		t.Fatalf("gob frame decode error: %#v", buf.err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for _, topic := range []string{"stmt-exec-context", "stmt-query-context"} {
+		var count int
+		for _, l := range lines {
+			if strings.Contains(l, " "+topic+" ") {
+				count++
+			}
+		}
+		if count < n {
+			t.Errorf("unexpected count of %s records, expected at least: %d, recieved: %d", topic, n, count)
+		}
+	}
+}
+
+func TestGobJSONArgs(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, NoInterpolate: true, JSONArgs: true}
+
+	g.ConnExec(42, "SELECT * FROM foo WHERE id = ? AND name = ?", []driver.Value{int64(42), "bar"}, nil, nil)
+	g.StmtQueryContext(context.Background(), 42, "SELECT * FROM foo WHERE id = $1 AND bin = :bin", []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Name: "bin", Ordinal: 2, Value: []byte("foo")}}, nil)
+
+	var tests = []struct {
+		line     string
+		query    string
+		expected string
+	}{
+		{
+			line:     line(),
+			query:    "SELECT * FROM foo WHERE id = ? AND name = ?",
+			expected: `[{"ordinal":1,"value":42},{"ordinal":2,"value":"bar"}]`,
+		},
+		{
+			line:     line(),
+			query:    "SELECT * FROM foo WHERE id = $1 AND bin = :bin",
+			expected: `[{"ordinal":1,"value":42},{"name":"bin","ordinal":2,"value":"Zm9v"}]`,
+		},
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(tests) {
+		t.Fatalf("unexpected count of records, expected: %d, recieved: %d", len(tests), len(lines))
+	}
+
+	for i, tt := range tests {
+		var b struct{ Description string }
+		err := json.Unmarshal([]byte(lines[i]), &b)
+		if err != nil {
+			t.Fatalf("record unmarshal error: %#v %s", err, tt.line)
+		}
+
+		j := strings.Index(b.Description, " args: ")
+		if j == -1 {
+			t.Fatalf("args not found: %s %s", b.Description, tt.line)
+		}
+
+		if !strings.HasSuffix(b.Description[:j], " query: "+tt.query) {
+			t.Errorf("unexpected query, expected: %s, recieved: %s %s", tt.query, b.Description[:j], tt.line)
+		}
+
+		var args []struct {
+			Name    string      `json:"name"`
+			Ordinal int         `json:"ordinal"`
+			Value   interface{} `json:"value"`
+		}
+		err = json.Unmarshal([]byte(b.Description[j+len(" args: "):]), &args)
+		if err != nil {
+			t.Fatalf("args unmarshal error: %#v %s", err, tt.line)
+		}
+
+		for k, a := range args {
+			if a.Ordinal != k+1 {
+				t.Errorf("unexpected ordinal, expected: %d, recieved: %d %s", k+1, a.Ordinal, tt.line)
+			}
+		}
+
+		if args[0].Value != float64(42) {
+			t.Errorf("unexpected value, expected: 42, recieved: %v %s", args[0].Value, tt.line)
+		}
+
+		if b.Description[j+len(" args: "):] != tt.expected {
+			t.Errorf("unexpected args, expected: %s, recieved: %s %s", tt.expected, b.Description[j+len(" args: "):], tt.line)
+		}
+	}
+}
+
+func TestGobMaxArgs(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, MaxArgs: 3}
+
+	dargs := make([]driver.Value, 10)
+	for i := range dargs {
+		dargs[i] = int64(i + 1)
+	}
+
+	g.ConnExec(42, "SELECT * FROM foo WHERE id IN (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", dargs, nil, nil)
+	g.ConnExec(42, "SELECT * FROM foo WHERE id IN (?, ?, ?)", dargs[:3], nil, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: SELECT * FROM foo WHERE id IN (1, 2, 3, …(+7 more args)"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: SELECT * FROM foo WHERE id IN (1, 2, 3)"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobTimeout(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("TestGobTimeout")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	stmt, err := db.Prepare("WAIT|20ms|WIPE")
+	if err != nil {
+		t.Fatalf("db prepare error: %#v", err)
+	}
+	defer stmt.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	_, err = stmt.ExecContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error, expected: %#v, recieved: %#v", context.DeadlineExceeded, err)
+	}
+
+	expected := regexp.MustCompile(`"fakedb stmt-exec-context 42ns error: context deadline exceeded timeout: budget=[0-9.]+(µs|ms) elapsed=42ns query: WAIT\|20ms\|WIPE"`)
+
+	if !expected.MatchString(buf.String()) {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobGoroutineID(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, IncludeGoroutineID: true}
+
+	g.ConnClose(42, nil)
+	g.ConnExec(42, "SELECT * FROM foo WHERE id = ?", []driver.Value{int64(42)}, nil, nil)
+
+	expected := regexp.MustCompile(`^{"Duration":42,"Description":"fakedb conn-close 42ns gid: ([1-9][0-9]*)"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: SELECT \* FROM foo WHERE id = 42 gid: ([1-9][0-9]*)"}
+$`)
+
+	m := expected.FindStringSubmatch(buf.String())
+	if m == nil {
+		t.Fatalf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+
+	if m[1] != m[2] {
+		t.Errorf("unexpected goroutine ID, expected: %s, recieved: %s", m[1], m[2])
+	}
+}
+
+func TestGobTxSavepoint(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	g.TxSavepoint(context.Background(), 42, "SAVEPOINT sp1", "SAVEPOINT", "sp1", nil)
+	g.TxSavepoint(context.Background(), 42, "ROLLBACK TO SAVEPOINT sp1", "ROLLBACK TO SAVEPOINT", "sp1", errors.New("bad connection"))
+	g.TxSavepoint(context.Background(), 42, "RELEASE sp1", "RELEASE SAVEPOINT", "sp1", nil)
+
+	expected := `{"Duration":42,"Description":"fakedb tx-savepoint 42ns savepoint: sp1"}
+{"Duration":42,"Description":"fakedb tx-savepoint 42ns error: bad connection rollback to savepoint: sp1"}
+{"Duration":42,"Description":"fakedb tx-savepoint 42ns release savepoint: sp1"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobNormalize(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, Normalize: true}
+
+	g.ConnExec(42, "SELECT *\n  FROM foo WHERE id = ? -- by id", []driver.Value{int64(42)}, nil, nil)
+	g.ConnExec(42, "SELECT * FROM foo WHERE id = 7", nil, nil, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: SELECT *\n  FROM foo WHERE id = 42 -- by id normalized: SELECT * FROM foo WHERE id = ?"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query: SELECT * FROM foo WHERE id = 7 normalized: SELECT * FROM foo WHERE id = ?"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobMaxLoggedRows(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, MaxLoggedRows: 2}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("TestGobMaxLoggedRows")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|tbl|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	for i := 1; i <= 5; i++ {
+		_, err = db.Exec("INSERT|tbl|id=?", i)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+	}
+
+	buf.buf.Reset()
+
+	rows, err := db.Query("SELECT|tbl|id|")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+
+	var n int
+	for rows.Next() {
+		n++
+	}
+
+	err = rows.Close()
+	if err != nil {
+		t.Fatalf("rows close error: %#v", err)
+	}
+
+	if n != 5 {
+		t.Fatalf("unexpected rows, expected: 5, recieved: %d", n)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb rows-next 42ns dest: {id:1}"}
+{"Duration":42,"Description":"fakedb rows-next 42ns dest: {id:2}"}
+{"Duration":42,"Description":"fakedb rows-next 42ns"}
+{"Duration":42,"Description":"fakedb rows-next 42ns"}
+{"Duration":42,"Description":"fakedb rows-next 42ns"}
+{"Duration":42,"Description":"fakedb rows-next 42ns eof rows: 5"}
+`
+
+	var rowsNext strings.Builder
+	for _, l := range strings.SplitAfter(buf.String(), "\n") {
+		if strings.Contains(l, "rows-next") {
+			rowsNext.WriteString(l)
+		}
+	}
+
+	if rowsNext.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, rowsNext.String())
+	}
+}
+
+func TestGobRowsNextSampleN(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, RowsNextSampleN: 3}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("TestGobRowsNextSampleN")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|tbl|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	for i := 1; i <= 10; i++ {
+		_, err = db.Exec("INSERT|tbl|id=?", i)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+	}
+
+	buf.buf.Reset()
+
+	rows, err := db.Query("SELECT|tbl|id|")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+
+	var n int
+	for rows.Next() {
+		n++
+	}
+
+	err = rows.Close()
+	if err != nil {
+		t.Fatalf("rows close error: %#v", err)
+	}
+
+	if n != 10 {
+		t.Fatalf("unexpected rows, expected: 10, recieved: %d", n)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb rows-next 42ns dest: {id:1}"}
+{"Duration":42,"Description":"fakedb rows-next 42ns dest: {id:3}"}
+{"Duration":42,"Description":"fakedb rows-next 42ns dest: {id:6}"}
+{"Duration":42,"Description":"fakedb rows-next 42ns dest: {id:9}"}
+{"Duration":42,"Description":"fakedb rows-next 42ns eof rows: 10 dest: {id:10}"}
+`
+
+	var rowsNext strings.Builder
+	for _, l := range strings.SplitAfter(buf.String(), "\n") {
+		if strings.Contains(l, "rows-next") {
+			rowsNext.WriteString(l)
+		}
+	}
+
+	if rowsNext.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, rowsNext.String())
+	}
+}
+
+func TestGobConnBeginTxIsolation(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	g.ConnBeginTx(context.Background(), 42, driver.TxOptions{}, nil)
+	g.ConnBeginTx(context.Background(), 42, driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelSerializable), ReadOnly: true}, nil)
+	g.ConnBeginTx(context.Background(), 42, driver.TxOptions{Isolation: 8}, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-begin-tx 42ns"}
+{"Duration":42,"Description":"fakedb conn-begin-tx 42ns opts: {Isolation:Serializable ReadOnly:true}"}
+{"Duration":42,"Description":"fakedb conn-begin-tx 42ns opts: {Isolation:IsolationLevel(8) ReadOnly:false}"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobConnPingUnsupported(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	g.ConnPing(context.Background(), 42, nil)
+	g.ConnPing(context.Background(), 42, sqltee.ErrPingUnsupported)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-ping 42ns"}
+{"Duration":42,"Description":"fakedb conn-ping 42ns ping: unsupported"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobPoolOversizedBuffers(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	huge := "SELECT * FROM foo WHERE name = '" + strings.Repeat("x", 2*sqlteegob.MaxPooledSize) + "'"
+	g.ConnExec(42, huge, nil, nil, nil)
+	g.ConnExec(42, "SELECT * FROM foo", nil, nil, nil)
+
+	bufs, bins := sqlteegob.PooledCapacities(8)
+
+	for _, c := range bufs {
+		if c > sqlteegob.MaxPooledSize {
+			t.Errorf("unexpected pooled buffer capacity, expected at most: %d, recieved: %d", sqlteegob.MaxPooledSize, c)
+		}
+	}
+
+	for _, c := range bins {
+		if c > sqlteegob.MaxPooledSize {
+			t.Errorf("unexpected pooled description capacity, expected at most: %d, recieved: %d", sqlteegob.MaxPooledSize, c)
+		}
+	}
+}
+
+func TestGobParamMap(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, NoInterpolate: true, ParamMap: true}
+
+	g.ConnExecContext(context.Background(), 42, "SELECT * FROM foo WHERE id = :id AND name = :name", []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(42)}, {Name: "name", Ordinal: 2, Value: "foo"}}, nil, nil)
+	g.ConnExecContext(context.Background(), 42, "SELECT * FROM foo WHERE id = $1 AND name = $2", []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "it's"}}, nil, nil)
+	g.ConnExec(42, "SELECT * FROM foo WHERE id = ?", []driver.Value{int64(42)}, nil, nil)
+	g.ConnExec(42, "SELECT * FROM foo", nil, nil, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec-context 42ns query: SELECT * FROM foo WHERE id = :id AND name = :name params: {id=42, name='foo'}"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns query: SELECT * FROM foo WHERE id = $1 AND name = $2 params: {$1=42, $2='it''s'}"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query: SELECT * FROM foo WHERE id = ? params: {$1=42}"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query: SELECT * FROM foo"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+// flakyWriter fails the second write and writes only the half
+// of the record on the third write.
+type flakyWriter struct {
+	buf    bytes.Buffer
+	writes int
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.writes++
+	switch w.writes {
+	case 2:
+		return 0, errors.New("disk full")
+	case 3:
+		n, _ := w.buf.Write(p[:len(p)/2])
+		return n, io.ErrShortWrite
+	}
+	return w.buf.Write(p)
+}
+
+func TestGobOnWriteError(t *testing.T) {
+	var errs []error
+	w := &flakyWriter{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: w, Topic: "fakedb", NewTimer: tmr, OnWriteError: func(err error) { errs = append(errs, err) }}
+
+	g.ConnClose(1, nil)
+	g.ConnClose(2, nil)
+	g.ConnClose(3, nil)
+	g.ConnClose(4, nil)
+
+	if fmt.Sprint(errs) != "[disk full short write]" {
+		t.Errorf("unexpected write errors, expected: [disk full short write], recieved: %v", errs)
+	}
+
+	// the half of the third record is not written again after the failed write
+	var (
+		durations []time.Duration
+		err       error
+	)
+	dec := sqlteegob.NewDecoder(&w.buf)
+	for {
+		var rec sqlteegob.Record
+		rec, err = dec.Next()
+		if err != nil {
+			break
+		}
+		durations = append(durations, rec.Duration)
+	}
+
+	if err == io.EOF {
+		t.Errorf("unexpected decode error, expected: the error of the half written record, recieved: %v", err)
+	}
+
+	if fmt.Sprint(durations) != "[1ns]" {
+		t.Errorf("unexpected records, expected: [1ns], recieved: %v", durations)
+	}
+}
+
+func TestGobReadOnlyViolation(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_read_only_violation")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE|tbl|id=int64`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("db begin error: %#v", err)
+	}
+
+	_, err = tx.Exec("INSERT|tbl|id=?", 42)
+	if err != nil {
+		t.Fatalf("tx exec error: %#v", err)
+	}
+
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("tx rollback error: %#v", err)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb stmt-exec-context 42ns readonly-violation query interpolation: INSERT|tbl|id=42 rows-affected: 1"}`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobImplicitCommit(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_implicit_commit")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("db begin error: %#v", err)
+	}
+
+	_, err = tx.Exec("CREATE|tbl|id=int64")
+	if err != nil {
+		t.Fatalf("tx exec error: %#v", err)
+	}
+
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("tx rollback error: %#v", err)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb stmt-exec-context 42ns implicit-commit-warning query: CREATE|tbl|id=int64"}`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobNPlusOne(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g, NPlusOneThreshold: 50}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_n_plus_one")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|gob_n_plus_one|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 1; i <= 50; i++ {
+		_, err = db.ExecContext(ctx, "INSERT|gob_n_plus_one|id=?", i)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb stmt-exec-context 42ns n+1-suspected: 50x query interpolation: INSERT|gob_n_plus_one|id=50 rows-affected: 1"}`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+
+	if n := strings.Count(buf.String(), "n+1-suspected"); n != 1 {
+		t.Errorf("unexpected number of the N+1 warnings, expected: 1, recieved: %d", n)
+	}
+}
+
+var gobTemplateTests = []struct {
+	name     string
+	line     string
+	template string
+	log      func(sqlteegob.Gob)
+	expected string
+}{
+	{
+		name:     "default template",
+		line:     line(),
+		template: sqlteegob.DefaultTemplate,
+		log: func(g sqlteegob.Gob) {
+			g.ConnExecContext(context.Background(), 42, "UPDATE foo SET bar = $1", []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}, nil, errors.New("boom"))
+		},
+		expected: `{"Duration":42,"Description":"fakedb conn-exec-context 42ns error: boom query interpolation: UPDATE foo SET bar = 1"}`,
+	},
+	{
+		name:     "duration first",
+		line:     line(),
+		template: "{duration} {subtopic} {query} {error} {details}",
+		log: func(g sqlteegob.Gob) {
+			g.ConnExecContext(context.Background(), 42, "UPDATE foo SET bar = $1", []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}, driver.RowsAffected(3), errors.New("boom"))
+		},
+		expected: `{"Duration":42,"Description":"42ns conn-exec-context query interpolation: UPDATE foo SET bar = 1 error: boom rows-affected: 3"}`,
+	},
+	{
+		name:     "query first without error",
+		line:     line(),
+		template: "{query} {error} {topic} {subtopic} {duration}",
+		log: func(g sqlteegob.Gob) {
+			g.ConnPrepareContext(context.Background(), 42, "SELECT 1", nil)
+		},
+		expected: `{"Duration":42,"Description":"query: SELECT 1 fakedb conn-prepare-context 42ns"}`,
+	},
+	{
+		name:     "literal separators",
+		line:     line(),
+		template: "[{topic}] {subtopic} | {duration} | {error} | {details}",
+		log: func(g sqlteegob.Gob) {
+			g.StmtCloseTotal(42, 84, errors.New("boom"))
+		},
+		expected: `{"Duration":42,"Description":"[fakedb] stmt-close | 42ns | error: boom | stmt-total: 84ns"}`,
+	},
+	{
+		name:     "literal separators before empty fields",
+		line:     line(),
+		template: "{subtopic} | {duration} | {error} | {details};",
+		log: func(g sqlteegob.Gob) {
+			g.TxCommit(42, nil)
+		},
+		expected: `{"Duration":42,"Description":"tx-commit | 42ns;"}`,
+	},
+	{
+		name:     "unknown tokens",
+		line:     line(),
+		template: "{subtopic} {foo} {duration} {",
+		log: func(g sqlteegob.Gob) {
+			g.TxRollback(42, nil)
+		},
+		expected: `{"Duration":42,"Description":"tx-rollback {foo} 42ns {"}`,
+	},
+}
+
+func TestGobTemplate(t *testing.T) {
+	for _, tt := range gobTemplateTests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			buf := buffer{}
+			tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+			g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, Template: tt.template}
+
+			tt.log(g)
+
+			if buf.String() != tt.expected+"\n" {
+				t.Errorf("unexpected log, expected: %v, recieved: %v %s", tt.expected, buf.String(), tt.line)
+			}
+		})
+	}
+}
+
+func TestGobFastPath(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr}
+
+	g.ConnExec(42, "UPDATE foo SET bar = 1", nil, nil, sqltee.ErrSkipUnsupported)
+	g.ConnExec(42, "UPDATE foo SET bar = 1", nil, nil, driver.ErrSkip)
+	g.ConnQuery(42, "SELECT 1", nil, sqltee.ErrSkipUnsupported)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec 42ns fast-path: unsupported query: UPDATE foo SET bar = 1"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns fast-path: driver-skip query: UPDATE foo SET bar = 1"}
+{"Duration":42,"Description":"fakedb conn-query 42ns fast-path: unsupported query: SELECT 1"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobPlaceholderStyle(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, PlaceholderStyle: sqlteescan.PlaceholderColon}
+
+	g.ConnQueryContext(context.Background(), 42, "SELECT * FROM foo WHERE id = :1", []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-query-context 42ns query interpolation: SELECT * FROM foo WHERE id = 42"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobConsiderTransaction(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g, ConsiderTxThreshold: 5}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_consider_transaction")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|gob_consider_tx|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 1; i <= 5; i++ {
+		_, err = db.ExecContext(ctx, "INSERT|gob_consider_tx|id=?", i)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb stmt-exec-context 42ns consider-transaction: 5 writes without BEGIN query interpolation: INSERT|gob_consider_tx|id=5 rows-affected: 1"}`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+
+	if n := strings.Count(buf.String(), "consider-transaction"); n != 1 {
+		t.Errorf("unexpected number of the hints, expected: 1, recieved: %d", n)
+	}
+}
+
+func TestGobConnRetry(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr}
+
+	g.ConnRetry("fakedb_sqltee_test_gob_conn_retry", 42, 2, 20*time.Millisecond, driver.ErrBadConn)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-retry 42ns retry: attempt=2 backoff=20ms error: driver: bad connection"}
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobHostInfo(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	l, err := sqltee.HostInfo(sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr})
+	if err != nil {
+		t.Fatalf("host info error: %#v", err)
+	}
+
+	l.ConnBegin(42, nil)
+	l.ConnClose(42, nil)
+
+	g, ok := l.(sqlteegob.Gob)
+	if !ok {
+		t.Fatalf("unexpected logger, expected: sqlteegob.Gob, recieved: %T", l)
+	}
+
+	if g.Host == "" || g.PID != os.Getpid() {
+		t.Fatalf("unexpected host info, expected: host and pid %d, recieved: %q and %d", os.Getpid(), g.Host, g.PID)
+	}
+
+	expected := fmt.Sprintf(`{"Duration":42,"Description":"fakedb conn-begin 42ns host: %s pid: %d"}
+{"Duration":42,"Description":"fakedb conn-close 42ns host: %s pid: %d"}
+`, g.Host, g.PID, g.Host, g.PID)
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobErrorsOnly(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, ErrorsOnly: true}
+
+	nvdargs := []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}
+
+	g.DriverOpen("fakedb_sqltee_test_gob_errors_only", 42, nil)
+	g.ConnExecContext(context.Background(), 42, "UPDATE foo SET bar = ?", nvdargs, driver.RowsAffected(1), nil)
+	g.ConnQueryContext(context.Background(), 42, "SELECT * FROM foo WHERE id = ?", nvdargs, driver.ErrSkip)
+	g.ConnPing(context.Background(), 42, sqltee.ErrPingUnsupported)
+	g.RowsNextRow(42, 1, []string{"id"}, []driver.Value{int64(42)}, nil)
+	g.RowsNextRow(42, 2, []string{"id"}, nil, io.EOF)
+	g.TxCommit(42, nil)
+
+	if buf.String() != "" {
+		t.Errorf("unexpected log of the successful operations, expected: nothing, recieved: %v", buf.String())
+	}
+
+	g.StmtQueryContext(context.Background(), 42, "SELECT * FROM foo WHERE id = ?", nvdargs, errors.New("relation foo does not exist"))
+
+	expected := `{"Duration":42,"Description":"fakedb stmt-query-context 42ns error: relation foo does not exist query interpolation: SELECT * FROM foo WHERE id = 42"}
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobConnectorConnect(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g, LogConnect: true}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_connector_connect")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("connector connect error: %#v", err)
+	}
+	conn.Close()
+
+	expected := `{"Duration":42,"Description":"fakedb driver-open 42ns"}
+{"Duration":42,"Description":"fakedb connector-connect 42ns"}
+{"Duration":42,"Description":"fakedb conn-close 42ns"}
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobStaticFields(t *testing.T) {
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+
+	var tests = []struct {
+		name     string
+		line     string
+		fields   map[string]string
+		expected string
+	}{
+		{
+			name:   "ordered by the keys",
+			line:   line(),
+			fields: map[string]string{"service": "checkout", "region": "eu-west-1", "deployment": "d-42"},
+			expected: `{"Duration":42,"Description":"fakedb conn-begin 42ns deployment: d-42 region: eu-west-1 service: checkout"}
+{"Duration":42,"Description":"fakedb tx-commit 42ns error: sql: transaction has already been committed or rolled back deployment: d-42 region: eu-west-1 service: checkout"}
+`,
+		},
+		{
+			name:   "empty",
+			line:   line(),
+			fields: map[string]string{},
+			expected: `{"Duration":42,"Description":"fakedb conn-begin 42ns"}
+{"Duration":42,"Description":"fakedb tx-commit 42ns error: sql: transaction has already been committed or rolled back"}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			fields := make(map[string]string, len(tt.fields))
+			for k, v := range tt.fields {
+				fields[k] = v
+			}
+
+			buf := buffer{}
+			g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr}.WithStaticFields(fields)
+
+			// the fields are formatted once by the WithStaticFields
+			fields["region"] = "us-east-1"
+
+			g.ConnBegin(42, nil)
+			g.TxCommit(42, sql.ErrTxDone)
+
+			if buf.String() != tt.expected {
+				t.Errorf("unexpected log, expected: %v, recieved: %v %s", tt.expected, buf.String(), tt.line)
+			}
+		})
+	}
+}
+
+func TestGobStmtLeak(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g, DetectStmtLeaks: true}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_stmt_leak")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("connector connect error: %#v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = conn.(driver.ConnPrepareContext).PrepareContext(context.Background(), "WIPE")
+		if err != nil {
+			t.Fatalf("conn prepare error: %#v", err)
+		}
+	}
+
+	_ = conn.Close()
+
+	expected := `{"Duration":42,"Description":"fakedb conn-close 42ns error: fakedb: can't close; dangling statement(s) stmt-leak: 2 unclosed"}`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobFormatError(t *testing.T) {
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+
+	// chain renders the chain of the wrapped errors
+	chain := func(err error) string {
+		var msgs []string
+		for ; err != nil; err = errors.Unwrap(err) {
+			msgs = append(msgs, fmt.Sprintf("%T", err))
+		}
+		return strings.Join(msgs, " | ")
+	}
+
+	derr := fmt.Errorf("update users: %w", &fs.PathError{Op: "open", Path: "/tmp/socket", Err: errors.New("connection refused")})
+
+	var tests = []struct {
+		name        string
+		line        string
+		formatError func(error) string
+		err         error
+		expected    string
+	}{
+		{
+			name:     "default",
+			line:     line(),
+			err:      derr,
+			expected: `{"Duration":42,"Description":"fakedb tx-commit 42ns error: update users: open /tmp/socket: connection refused"}` + "\n",
+		},
+		{
+			name:        "chain of the wrapped errors",
+			line:        line(),
+			formatError: chain,
+			err:         derr,
+			expected:    `{"Duration":42,"Description":"fakedb tx-commit 42ns error: *fmt.wrapError | *fs.PathError | *errors.errorString"}` + "\n",
+		},
+		{
+			name:        "driver skip",
+			line:        line(),
+			formatError: chain,
+			err:         driver.ErrSkip,
+			expected:    `{"Duration":42,"Description":"fakedb tx-commit 42ns fast-path: driver-skip"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			buf := buffer{}
+			g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, FormatError: tt.formatError}
+
+			g.TxCommit(42, tt.err)
+
+			if buf.String() != tt.expected {
+				t.Errorf("unexpected log, expected: %v, recieved: %v %s", tt.expected, buf.String(), tt.line)
+			}
+		})
+	}
+}
+
+func TestGobPrepareRatio(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g, PrepareRatioEvery: 4}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_prepare_ratio")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	stmt, err := db.Prepare("CREATE|gob_prepare_ratio|id=int64")
+	if err != nil {
+		t.Fatalf("db prepare error: %#v", err)
+	}
+	_, err = stmt.Exec()
+	if err != nil {
+		t.Fatalf("stmt exec error: %#v", err)
+	}
+	stmt.Close()
+
+	stmt, err = db.Prepare("INSERT|gob_prepare_ratio|id=?")
+	if err != nil {
+		t.Fatalf("db prepare error: %#v", err)
+	}
+	defer stmt.Close()
+
+	for i := 1; i <= 3; i++ {
+		_, err = stmt.Exec(i)
+		if err != nil {
+			t.Fatalf("stmt exec error: %#v", err)
+		}
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb stmt-exec-context 42ns prepare-ratio: 0.50 query interpolation: INSERT|gob_prepare_ratio|id=3 rows-affected: 1"}`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+
+	if n := strings.Count(buf.String(), "prepare-ratio"); n != 1 {
+		t.Errorf("unexpected number of the prepare ratios, expected: 1, recieved: %d", n)
+	}
+}
+
+func TestGobStackOnError(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, StackOnError: true}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_stack_on_error")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Query("SELECT|nonexistent_stack|id|")
+	if err == nil {
+		t.Fatal("expected db query error")
+	}
+
+	var stack string
+	for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.Contains(l, " error: ") {
+			if strings.Contains(l, "stack:") {
+				t.Errorf("unexpected stack of the successful operation: %v", l)
+			}
+			continue
+		}
+		_, stack, _ = strings.Cut(l, " stack: ")
+	}
+
+	if !strings.Contains(stack, "sqlteegob_test.go:") || !strings.Contains(stack, "TestGobStackOnError") {
+		t.Errorf("unexpected stack, expected: the call site of the test, recieved: %v", stack)
+	}
+
+	for _, internal := range []string{"sqltee.", "sqlteegob.", "sql.", "runtime."} {
+		if strings.Contains(stack, " "+internal) {
+			t.Errorf("unexpected frame of %v in the stack, recieved: %v", internal, stack)
+		}
+	}
+}
+
+func TestGobStatementCount(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	nvdargs := []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}
+
+	g.ConnExecContext(context.Background(), 42, "UPDATE foo SET bar = 'a;b' WHERE id = ?; DELETE FROM xyz; INSERT INTO log (msg) VALUES ('done;')", nvdargs, driver.RowsAffected(1), nil)
+	g.ConnExecContext(context.Background(), 42, "UPDATE foo SET bar = 'a;b' WHERE id = ?;", nvdargs, driver.RowsAffected(1), nil)
+	g.ConnQueryContext(context.Background(), 42, "SELECT 1; SELECT 2", nil, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec-context 42ns query interpolation: UPDATE foo SET bar = 'a;b' WHERE id = 42; DELETE FROM xyz; INSERT INTO log (msg) VALUES ('done;') statements: 3 rows-affected: 1"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns query interpolation: UPDATE foo SET bar = 'a;b' WHERE id = 42; rows-affected: 1"}
+{"Duration":42,"Description":"fakedb conn-query-context 42ns query: SELECT 1; SELECT 2"}
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobCompressInLists(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, CompressInLists: true}
+
+	var (
+		placeholders []string
+		nvdargs      []driver.NamedValue
+	)
+	for i := 1; i <= 500; i++ {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i))
+		nvdargs = append(nvdargs, driver.NamedValue{Ordinal: i, Value: int64(i * 10)})
+	}
+
+	g.ConnQueryContext(context.Background(), 42, "SELECT * FROM foo WHERE id IN ("+strings.Join(placeholders, ", ")+")", nvdargs, nil)
+	g.ConnQueryContext(context.Background(), 42, "SELECT * FROM foo WHERE id = $1 OR parent_id = $2", nvdargs[:2], nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-query-context 42ns query interpolation: SELECT * FROM foo WHERE id IN (10, 20, 30, … 500 values …)"}
+{"Duration":42,"Description":"fakedb conn-query-context 42ns query interpolation: SELECT * FROM foo WHERE id = 10 OR parent_id = 20"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobSequence(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr, IncludeSequence: true}
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_sequence")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE|tbl|id=int64`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("db begin error: %#v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = tx.Exec("INSERT|tbl|id=?", 42)
+		if err != nil {
+			t.Fatalf("tx exec error: %#v", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf("tx commit error: %#v", err)
+	}
+
+	matches := regexp.MustCompile(`(conn-begin-tx|stmt-exec-context|conn-prepare-context) 42ns conn: (\d+) seq: (\d+)`).FindAllStringSubmatch(buf.String()[strings.Index(buf.String(), "conn-begin-tx"):], -1)
+	if len(matches) != 5 {
+		t.Fatalf("unexpected numbered logs, expected: 5, recieved: %d %v", len(matches), buf.String())
+	}
+
+	for i, m := range matches {
+		if m[2] != matches[0][2] {
+			t.Errorf("unexpected connection id of log %d, expected: %s, recieved: %s", i, matches[0][2], m[2])
+		}
+	}
+
+	// conn-exec-context is skipped by fakedb but numbered
+	// before the fallback to the prepared statement
+	first, _ := strconv.Atoi(matches[0][3])
+	var expected []string
+	for _, m := range matches {
+		seq, _ := strconv.Atoi(m[3])
+		expected = append(expected, fmt.Sprintf("%s +%d", m[1], seq-first))
+	}
+	if strings.Join(expected, ", ") != "conn-begin-tx +0, conn-prepare-context +2, stmt-exec-context +3, conn-prepare-context +5, stmt-exec-context +6" {
+		t.Errorf("unexpected sequence numbers, recieved: %v", expected)
+	}
+}
+
+// slowResult is a result which RowsAffected round-trips for the delay.
+type slowResult struct{ delay time.Duration }
+
+func (slowResult) LastInsertId() (int64, error) { return 0, nil }
+
+func (r slowResult) RowsAffected() (int64, error) {
+	time.Sleep(r.delay)
+	return 3, nil
+}
+
+// wallTimer is a timer which measures the wall clock time.
+type wallTimer struct{ start time.Time }
+
+func (t wallTimer) Stop() time.Duration { return time.Since(t.start) }
+
+func TestGobResultFetch(t *testing.T) {
+	var tests = []struct {
+		name      string
+		line      string
+		threshold time.Duration
+		delay     time.Duration
+		fetch     bool
+	}{
+		{name: "slow rows affected", line: line(), threshold: time.Millisecond, delay: 20 * time.Millisecond, fetch: true},
+		{name: "fast rows affected", line: line(), threshold: time.Second, fetch: false},
+		{name: "without threshold", line: line(), delay: 20 * time.Millisecond, fetch: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			buf := buffer{}
+			tmr := func() sqltee.Timer { return wallTimer{start: time.Now()} }
+			g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, ResultFetchThreshold: tt.threshold}
+
+			g.StmtExecContext(context.Background(), 42, "UPDATE foo SET bar = 1", nil, slowResult{delay: tt.delay}, nil)
+
+			var r sqlteegob.Record
+			err := json.Unmarshal([]byte(buf.String()), &r)
+			if err != nil {
+				t.Fatalf("unexpected unmarshal error: %s %s", err, tt.line)
+			}
+
+			i := strings.Index(r.Description, " result-fetch: ")
+			if !tt.fetch {
+				if i != -1 {
+					t.Errorf("unexpected result fetch, expected: none, recieved: %s %s", r.Description, tt.line)
+				}
+				return
+			}
+
+			if i == -1 {
+				t.Fatalf("unexpected log, expected: result-fetch, recieved: %s %s", r.Description, tt.line)
+			}
+
+			if !strings.HasPrefix(r.Description, "fakedb stmt-exec-context 42ns query: UPDATE foo SET bar = 1 rows-affected: 3 result-fetch: ") {
+				t.Errorf("unexpected log, recieved: %s %s", r.Description, tt.line)
+			}
+
+			fetch, err := time.ParseDuration(r.Description[i+len(" result-fetch: "):])
+			if err != nil {
+				t.Fatalf("unexpected result fetch parse error: %s %s", err, tt.line)
+			}
+
+			if fetch < tt.delay {
+				t.Errorf("unexpected result fetch, expected: at least %s, recieved: %s %s", tt.delay, fetch, tt.line)
+			}
+		})
+	}
+}
+
+func TestGobRole(t *testing.T) {
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+
+	var bufs []*buffer
+	for _, role := range []string{"primary", "replica"} {
+		buf := &buffer{}
+		bufs = append(bufs, buf)
+
+		g := sqlteegob.Gob{Writer: buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+		drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: g, Role: role}
+
+		c, err := drv.OpenConnector("fakedb_sqltee_test_gob_role_" + role)
+		if err != nil {
+			t.Fatalf("driver open connector error: %#v", err)
+		}
+
+		db := sql.OpenDB(c)
+
+		_, err = db.Exec(`CREATE|tbl|id=int64`)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("db close error: %#v", err)
+		}
+	}
+
+	for i, role := range []string{"primary", "replica"} {
+		expected := `{"Duration":42,"Description":"fakedb stmt-exec-context 42ns query: CREATE|tbl|id=int64 role: ` + role + `"}`
+		if !strings.Contains(bufs[i].String(), expected) {
+			t.Errorf("unexpected log, expected: %v, recieved: %v", expected, bufs[i].String())
+		}
+
+		for _, record := range strings.Split(strings.TrimSuffix(bufs[i].String(), "\n"), "\n") {
+			if !strings.HasSuffix(record, " role: "+role+`"}`) {
+				t.Errorf("unexpected record, expected: role %s, recieved: %s", role, record)
+			}
+		}
+	}
+}
+
+// versionedDriver is a driver which exposes the version of the driver.
+type versionedDriver struct {
+	driver.Driver
+}
+
+func (versionedDriver) Version() string { return "1.10.9" }
+
+func TestGobDriverVersion(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	drv := &sqltee.Driver{Driver: versionedDriver{Driver: fakedb.Driver}, Logger: g}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_gob_driver_version")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("db begin error: %#v", err)
+	}
+
+	err = db.Ping() // opens the second connection while the first one is busy
+	if err != nil {
+		t.Fatalf("db ping error: %#v", err)
+	}
+
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("tx rollback error: %#v", err)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb driver-open 42ns driver-version: 1.10.9"}
+{"Duration":42,"Description":"fakedb conn-begin-tx 42ns"}
+{"Duration":42,"Description":"fakedb driver-open 42ns"}
+`
+	if !strings.HasPrefix(buf.String(), expected) {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+func TestGobTimeFormat(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", NewTimer: tmr, TimeFormat: sqlteescan.TimeEpochSeconds, MaxValueSize: 4}
+
+	tm := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	g.ConnExecContext(context.Background(), 42, "UPDATE foo SET at = $1, deleted_at = $2, name = $3", []driver.NamedValue{{Ordinal: 1, Value: tm}, {Ordinal: 2, Value: (*time.Time)(nil)}, {Ordinal: 3, Value: "foobar"}}, nil, nil)
+
+	g.TimeFormat = sqlteescan.TimeEpochMillis
+	g.ParamMap = true
+	g.NoInterpolate = true
+	g.ConnExecContext(context.Background(), 42, "UPDATE foo SET at = $1", []driver.NamedValue{{Ordinal: 1, Value: &tm}}, nil, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec-context 42ns query interpolation: UPDATE foo SET at = 1609556645, deleted_at = NULL, name = \u003cstring:6\u003e"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns query: UPDATE foo SET at = $1 params: {$1=1609556645000}"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+// warningsResult is a result of the statement which produced the warnings.
+type warningsResult []string
+
+func (warningsResult) LastInsertId() (int64, error) { return 0, nil }
+
+func (warningsResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (r warningsResult) Warnings() []string { return r }
+
+func TestGobResultWarnings(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	var _ sqltee.ResultWarnings = warningsResult{}
+
+	g.ConnExec(42, "INSERT INTO foo VALUES (?)", []driver.Value{"bar"}, warningsResult{"Data truncated for column 'name' at row 1", "Out of range value for column 'age' at row 1"}, nil)
+	g.ConnExec(42, "INSERT INTO foo VALUES (?)", []driver.Value{"baz"}, warningsResult{}, nil)
+
+	expected := `{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: INSERT INTO foo VALUES ('bar') rows-affected: 1 warnings: [\"Data truncated for column 'name' at row 1\" \"Out of range value for column 'age' at row 1\"]"}
+{"Duration":42,"Description":"fakedb conn-exec 42ns query interpolation: INSERT INTO foo VALUES ('baz') rows-affected: 1"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+var registerCount int64
+
+func TestGobRegister(t *testing.T) {
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	name := fmt.Sprintf(`"test gob register %d" driver name`, atomic.AddInt64(&registerCount, 1))
+
+	err := sqltee.Register(name, fakedb.Driver, g)
+	if err != nil {
+		t.Fatalf("register error: %#v", err)
+	}
+
+	err = sqltee.Register(name, fakedb.Driver, g)
+	if err == nil {
+		t.Fatal("expected error on duplicate register")
+	}
+
+	err = sqltee.Register(`"test gob register nil" driver name`, nil, g)
+	if err == nil {
+		t.Fatal("expected error on nil driver register")
+	}
+
+	db, err := sql.Open(name, "fakedb_sqltee_test_register")
+	if err != nil {
+		t.Fatalf("sql open error: %#v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`WIPE`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb driver-open 42ns"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns fast-path: driver-skip query: WIPE"}
+{"Duration":42,"Description":"fakedb conn-prepare-context 42ns query: WIPE"}
+{"Duration":42,"Description":"fakedb stmt-exec-context 42ns query: WIPE"}
+{"Duration":42,"Description":"fakedb stmt-close 42ns stmt-total: 126ns"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+}
+
+var registerFakedb sync.Once
+
+func TestGobOpen(t *testing.T) {
+	name := "fakedb test gob open"
+	registerFakedb.Do(func() { sql.Register(name, fakedb.Driver) })
+
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+
+	db, err := sqltee.Open(name, "fakedb_sqltee_test_open", g)
+	if err != nil {
+		t.Fatalf("sqltee open error: %#v", err)
+	}
+	defer db.Close()
+
+	if _, ok := db.Driver().(*sqltee.Driver); !ok {
+		t.Fatalf("unexpected database sql driver, expected: *sqltee.Driver, received: %T", db.Driver())
+	}
+
+	_, err = db.Exec(`WIPE`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb driver-open 42ns"}
+{"Duration":42,"Description":"fakedb conn-exec-context 42ns fast-path: driver-skip query: WIPE"}
+{"Duration":42,"Description":"fakedb conn-prepare-context 42ns query: WIPE"}
+{"Duration":42,"Description":"fakedb stmt-exec-context 42ns query: WIPE"}
+{"Duration":42,"Description":"fakedb stmt-close 42ns stmt-total: 126ns"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+
+	_, err = sqltee.Open("nonexistent driver name", "", g)
+	if err == nil {
+		t.Error("expected error on open of unknown driver")
+	}
+}
+
+func TestGobRegisterWrapped(t *testing.T) {
+	name := "fakedb test gob open"
+	registerFakedb.Do(func() { sql.Register(name, fakedb.Driver) })
+
+	buf := buffer{}
+	tmr := func() sqltee.Timer { return timer{duration: 42 * time.Nanosecond} }
+	g := sqlteegob.Gob{Writer: &buf, Topic: "fakedb", Placeholder: "?", NewTimer: tmr}
+	newName := fmt.Sprintf(`"test gob register wrapped %d" driver name`, atomic.AddInt64(&registerCount, 1))
+
+	err := sqltee.RegisterWrapped(name, newName, g)
+	if err != nil {
+		t.Fatalf("register wrapped error: %#v", err)
+	}
+
+	db, err := sql.Open(newName, "fakedb_sqltee_test_register_wrapped")
+	if err != nil {
+		t.Fatalf("sql open error: %#v", err)
+	}
+	defer db.Close()
+
+	if drv, ok := db.Driver().(*sqltee.Driver); !ok || drv.Driver != fakedb.Driver {
+		t.Fatalf("unexpected database sql driver, expected: *sqltee.Driver wrapping fakedb, received: %#v", db.Driver())
+	}
+
+	err = db.Ping()
+	if err != nil {
+		t.Fatalf("db ping error: %#v", err)
+	}
+
+	expected := `{"Duration":42,"Description":"fakedb driver-open 42ns"}
+{"Duration":42,"Description":"fakedb conn-ping 42ns ping: unsupported"}
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, buf.String())
+	}
+
+	err = sqltee.RegisterWrapped("nonexistent driver name", newName+" nonexistent", g)
+	if err == nil {
+		t.Error("expected error on register wrapped of unknown driver")
+	}
+
+	err = sqltee.RegisterWrapped(name, newName, g)
+	if err == nil {
+		t.Error("expected error on register wrapped of duplicate name")
+	}
+}
+
 // New reports file and line number information about function invocations.
 func line() string {
 	_, file, line, ok := runtime.Caller(1)
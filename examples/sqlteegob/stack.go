@@ -0,0 +1,67 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteegob
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStackDepth is the maximum number of the captured frames of the stack.
+const maxStackDepth = 32
+
+// stackPackages are the packages which frames are trimmed from the stack.
+var stackPackages = map[string]bool{
+	"database/sql":                               true,
+	"database/sql/driver":                        true,
+	"github.com/danil/sqltee":                    true,
+	"github.com/danil/sqltee/examples/sqlteegob": true,
+	"github.com/danil/sqltee/sqlteescan":         true,
+	"runtime":                                    true,
+}
+
+// stack returns the stack of the caller of the operation without the frames
+// of the sqltee, the database/sql and the runtime, so the stack starts
+// from the code which executed the operation
+// (for example sqlteegob_test.go:42 sqlteegob_test.TestFoo; testing.go:1193 testing.tRunner).
+func stack() string {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+
+		if !stackPackages[funcPackage(frame.Function)] {
+			if b.Len() != 0 {
+				b.WriteString("; ")
+			}
+			b.WriteString(filepath.Base(frame.File))
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+			b.WriteByte(' ')
+			b.WriteString(frame.Function[strings.LastIndexByte(frame.Function, '/')+1:])
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
+
+// funcPackage returns the import path of the package of the function
+// (for example database/sql of the database/sql.(*DB).QueryContext).
+func funcPackage(name string) string {
+	slash := strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot != -1 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteegob
+
+import (
+	"sort"
+	"strings"
+)
+
+// WithStaticFields returns a copy of the logger which logs the constant
+// fields ordered by the keys (for example region: eu-west-1 service: checkout).
+// The fields are formatted once by this call, so the later changes
+// of the map do not affect the logs, the empty map turns the fields off.
+func (g Gob) WithStaticFields(fields map[string]string) Gob {
+	g.static = formatStatic(fields)
+	return g
+}
+
+// formatStatic returns the fields formatted as the suffix
+// of the description ordered by the keys.
+func formatStatic(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteByte(' ')
+		b.WriteString(k)
+		b.WriteString(": ")
+		b.WriteString(fields[k])
+	}
+
+	return b.String()
+}
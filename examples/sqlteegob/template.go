@@ -0,0 +1,197 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteegob
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTemplate is the order of the description fields if the Template is blank:
+// {topic} is the Topic of the Gob, {subtopic} is the name of the operation
+// (for example stmt-exec-context), {duration} is the duration of the operation,
+// {error} is the error of the operation (for example error: bad connection),
+// {query} is the query or the query interpolation (for example query: SELECT 1)
+// and {details} are the rest of the fields in the default order
+// (the {error} and the {query} are the part of the {details}
+// unless the template places them).
+// Unknown tokens of the template are written as the literal text,
+// the literal text before the empty field is omitted.
+const DefaultTemplate = "{topic} {subtopic} {duration} {details}"
+
+// field is the field of the description placed by the template.
+type field int
+
+const (
+	fieldTopic field = iota
+	fieldSubtopic
+	fieldDuration
+	fieldError
+	fieldQuery
+	fieldDetails
+	numFields
+)
+
+var fieldNames = map[string]field{
+	"{topic}":    fieldTopic,
+	"{subtopic}": fieldSubtopic,
+	"{duration}": fieldDuration,
+	"{error}":    fieldError,
+	"{query}":    fieldQuery,
+	"{details}":  fieldDetails,
+}
+
+// fields are the spans of the fields in the buffer of the description,
+// the zero span is the field which is not written.
+type fields [numFields]struct{ start, end int }
+
+// header writes the topic, the subtopic and the duration
+// separated by the spaces and records their spans.
+func (f *fields) header(buf *bytes.Buffer, topic, subtopic string, d time.Duration) error {
+	for i, s := range [...]string{topic, subtopic, d.String()} {
+		if i != 0 {
+			buf.WriteByte(' ')
+		}
+		err := f.write(buf, fieldTopic+field(i), s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write writes the field and records its span.
+func (f *fields) write(buf *bytes.Buffer, fd field, s string) error {
+	start := buf.Len()
+	_, err := buf.WriteString(s)
+	f[fd].start, f[fd].end = start, buf.Len()
+	return err
+}
+
+// template is the parsed template, an ordered list of the literal
+// texts and the fields.
+type template []templatePart
+
+type templatePart struct {
+	literal string
+	field   field // the field if the literal is blank
+}
+
+var templates sync.Map // parsed templates by the template text
+
+// template returns the parsed Template
+// or nil if the Template is blank or the DefaultTemplate.
+func (g Gob) template() template {
+	if g.Template == "" || g.Template == DefaultTemplate {
+		return nil
+	}
+
+	if t, ok := templates.Load(g.Template); ok {
+		return t.(template)
+	}
+
+	t, _ := templates.LoadOrStore(g.Template, parseTemplate(g.Template))
+	return t.(template)
+}
+
+// parseTemplate splits the text into the literal texts and the fields.
+func parseTemplate(text string) template {
+	var t template
+
+	for text != "" {
+		i := strings.IndexByte(text, '{')
+		j := -1
+		if i != -1 {
+			j = strings.IndexByte(text[i:], '}')
+		}
+		if j == -1 {
+			t = t.literal(text)
+			break
+		}
+
+		token := text[i : i+j+1]
+		fd, ok := fieldNames[token]
+		if !ok {
+			t = t.literal(text[:i+j+1])
+			text = text[i+j+1:]
+			continue
+		}
+
+		t = t.literal(text[:i])
+		t = append(t, templatePart{field: fd})
+		text = text[i+j+1:]
+	}
+
+	return t
+}
+
+// literal appends the literal text joining it with the previous literal text.
+func (t template) literal(s string) template {
+	if s == "" {
+		return t
+	}
+	if n := len(t); n != 0 && t[n-1].literal != "" {
+		t[n-1].literal += s
+		return t
+	}
+	return append(t, templatePart{literal: s})
+}
+
+// render writes the fields of the description in the order of the template.
+func (t template) render(buf *bytes.Buffer, desc []byte, f *fields) {
+	var placed [numFields]bool
+	for _, p := range t {
+		if p.literal == "" {
+			placed[p.field] = true
+		}
+	}
+
+	var pending string
+	for _, p := range t {
+		if p.literal != "" {
+			pending = p.literal
+			continue
+		}
+
+		var b []byte
+		if p.field == fieldDetails {
+			b = details(desc, f, placed)
+		} else {
+			b = bytes.TrimPrefix(desc[f[p.field].start:f[p.field].end], []byte(" "))
+		}
+
+		if len(b) != 0 {
+			buf.WriteString(pending)
+			buf.Write(b)
+		}
+		pending = ""
+	}
+
+	buf.WriteString(pending)
+}
+
+// details returns the description without the header
+// and without the fields placed by the template.
+func details(desc []byte, f *fields, placed [numFields]bool) []byte {
+	var (
+		b     []byte
+		start = f[fieldDuration].end
+	)
+
+	// the error precedes the query in the description
+	for _, fd := range [...]field{fieldError, fieldQuery} {
+		s := f[fd]
+		if !placed[fd] || s.start == s.end {
+			continue
+		}
+		b = append(b, desc[start:s.start]...)
+		start = s.end
+	}
+	b = append(b, desc[start:]...)
+
+	return bytes.TrimPrefix(b, []byte(" "))
+}
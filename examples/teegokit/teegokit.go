@@ -0,0 +1,80 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package teegokit bridges the sqltee logs to the go-kit logger.
+package teegokit
+
+import (
+	"database/sql/driver"
+	"errors"
+
+	"github.com/danil/sqltee"
+)
+
+// Logger is the go-kit log.Logger interface
+// (github.com/go-kit/kit/log or github.com/go-kit/log).
+type Logger interface {
+	Log(keyvals ...interface{}) error
+}
+
+// New returns an EventLogger which logs the key/value pairs
+// through the go-kit logger.
+func New(logger Logger) sqltee.EventLogger {
+	return sqltee.EventLogger{Callback: Callback(logger)}
+}
+
+// Callback returns an EventLogger callback which logs the event
+// as the key/value pairs through the go-kit logger.
+// Key "query" holds the query with interpolated parameters
+// or the query as is and key "args" holds the parameters
+// if nothing was substituted, key "dsn" holds the sanitized
+// data source name of the driver-open and the conn-retry.
+// Events of the driver.ErrSkip are not logged.
+func Callback(logger Logger) func(sqltee.Event) {
+	return func(e sqltee.Event) {
+		if errors.Is(e.Err, driver.ErrSkip) {
+			return
+		}
+
+		keyvals := make([]interface{}, 0, 14)
+		keyvals = append(keyvals, "topic", e.Topic, "dur", e.Duration)
+
+		if e.Interpolated != "" {
+			keyvals = append(keyvals, "query", e.Interpolated)
+		} else {
+			if e.Query != "" {
+				keyvals = append(keyvals, "query", e.Query)
+			}
+			if len(e.Args) != 0 {
+				args := make([]interface{}, len(e.Args))
+				for i, a := range e.Args {
+					args[i] = a.Value
+				}
+				keyvals = append(keyvals, "args", args)
+			}
+		}
+
+		if e.DSN != "" {
+			keyvals = append(keyvals, "dsn", e.DSN)
+		}
+
+		if len(e.Plan) != 0 {
+			keyvals = append(keyvals, "plan", e.Plan)
+		}
+
+		if e.Err != nil {
+			keyvals = append(keyvals, "err", e.Err)
+		}
+
+		if e.RowsAffected != 0 {
+			keyvals = append(keyvals, "rows_affected", e.RowsAffected)
+		}
+
+		if e.LastInsertId != 0 {
+			keyvals = append(keyvals, "last_insert_id", e.LastInsertId)
+		}
+
+		logger.Log(keyvals...)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package teegokit_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee"
+	"github.com/danil/sqltee/examples/teegokit"
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+var goKitTests = []struct {
+	name     string
+	line     string
+	topic    string
+	expected map[string]interface{}
+	fetch    func(*sql.DB) error
+}{
+	{
+		name:  "exec",
+		line:  line(),
+		topic: "stmt-exec-context",
+		expected: map[string]interface{}{
+			"dur":           42 * time.Nanosecond,
+			"query":         "INSERT|tbl|id=42,name='foo'",
+			"rows_affected": int64(1),
+		},
+		fetch: func(db *sql.DB) error {
+			if _, err := db.Exec(`CREATE|tbl|id=int64,name=string`); err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			if _, err := db.Exec("INSERT|tbl|id=?,name=?", 42, "foo"); err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			return nil
+		},
+	},
+	{
+		name:  "query",
+		line:  line(),
+		topic: "stmt-query-context",
+		expected: map[string]interface{}{
+			"dur":   42 * time.Nanosecond,
+			"query": "SELECT|tbl|id|name='foo'",
+		},
+		fetch: func(db *sql.DB) error {
+			if _, err := db.Exec(`CREATE|tbl|id=int64,name=string`); err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			rows, err := db.Query(`SELECT|tbl|id|name=?`, "foo")
+			if err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			return rows.Close()
+		},
+	},
+}
+
+func TestGoKit(t *testing.T) {
+	for _, tt := range goKitTests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			kit := &fakeLogger{}
+			l := teegokit.New(kit)
+			l.Placeholder = "?"
+			l.NewTimer = func() sqltee.Timer { return timer{} }
+			drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: l}
+			connstr := strings.ReplaceAll(fmt.Sprintf("application_name=TestGoKit_%s", tt.line), ":", "_")
+
+			c, err := drv.OpenConnector(connstr)
+			if err != nil {
+				t.Fatalf("driver open connector error: %#v %s", err, tt.line)
+			}
+
+			db := sql.OpenDB(c)
+			defer db.Close()
+
+			err = tt.fetch(db)
+			if err != nil {
+				t.Fatalf("test case fetch error: %#v %s", err, tt.line)
+			}
+
+			db.Close()
+
+			kv, ok := kit.find(tt.topic)
+			if !ok {
+				t.Fatalf("unexpected log, expected topic: %s, recieved: %v %s", tt.topic, kit.logs, tt.line)
+			}
+
+			for k, v := range tt.expected {
+				if kv[k] != v {
+					t.Errorf("unexpected %s, expected: %#v, recieved: %#v %s", k, v, kv[k], tt.line)
+				}
+			}
+
+			if _, ok := kv["err"]; ok {
+				t.Errorf("unexpected err, expected: no err key, recieved: %#v %s", kv["err"], tt.line)
+			}
+		})
+	}
+}
+
+func TestGoKitErrSkip(t *testing.T) {
+	kit := &fakeLogger{}
+	log := teegokit.Callback(kit)
+
+	log(sqltee.Event{Topic: "conn-query-context", Err: driver.ErrSkip})
+	log(sqltee.Event{Topic: "conn-exec", Err: sqltee.ErrSkipUnsupported})
+
+	if len(kit.logs) != 0 {
+		t.Errorf("unexpected logs, expected: no logs of the driver.ErrSkip, recieved: %v", kit.logs)
+	}
+}
+
+// fakeLogger is a go-kit logger which collects the key/value pairs.
+type fakeLogger struct {
+	mu   sync.Mutex
+	logs [][]interface{}
+}
+
+func (l *fakeLogger) Log(keyvals ...interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, keyvals)
+	return nil
+}
+
+// find returns the key/value pairs of the last log with the topic.
+func (l *fakeLogger) find(topic string) (map[string]interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := len(l.logs) - 1; i >= 0; i-- {
+		keyvals := l.logs[i]
+		kv := make(map[string]interface{}, len(keyvals)/2)
+		for j := 0; j+1 < len(keyvals); j += 2 {
+			kv[keyvals[j].(string)] = keyvals[j+1]
+		}
+		if kv["topic"] == topic {
+			return kv, true
+		}
+	}
+
+	return nil, false
+}
+
+type timer struct{}
+
+func (timer) Stop() time.Duration { return 42 * time.Nanosecond }
+
+func line() string {
+	_, file, line, ok := runtime.Caller(1)
+	if ok {
+		return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	return "It was not possible to recover file and line number information about function invocations!"
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package teeotellog bridges the sqltee logs to the OpenTelemetry
+// log data model (go.opentelemetry.io/otel/log).
+package teeotellog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/danil/sqltee"
+)
+
+// Severity is the severity number of the OpenTelemetry log record.
+type Severity int
+
+const (
+	SeverityDebug Severity = 5  // DEBUG
+	SeverityError Severity = 17 // ERROR
+)
+
+// Record is the log record of the OpenTelemetry log data model.
+type Record struct {
+	Timestamp    time.Time              // time when the operation was logged
+	Severity     Severity               // severity number
+	SeverityText string                 // severity text (for example DEBUG)
+	Body         string                 // topic of the operation (for example stmt-exec-context)
+	Attributes   map[string]interface{} // attributes of the operation (for example db.statement)
+}
+
+// Emitter is the logger of the OpenTelemetry logs bridge
+// (for example the adapter to the log.Logger of the logs SDK
+// which converts the Record to the log.Record).
+type Emitter interface {
+	Emit(ctx context.Context, record Record)
+}
+
+// New returns an EventLogger which emits the events as the log records
+// of the database system (for example postgresql) through the emitter.
+func New(emitter Emitter, system string) sqltee.EventLogger {
+	return sqltee.EventLogger{Callback: Callback(emitter, system)}
+}
+
+// Callback returns an EventLogger callback which emits the event
+// as the log record at the debug severity or at the error severity
+// if the operation fails. The record is emitted with the context
+// of the operation (if any), so the emitter may correlate the record
+// with the active span.
+//
+// Attribute "db.statement" holds the query with interpolated parameters
+// or the query as is if nothing was substituted, attribute "db.operation"
+// holds the first keyword of the query (for example SELECT),
+// attribute "db.connection_string" holds the sanitized data source name
+// of the driver-open and the conn-retry, attribute "sqltee.duration_ns" holds the duration of the operation
+// in nanoseconds. Events of the driver.ErrSkip are not emitted.
+func Callback(emitter Emitter, system string) func(sqltee.Event) {
+	return func(e sqltee.Event) {
+		if errors.Is(e.Err, driver.ErrSkip) {
+			return
+		}
+
+		attrs := make(map[string]interface{}, 8)
+		attrs["sqltee.topic"] = e.Topic
+		attrs["sqltee.duration_ns"] = e.Duration.Nanoseconds()
+
+		if system != "" {
+			attrs["db.system"] = system
+		}
+
+		if e.Interpolated != "" {
+			attrs["db.statement"] = e.Interpolated
+		} else if e.Query != "" {
+			attrs["db.statement"] = e.Query
+		}
+
+		if op := operation(e.Query); op != "" {
+			attrs["db.operation"] = op
+		}
+
+		if e.DSN != "" {
+			attrs["db.connection_string"] = e.DSN
+		}
+
+		if e.RowsAffected != 0 {
+			attrs["db.rows_affected"] = e.RowsAffected
+		}
+
+		if e.Role != "" {
+			attrs["sqltee.role"] = e.Role
+		}
+
+		r := Record{
+			Timestamp:    time.Now(),
+			Severity:     SeverityDebug,
+			SeverityText: "DEBUG",
+			Body:         e.Topic,
+			Attributes:   attrs,
+		}
+
+		if e.Err != nil {
+			r.Severity = SeverityError
+			r.SeverityText = "ERROR"
+			attrs["exception.message"] = e.Err.Error()
+		}
+
+		ctx := e.Ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		emitter.Emit(ctx, r)
+	}
+}
+
+// operation returns the upper cased first keyword
+// of the query after the leading comments or blank string.
+func operation(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(")
+
+		if strings.HasPrefix(query, "--") {
+			i := strings.IndexByte(query, '\n')
+			if i == -1 {
+				return ""
+			}
+			query = query[i+1:]
+
+		} else if strings.HasPrefix(query, "/*") {
+			i := strings.Index(query, "*/")
+			if i == -1 {
+				return ""
+			}
+			query = query[i+2:]
+
+		} else {
+			break
+		}
+	}
+
+	i := 0
+	for i < len(query) && (query[i] == '_' || query[i] >= 'a' && query[i] <= 'z' || query[i] >= 'A' && query[i] <= 'Z') {
+		i++
+	}
+
+	return strings.ToUpper(query[:i])
+}
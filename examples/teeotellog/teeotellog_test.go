@@ -0,0 +1,231 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package teeotellog_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee"
+	"github.com/danil/sqltee/examples/teeotellog"
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+var otelLogTests = []struct {
+	name     string
+	line     string
+	topic    string
+	severity teeotellog.Severity
+	expected map[string]interface{}
+	fetch    func(*sql.DB) error
+}{
+	{
+		name:     "exec",
+		line:     line(),
+		topic:    "stmt-exec-context",
+		severity: teeotellog.SeverityDebug,
+		expected: map[string]interface{}{
+			"db.system":          "fakedb",
+			"db.statement":       "INSERT|tbl|id=42,name='foo'",
+			"db.operation":       "INSERT",
+			"db.rows_affected":   int64(1),
+			"sqltee.topic":       "stmt-exec-context",
+			"sqltee.duration_ns": int64(42),
+		},
+		fetch: func(db *sql.DB) error {
+			if _, err := db.Exec(`CREATE|tbl|id=int64,name=string`); err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			if _, err := db.Exec("INSERT|tbl|id=?,name=?", 42, "foo"); err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			return nil
+		},
+	},
+	{
+		name:     "query",
+		line:     line(),
+		topic:    "stmt-query-context",
+		severity: teeotellog.SeverityDebug,
+		expected: map[string]interface{}{
+			"db.system":          "fakedb",
+			"db.statement":       "SELECT|tbl|id|name='foo'",
+			"db.operation":       "SELECT",
+			"sqltee.topic":       "stmt-query-context",
+			"sqltee.duration_ns": int64(42),
+		},
+		fetch: func(db *sql.DB) error {
+			if _, err := db.Exec(`CREATE|tbl|id=int64,name=string`); err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			rows, err := db.Query(`SELECT|tbl|id|name=?`, "foo")
+			if err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			return rows.Close()
+		},
+	},
+	{
+		name:     "error",
+		line:     line(),
+		topic:    "stmt-query-context",
+		severity: teeotellog.SeverityError,
+		expected: map[string]interface{}{
+			"db.system":          "fakedb",
+			"db.statement":       "SELECT|nonexistent|id|",
+			"db.operation":       "SELECT",
+			"sqltee.topic":       "stmt-query-context",
+			"sqltee.duration_ns": int64(42),
+			"exception.message":  `fakedb: table "nonexistent" doesn't exist`,
+		},
+		fetch: func(db *sql.DB) error {
+			if _, err := db.Query(`SELECT|nonexistent|id|`); err == nil {
+				return fmt.Errorf("expected error %s", line())
+			}
+			return nil
+		},
+	},
+}
+
+func TestOTelLog(t *testing.T) {
+	for _, tt := range otelLogTests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			exp := &exporter{}
+			l := teeotellog.New(exp, "fakedb")
+			l.Placeholder = "?"
+			l.NewTimer = func() sqltee.Timer { return timer{} }
+			drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: l}
+			connstr := strings.ReplaceAll(fmt.Sprintf("application_name=TestOTelLog_%s", tt.line), ":", "_")
+
+			c, err := drv.OpenConnector(connstr)
+			if err != nil {
+				t.Fatalf("driver open connector error: %#v %s", err, tt.line)
+			}
+
+			db := sql.OpenDB(c)
+			defer db.Close()
+
+			start := time.Now()
+
+			err = tt.fetch(db)
+			if err != nil {
+				t.Fatalf("test case fetch error: %#v %s", err, tt.line)
+			}
+
+			db.Close()
+
+			r, ok := exp.find(tt.topic)
+			if !ok {
+				t.Fatalf("unexpected records, expected: record of %s, recieved: %+v %s", tt.topic, exp.records, tt.line)
+			}
+
+			if r.Severity != tt.severity || r.Timestamp.Before(start) {
+				t.Errorf("unexpected record, expected: severity %d after %s, recieved: %+v %s", tt.severity, start, r, tt.line)
+			}
+
+			if len(r.Attributes) != len(tt.expected) {
+				t.Errorf("unexpected attributes, expected: %v, recieved: %v %s", tt.expected, r.Attributes, tt.line)
+			}
+
+			for k, v := range tt.expected {
+				if r.Attributes[k] != v {
+					t.Errorf("unexpected %s, expected: %#v, recieved: %#v %s", k, v, r.Attributes[k], tt.line)
+				}
+			}
+		})
+	}
+}
+
+func TestOTelLogRecordPerOperation(t *testing.T) {
+	exp := &exporter{}
+	l := teeotellog.New(exp, "postgresql")
+
+	l.ConnBeginTx(context.Background(), time.Millisecond, driver.TxOptions{}, nil)
+	l.ConnExecContext(context.Background(), time.Millisecond, "UPDATE foo SET bar = 1", nil, nil, driver.ErrSkip)
+	l.ConnPrepareContext(context.Background(), time.Millisecond, "/* app */ UPDATE foo SET bar = 1", nil)
+	l.StmtExecContext(context.Background(), time.Millisecond, "/* app */ UPDATE foo SET bar = 1", nil, driver.RowsAffected(2), nil)
+	l.StmtClose(time.Millisecond, nil)
+	l.TxCommit(time.Millisecond, nil)
+
+	var topics []string
+	for _, r := range exp.records {
+		topics = append(topics, r.Body)
+	}
+
+	expected := "conn-begin-tx conn-prepare-context stmt-exec-context stmt-close tx-commit"
+	if strings.Join(topics, " ") != expected {
+		t.Errorf("unexpected records, expected: %s, recieved: %v", expected, topics)
+	}
+
+	if r := exp.records[2]; r.Attributes["db.operation"] != "UPDATE" || r.Attributes["db.rows_affected"] != int64(2) {
+		t.Errorf("unexpected attributes, expected: UPDATE of 2 rows, recieved: %v", r.Attributes)
+	}
+}
+
+func TestOTelLogContext(t *testing.T) {
+	type key struct{}
+
+	exp := &exporter{}
+	l := teeotellog.New(exp, "postgresql")
+	ctx := context.WithValue(context.Background(), key{}, "span")
+
+	l.ConnPing(ctx, time.Millisecond, nil)
+	l.ConnClose(time.Millisecond, nil)
+
+	if len(exp.records) != 2 || exp.ctxs[0].Value(key{}) != "span" || exp.ctxs[1] == nil {
+		t.Errorf("unexpected contexts, expected: context of the ping and background, recieved: %v", exp.ctxs)
+	}
+}
+
+// exporter is an in-memory emitter which collects the records.
+type exporter struct {
+	mu      sync.Mutex
+	records []teeotellog.Record
+	ctxs    []context.Context
+}
+
+func (e *exporter) Emit(ctx context.Context, r teeotellog.Record) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.records = append(e.records, r)
+	e.ctxs = append(e.ctxs, ctx)
+}
+
+// find returns the last record of the topic.
+func (e *exporter) find(topic string) (teeotellog.Record, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := len(e.records) - 1; i >= 0; i-- {
+		if e.records[i].Body == topic {
+			return e.records[i], true
+		}
+	}
+	return teeotellog.Record{}, false
+}
+
+type timer struct{}
+
+func (timer) Stop() time.Duration { return 42 * time.Nanosecond }
+
+func line() string {
+	_, file, line, ok := runtime.Caller(1)
+	if ok {
+		return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	return "It was not possible to recover file and line number information about function invocations!"
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The hand-written encoding of the Record message of record.proto
+// which does not depend on the protobuf runtime (google.golang.org/protobuf),
+// the messages are interchangeable with any protobuf implementation
+// of record.proto.
+
+package teeproto
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Record is the sqltee log record of one operation (see record.proto).
+type Record struct {
+	Topic        string // name of the operation (for example stmt-exec-context)
+	DurationNs   int64  // execution time in nanoseconds
+	Query        string // query
+	Interpolated string // query with interpolated parameters, blank if nothing was substituted
+	Error        string // error of the operation, blank if the operation succeeds
+	RowsAffected int64  // number of rows affected by the execution
+	DSN          string // sanitized data source name of the driver-open and the conn-retry, blank otherwise
+}
+
+// Field numbers of the Record message.
+const (
+	fieldTopic        = 1
+	fieldDurationNs   = 2
+	fieldQuery        = 3
+	fieldInterpolated = 4
+	fieldError        = 5
+	fieldRowsAffected = 6
+	fieldDSN          = 7
+)
+
+// Wire types of the protobuf encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// ErrInvalidRecord is returned by the Unmarshal of the malformed message.
+var ErrInvalidRecord = errors.New("teeproto: invalid record")
+
+// Marshal returns the protobuf encoding of the record,
+// the fields of the zero values are omitted as of proto3.
+func (r *Record) Marshal() []byte {
+	b := make([]byte, 0, 32+len(r.Topic)+len(r.Query)+len(r.Interpolated)+len(r.Error)+len(r.DSN))
+	b = appendString(b, fieldTopic, r.Topic)
+	b = appendInt64(b, fieldDurationNs, r.DurationNs)
+	b = appendString(b, fieldQuery, r.Query)
+	b = appendString(b, fieldInterpolated, r.Interpolated)
+	b = appendString(b, fieldError, r.Error)
+	b = appendInt64(b, fieldRowsAffected, r.RowsAffected)
+	b = appendString(b, fieldDSN, r.DSN)
+	return b
+}
+
+// Unmarshal decodes the protobuf encoding of the record into the r,
+// the unknown fields are skipped.
+func (r *Record) Unmarshal(b []byte) error {
+	*r = Record{}
+
+	for len(b) != 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return fmt.Errorf("%w: malformed tag", ErrInvalidRecord)
+		}
+		b = b[n:]
+
+		num, typ := tag>>3, tag&7
+
+		switch typ {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return fmt.Errorf("%w: malformed varint of field %d", ErrInvalidRecord, num)
+			}
+			b = b[n:]
+
+			switch num {
+			case fieldDurationNs:
+				r.DurationNs = int64(v)
+			case fieldRowsAffected:
+				r.RowsAffected = int64(v)
+			}
+
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return fmt.Errorf("%w: malformed length of field %d", ErrInvalidRecord, num)
+			}
+			v := string(b[n : n+int(l)])
+			b = b[n+int(l):]
+
+			switch num {
+			case fieldTopic:
+				r.Topic = v
+			case fieldQuery:
+				r.Query = v
+			case fieldInterpolated:
+				r.Interpolated = v
+			case fieldError:
+				r.Error = v
+			case fieldDSN:
+				r.DSN = v
+			}
+
+		case wireFixed64, wireFixed32:
+			size := 8
+			if typ == wireFixed32 {
+				size = 4
+			}
+			if len(b) < size {
+				return fmt.Errorf("%w: truncated field %d", ErrInvalidRecord, num)
+			}
+			b = b[size:]
+
+		default:
+			return fmt.Errorf("%w: unsupported wire type %d of field %d", ErrInvalidRecord, typ, num)
+		}
+	}
+
+	return nil
+}
+
+func appendString(b []byte, num int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = appendUvarint(b, uint64(num)<<3|wireBytes)
+	b = appendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendInt64(b []byte, num int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendUvarint(b, uint64(num)<<3|wireVarint)
+	return appendUvarint(b, uint64(v))
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package teeproto encodes the sqltee logs as the stream
+// of the length-delimited protobuf messages (see record.proto).
+package teeproto
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/danil/sqltee"
+)
+
+// New returns an EventLogger which writes the events
+// as the length-delimited records to the writer.
+func New(w io.Writer) sqltee.EventLogger {
+	return sqltee.EventLogger{Callback: Callback(w)}
+}
+
+// Callback returns an EventLogger callback which writes the event
+// as the record prefixed by the varint length of the record
+// (as the writeDelimitedTo of the protobuf libraries).
+// The records are written by one write each and the writes
+// are serialized, so the writer may be shared by the connections.
+// Field "interpolated" holds the query with interpolated parameters.
+// Events of the driver.ErrSkip are not written, the write errors are ignored.
+func Callback(w io.Writer) func(sqltee.Event) {
+	var mu sync.Mutex
+
+	return func(e sqltee.Event) {
+		if errors.Is(e.Err, driver.ErrSkip) {
+			return
+		}
+
+		r := Record{
+			Topic:        e.Topic,
+			DurationNs:   e.Duration.Nanoseconds(),
+			Query:        e.Query,
+			Interpolated: e.Interpolated,
+			RowsAffected: e.RowsAffected,
+			DSN:          e.DSN,
+		}
+
+		if e.Err != nil {
+			r.Error = e.Err.Error()
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		_ = WriteDelimited(w, &r)
+	}
+}
+
+// WriteDelimited writes the record prefixed by the varint length of the record.
+func WriteDelimited(w io.Writer, r *Record) error {
+	msg := r.Marshal()
+	b := appendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(msg)), uint64(len(msg)))
+	_, err := w.Write(append(b, msg...))
+	return err
+}
+
+// Reader reads the length-delimited records.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a Reader of the stream of the length-delimited records.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next record or io.EOF at the end of the stream.
+func (r *Reader) Read() (Record, error) {
+	l, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Record{}, io.EOF
+		}
+		return Record{}, fmt.Errorf("%w: malformed length: %v", ErrInvalidRecord, err)
+	}
+
+	b := make([]byte, l)
+	_, err = io.ReadFull(r.r, b)
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: truncated record: %v", ErrInvalidRecord, err)
+	}
+
+	var rec Record
+	err = rec.Unmarshal(b)
+	return rec, err
+}
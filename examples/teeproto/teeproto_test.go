@@ -0,0 +1,202 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package teeproto_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee"
+	"github.com/danil/sqltee/examples/teeproto"
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+var marshalTests = []struct {
+	name     string
+	line     string
+	record   teeproto.Record
+	expected []byte
+}{
+	{
+		name:     "zero",
+		line:     line(),
+		expected: []byte{},
+	},
+	{
+		name:     "topic and duration",
+		line:     line(),
+		record:   teeproto.Record{Topic: "a", DurationNs: 1},
+		expected: []byte{0x0a, 0x01, 'a', 0x10, 0x01},
+	},
+	{
+		name:     "multibyte varint",
+		line:     line(),
+		record:   teeproto.Record{DurationNs: 300, RowsAffected: 2},
+		expected: []byte{0x10, 0xac, 0x02, 0x30, 0x02},
+	},
+	{
+		name:     "negative int64",
+		line:     line(),
+		record:   teeproto.Record{RowsAffected: -1},
+		expected: []byte{0x30, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+	},
+	{
+		name:     "all fields",
+		line:     line(),
+		record:   teeproto.Record{Topic: "t", DurationNs: 42, Query: "q", Interpolated: "i", Error: "e", RowsAffected: 1, DSN: "d"},
+		expected: []byte{0x0a, 0x01, 't', 0x10, 0x2a, 0x1a, 0x01, 'q', 0x22, 0x01, 'i', 0x2a, 0x01, 'e', 0x30, 0x01, 0x3a, 0x01, 'd'},
+	},
+}
+
+func TestRecordMarshal(t *testing.T) {
+	for _, tt := range marshalTests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			b := tt.record.Marshal()
+			if !bytes.Equal(b, tt.expected) {
+				t.Errorf("unexpected encoding, expected: % x, recieved: % x %s", tt.expected, b, tt.line)
+			}
+
+			var r teeproto.Record
+			err := r.Unmarshal(b)
+			if err != nil {
+				t.Fatalf("unmarshal error: %#v %s", err, tt.line)
+			}
+
+			if r != tt.record {
+				t.Errorf("unexpected record, expected: %+v, recieved: %+v %s", tt.record, r, tt.line)
+			}
+		})
+	}
+}
+
+func TestRecordUnmarshalUnknownFields(t *testing.T) {
+	b := []byte{
+		0x0a, 0x01, 't', // topic
+		0x38, 0x07, // field 7 varint
+		0x42, 0x02, 'x', 'y', // field 8 bytes
+		0x49, 1, 2, 3, 4, 5, 6, 7, 8, // field 9 fixed64
+		0x55, 1, 2, 3, 4, // field 10 fixed32
+		0x30, 0x03, // rows affected
+	}
+
+	var r teeproto.Record
+	err := r.Unmarshal(b)
+	if err != nil {
+		t.Fatalf("unmarshal error: %#v", err)
+	}
+
+	expected := teeproto.Record{Topic: "t", RowsAffected: 3}
+	if r != expected {
+		t.Errorf("unexpected record, expected: %+v, recieved: %+v", expected, r)
+	}
+
+	err = r.Unmarshal([]byte{0x0a, 0x05, 't'})
+	if !errors.Is(err, teeproto.ErrInvalidRecord) {
+		t.Errorf("unexpected error of the truncated record, expected: %v, recieved: %v", teeproto.ErrInvalidRecord, err)
+	}
+}
+
+func TestProto(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := teeproto.New(&buf)
+	l.Placeholder = "?"
+	l.NewTimer = func() sqltee.Timer { return timer{} }
+	drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("TestProto")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+
+	_, err = db.Exec(`CREATE|proto|id=int64,name=string`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|proto|id=?,name=?", 42, "foo")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Query(`SELECT|nonexistent|id|`)
+	if err == nil {
+		t.Fatal("expected db query error")
+	}
+
+	db.Close()
+
+	var records []teeproto.Record
+	r := teeproto.NewReader(&buf)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read error: %#v", err)
+		}
+		records = append(records, rec)
+	}
+
+	expected := []teeproto.Record{
+		{Topic: "driver-open", DurationNs: 42, DSN: "TestProto"},
+		{Topic: "conn-prepare-context", DurationNs: 42, Query: "CREATE|proto|id=int64,name=string"},
+		{Topic: "stmt-exec-context", DurationNs: 42, Query: "CREATE|proto|id=int64,name=string"},
+		{Topic: "stmt-close", DurationNs: 42},
+		{Topic: "conn-prepare-context", DurationNs: 42, Query: "INSERT|proto|id=?,name=?"},
+		{Topic: "stmt-exec-context", DurationNs: 42, Query: "INSERT|proto|id=?,name=?", Interpolated: "INSERT|proto|id=42,name='foo'", RowsAffected: 1},
+		{Topic: "stmt-close", DurationNs: 42},
+		{Topic: "conn-prepare-context", DurationNs: 42, Query: "SELECT|nonexistent|id|"},
+		{Topic: "stmt-query-context", DurationNs: 42, Query: "SELECT|nonexistent|id|", Error: `fakedb: table "nonexistent" doesn't exist`},
+		{Topic: "stmt-close", DurationNs: 42},
+		{Topic: "conn-close", DurationNs: 42},
+	}
+
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("unexpected records, expected: %+v, recieved: %+v", expected, records)
+	}
+}
+
+func TestProtoReadTruncated(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := teeproto.New(&buf)
+	l.ConnExecContext(context.Background(), time.Millisecond, "UPDATE foo SET bar = 1", nil, driver.RowsAffected(2), nil)
+
+	r := teeproto.NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	_, err := r.Read()
+	if !errors.Is(err, teeproto.ErrInvalidRecord) {
+		t.Errorf("unexpected error, expected: %v, recieved: %v", teeproto.ErrInvalidRecord, err)
+	}
+}
+
+type timer struct{}
+
+func (timer) Stop() time.Duration { return 42 * time.Nanosecond }
+
+func line() string {
+	_, file, line, ok := runtime.Caller(1)
+	if ok {
+		return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	return "It was not possible to recover file and line number information about function invocations!"
+}
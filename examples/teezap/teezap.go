@@ -0,0 +1,61 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package teezap bridges the sqltee logs to the zap logger.
+package teezap
+
+import (
+	"database/sql/driver"
+	"errors"
+
+	"github.com/danil/sqltee"
+	"go.uber.org/zap"
+)
+
+// New returns an EventLogger which logs the events
+// through the zap logger.
+func New(logger *zap.Logger) sqltee.EventLogger {
+	return sqltee.EventLogger{Callback: Callback(logger)}
+}
+
+// Callback returns an EventLogger callback which logs the event
+// with the typed fields through the zap logger at the debug level
+// or at the error level if the operation fails.
+// Field "query" holds the query with interpolated parameters
+// or the query as is if nothing was substituted,
+// field "dsn" holds the sanitized data source name of the driver-open
+// and the conn-retry, field "role" holds the database role of the tagged driver.
+// Events of the driver.ErrSkip are not logged.
+func Callback(logger *zap.Logger) func(sqltee.Event) {
+	return func(e sqltee.Event) {
+		if errors.Is(e.Err, driver.ErrSkip) {
+			return
+		}
+
+		fields := make([]zap.Field, 0, 5)
+		fields = append(fields, zap.String("topic", e.Topic), zap.Duration("dur", e.Duration))
+
+		if e.Interpolated != "" {
+			fields = append(fields, zap.String("query", e.Interpolated))
+		} else if e.Query != "" {
+			fields = append(fields, zap.String("query", e.Query))
+		}
+
+		if e.DSN != "" {
+			fields = append(fields, zap.String("dsn", e.DSN))
+		}
+
+		if e.Role != "" {
+			fields = append(fields, zap.String("role", e.Role))
+		}
+
+		if e.Err != nil {
+			fields = append(fields, zap.Error(e.Err))
+			logger.Error("sqltee", fields...)
+			return
+		}
+
+		logger.Debug("sqltee", fields...)
+	}
+}
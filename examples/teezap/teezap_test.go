@@ -0,0 +1,164 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package teezap_test
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee"
+	"github.com/danil/sqltee/examples/teezap"
+	"github.com/danil/sqltee/internal/fakedb"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var zapTests = []struct {
+	name     string
+	line     string
+	topic    string
+	level    zapcore.Level
+	expected map[string]interface{}
+	fetch    func(*sql.DB) error
+}{
+	{
+		name:  "exec",
+		line:  line(),
+		topic: "stmt-exec-context",
+		level: zapcore.DebugLevel,
+		expected: map[string]interface{}{
+			"topic": "stmt-exec-context",
+			"dur":   42 * time.Nanosecond,
+			"query": "INSERT|tbl|id=42,name='foo'",
+		},
+		fetch: func(db *sql.DB) error {
+			if _, err := db.Exec(`CREATE|tbl|id=int64,name=string`); err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			if _, err := db.Exec("INSERT|tbl|id=?,name=?", 42, "foo"); err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			return nil
+		},
+	},
+	{
+		name:  "query",
+		line:  line(),
+		topic: "stmt-query-context",
+		level: zapcore.DebugLevel,
+		expected: map[string]interface{}{
+			"topic": "stmt-query-context",
+			"dur":   42 * time.Nanosecond,
+			"query": "SELECT|tbl|id|name='foo'",
+		},
+		fetch: func(db *sql.DB) error {
+			if _, err := db.Exec(`CREATE|tbl|id=int64,name=string`); err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			rows, err := db.Query(`SELECT|tbl|id|name=?`, "foo")
+			if err != nil {
+				return fmt.Errorf("%#v %s", err, line())
+			}
+			return rows.Close()
+		},
+	},
+	{
+		name:  "failing query",
+		line:  line(),
+		topic: "stmt-query-context",
+		level: zapcore.ErrorLevel,
+		expected: map[string]interface{}{
+			"topic": "stmt-query-context",
+			"dur":   42 * time.Nanosecond,
+			"query": "SELECT|nonexistent_table|id|",
+			"error": `fakedb: table "nonexistent_table" doesn't exist`,
+		},
+		fetch: func(db *sql.DB) error {
+			rows, err := db.Query(`SELECT|nonexistent_table|id|`)
+			if err == nil {
+				rows.Close()
+				return fmt.Errorf("expected query error %s", line())
+			}
+			return nil
+		},
+	},
+}
+
+func TestZap(t *testing.T) {
+	for _, tt := range zapTests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			core, logs := observer.New(zapcore.DebugLevel)
+			l := teezap.New(zap.New(core))
+			l.Placeholder = "?"
+			l.NewTimer = func() sqltee.Timer { return timer{} }
+			drv := &sqltee.Driver{Driver: fakedb.Driver, Logger: l}
+			connstr := strings.ReplaceAll(fmt.Sprintf("application_name=TestZap_%s", tt.line), ":", "_")
+
+			c, err := drv.OpenConnector(connstr)
+			if err != nil {
+				t.Fatalf("driver open connector error: %#v %s", err, tt.line)
+			}
+
+			db := sql.OpenDB(c)
+			defer db.Close()
+
+			err = tt.fetch(db)
+			if err != nil {
+				t.Fatalf("test case fetch error: %#v %s", err, tt.line)
+			}
+
+			db.Close()
+
+			entries := logs.FilterField(zap.String("topic", tt.topic)).All()
+			if len(entries) == 0 {
+				t.Fatalf("unexpected log, expected topic: %s, recieved: %v %s", tt.topic, logs.All(), tt.line)
+			}
+
+			entry := entries[len(entries)-1]
+			if entry.Level != tt.level {
+				t.Errorf("unexpected level, expected: %s, recieved: %s %s", tt.level, entry.Level, tt.line)
+			}
+
+			fields := entry.ContextMap()
+			if len(fields) != len(tt.expected) {
+				t.Errorf("unexpected fields, expected: %#v, recieved: %#v %s", tt.expected, fields, tt.line)
+			}
+
+			for k, v := range tt.expected {
+				if fields[k] != v {
+					t.Errorf("unexpected %s, expected: %#v, recieved: %#v %s", k, v, fields[k], tt.line)
+				}
+			}
+
+			for _, e := range logs.All() {
+				if _, ok := e.ContextMap()["error"]; ok && strings.Contains(e.ContextMap()["error"].(string), "skip") {
+					t.Errorf("unexpected driver.ErrSkip log: %v %s", e.ContextMap(), tt.line)
+				}
+			}
+		})
+	}
+}
+
+type timer struct{}
+
+func (timer) Stop() time.Duration { return 42 * time.Nanosecond }
+
+func line() string {
+	_, file, line, ok := runtime.Caller(1)
+	if ok {
+		return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	return "It was not possible to recover file and line number information about function invocations!"
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// explanation is a plan request of the slow SELECT query.
+// The EXPLAIN query is executed on the same underlying connection
+// after the rows of the explained query are closed, so the EXPLAIN query
+// itself is neither logged nor explained.
+type explanation struct {
+	conn    connection
+	ctx     context.Context
+	query   string
+	dargs   []driver.Value
+	nvdargs []driver.NamedValue
+	slow    bool // query is slower than threshold
+}
+
+// ExplainLogger may be implemented by the Logger to log the plans
+// of the slow SELECT queries (see Driver.ExplainSlowerThan).
+type ExplainLogger interface {
+	ConnExplain(ctx context.Context, d time.Duration, query string, plan []string, err error)
+}
+
+// logExplain logs the plan if the logger implements ExplainLogger.
+func logExplain(l Logger, ctx context.Context, d time.Duration, query string, plan []string, err error) {
+	if el, ok := l.(ExplainLogger); ok {
+		el.ConnExplain(ctx, d, query, plan, err)
+	}
+}
+
+// explanation returns the plan request or nil if explain is disabled
+// or if the query is not a SELECT query.
+func (c connection) explanation(ctx context.Context, query string, dargs []driver.Value, nvdargs []driver.NamedValue) *explanation {
+	if c.explainSlowerThan <= 0 || !isSelect(query) {
+		return nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &explanation{conn: c, ctx: ctx, query: query, dargs: dargs, nvdargs: nvdargs}
+}
+
+// stop marks the query as slow if the query execution time exceeds threshold
+// and returns the query execution time.
+func (e *explanation) stop(d time.Duration) time.Duration {
+	if e != nil {
+		e.slow = d > e.conn.explainSlowerThan
+	}
+
+	return d
+}
+
+// explain logs the plan of the slow query.
+func (e *explanation) explain() {
+	if e == nil || !e.slow {
+		return
+	}
+
+	e.slow = false
+
+	t := startTimer(e.conn.Logger)
+	var (
+		plan []string
+		err  error
+	)
+
+	defer func() { logExplain(e.conn.Logger, e.ctx, t.Stop(), e.query, plan, err) }()
+
+	var rows driver.Rows
+	rows, err = e.rows("EXPLAIN " + e.query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, len(rows.Columns()))
+
+	for {
+		err = rows.Next(dest)
+		if err == io.EOF {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		cols := make([]string, len(dest))
+		for i, v := range dest {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			cols[i] = fmt.Sprint(v)
+		}
+
+		plan = append(plan, strings.Join(cols, " "))
+	}
+}
+
+// rows queries the underlying connection.
+func (e *explanation) rows(query string) (driver.Rows, error) {
+	nvdargs := e.nvdargs
+	if nvdargs == nil {
+		nvdargs = make([]driver.NamedValue, len(e.dargs))
+		for i, v := range e.dargs {
+			nvdargs[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+		}
+	}
+
+	if queryerContext, ok := e.conn.conn.(driver.QueryerContext); ok {
+		rows, err := queryerContext.QueryContext(e.ctx, query, nvdargs)
+		if err != driver.ErrSkip {
+			return rows, err
+		}
+	}
+
+	dargs, err := namedValueToValue(nvdargs)
+	if err != nil {
+		return nil, err
+	}
+
+	if queryer, ok := e.conn.conn.(driver.Queryer); ok {
+		rows, err := queryer.Query(query, dargs)
+		if err != driver.ErrSkip {
+			return rows, err
+		}
+	}
+
+	stmt, err := e.conn.conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.Query(dargs)
+	if err != nil {
+		stmt.Close()
+		return nil, err
+	}
+
+	return stmtRows{Rows: rows, stmt: stmt}, nil
+}
+
+// stmtRows closes the statement on close of the rows.
+type stmtRows struct {
+	driver.Rows
+	stmt driver.Stmt
+}
+
+func (r stmtRows) Close() error {
+	err := r.Rows.Close()
+	if err := r.stmt.Close(); err != nil {
+		return err
+	}
+	return err
+}
+
+// isSelect reports whether the query is a read only SELECT query.
+func isSelect(query string) bool {
+	query = strings.TrimLeftFunc(query, func(r rune) bool { return unicode.IsSpace(r) || r == '(' })
+	if len(query) < len("SELECT") {
+		return false
+	}
+
+	return strings.EqualFold(query[:len("SELECT")], "SELECT") &&
+		(len(query) == len("SELECT") || !unicode.IsLetter(rune(query[len("SELECT")])))
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import "context"
+
+type fallbackKey struct{}
+
+// Fallback returns true if the conn-exec-context or the conn-query-context
+// is logged for the connection which does not implement driver.ExecerContext
+// or driver.QueryerContext, so the query is executed (if at all)
+// by the conn-exec or the conn-query or by the prepared statement
+// which are logged separately and the loggers may skip the operation
+// to not count the query twice.
+func Fallback(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	fallback, _ := ctx.Value(fallbackKey{}).(bool)
+	return fallback
+}
+
+// withFallback returns a copy of the parent context which flags
+// the fallback or the parent context as is if the fallback is false.
+func withFallback(ctx context.Context, fallback bool) context.Context {
+	if !fallback {
+		return ctx
+	}
+
+	return context.WithValue(ctx, fallbackKey{}, true)
+}
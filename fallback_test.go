@@ -0,0 +1,64 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestFallback(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		fallbacks = map[string]bool{}
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			if e.Topic == "conn-exec-context" || e.Topic == "conn-query-context" {
+				fallbacks[e.Topic] = Fallback(e.Ctx)
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+
+	drv := &Driver{Driver: noContextDriver{}, Logger: l}
+
+	c, err := drv.OpenConnector("TestFallback")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	rows, err := db.Query("SELECT|t1|name|")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+	rows.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := map[string]bool{"conn-exec-context": true, "conn-query-context": true}
+	if !reflect.DeepEqual(fallbacks, expected) {
+		t.Errorf("unexpected fallbacks, expected: %v, recieved: %v", expected, fallbacks)
+	}
+
+	if Fallback(context.Background()) {
+		t.Error("unexpected fallback of the background context")
+	}
+}
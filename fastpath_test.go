@@ -0,0 +1,69 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// noFastPathConn is a connection without driver.Execer and driver.Queryer.
+type noFastPathConn struct{}
+
+func (noFastPathConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (noFastPathConn) Close() error                        { return nil }
+func (noFastPathConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+// skipConn is a connection which skips the fast-path of each query.
+type skipConn struct{ noFastPathConn }
+
+func (skipConn) Exec(string, []driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (skipConn) Query(string, []driver.Value) (driver.Rows, error)  { return nil, driver.ErrSkip }
+
+func TestSkipUnsupported(t *testing.T) {
+	var tests = []struct {
+		name        string
+		conn        driver.Conn
+		unsupported bool
+	}{
+		{name: "driver without fast-path", conn: noFastPathConn{}, unsupported: true},
+		{name: "driver skips fast-path", conn: skipConn{}, unsupported: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var logged []error
+			l := EventLogger{
+				Callback: func(e Event) { logged = append(logged, e.Err) },
+				NewTimer: func() Timer { return fakeTimer{} },
+			}
+			c := connection{Logger: l, conn: tt.conn}
+
+			_, err := c.Exec("UPDATE foo SET bar = 1", nil)
+			if err != driver.ErrSkip {
+				t.Errorf("unexpected exec error, expected: %v, recieved: %v", driver.ErrSkip, err)
+			}
+
+			_, err = c.Query("SELECT 1", nil)
+			if err != driver.ErrSkip {
+				t.Errorf("unexpected query error, expected: %v, recieved: %v", driver.ErrSkip, err)
+			}
+
+			if len(logged) != 2 {
+				t.Fatalf("unexpected number of logs, expected: 2, recieved: %d", len(logged))
+			}
+
+			for _, err := range logged {
+				if !errors.Is(err, driver.ErrSkip) || errors.Is(err, ErrSkipUnsupported) != tt.unsupported {
+					t.Errorf("unexpected logged error, expected unsupported: %t, recieved: %v", tt.unsupported, err)
+				}
+			}
+		})
+	}
+}
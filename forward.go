@@ -0,0 +1,122 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+// forwarder is embedded by the decorators of the Logger to forward
+// the optional interfaces of the inner logger through the decorator,
+// so the decorator does not turn off the features of the inner logger.
+//
+// The tagging interfaces (RoleLogger, CorrelationLogger,
+// DriverVersionLogger, HostInfoLogger and StmtLeakLogger) return
+// the decorator of the tagged inner logger, the TimingLogger reports
+// the timing of the inner logger and the rest of the optional interfaces
+// are passed to the inner logger as is unless the decorator overrides them.
+type forwarder struct {
+	Logger                                      // inner logger
+	with   func(tag func(Logger) Logger) Logger // returns the decorator of the inner logger tagged by the tag
+}
+
+// forward returns the forwarder of the inner logger,
+// the wrap returns the decorator of the tagged inner logger
+// which shares the state of the decorator.
+func forward(inner Logger, wrap func(inner Logger) Logger) forwarder {
+	return forwarder{
+		Logger: inner,
+		with:   func(tag func(Logger) Logger) Logger { return wrap(tag(inner)) },
+	}
+}
+
+// inner returns the inner logger.
+func (f forwarder) inner() Logger {
+	return f.Logger
+}
+
+func (f forwarder) WithRole(role string) Logger {
+	return f.with(func(l Logger) Logger {
+		if rl, ok := l.(RoleLogger); ok {
+			return rl.WithRole(role)
+		}
+		return l
+	})
+}
+
+func (f forwarder) WithCorrelation(id uint64) Logger {
+	return f.with(func(l Logger) Logger {
+		if cl, ok := l.(CorrelationLogger); ok {
+			return cl.WithCorrelation(id)
+		}
+		return l
+	})
+}
+
+func (f forwarder) WithDriverVersion(version string) Logger {
+	return f.with(func(l Logger) Logger {
+		if vl, ok := l.(DriverVersionLogger); ok {
+			return vl.WithDriverVersion(version)
+		}
+		return l
+	})
+}
+
+func (f forwarder) WithHostInfo(host string, pid int) Logger {
+	return f.with(func(l Logger) Logger {
+		if hl, ok := l.(HostInfoLogger); ok {
+			return hl.WithHostInfo(host, pid)
+		}
+		return l
+	})
+}
+
+func (f forwarder) WithStmtLeak(unclosed int) Logger {
+	return f.with(func(l Logger) Logger {
+		if sl, ok := l.(StmtLeakLogger); ok {
+			return sl.WithStmtLeak(unclosed)
+		}
+		return l
+	})
+}
+
+// NeedsTiming reports whether the inner logger needs the timing.
+func (f forwarder) NeedsTiming() bool {
+	return needsTiming(f.Logger)
+}
+
+func (f forwarder) ConnRetry(name string, d time.Duration, attempt int, backoff time.Duration, err error) {
+	if rl, ok := f.Logger.(RetryLogger); ok {
+		rl.ConnRetry(name, d, attempt, backoff, err)
+	}
+}
+
+func (f forwarder) ConnectorConnect(ctx context.Context, d time.Duration, err error) {
+	if cl, ok := f.Logger.(ConnectorLogger); ok {
+		cl.ConnectorConnect(ctx, d, err)
+	}
+}
+
+func (f forwarder) ConnPrepareFallback(ctx context.Context, d time.Duration, query string, err error) {
+	logPrepareFallback(f.Logger, ctx, d, query, err)
+}
+
+func (f forwarder) ConnRaw() {
+	logRaw(f.Logger)
+}
+
+func (f forwarder) ConnExplain(ctx context.Context, d time.Duration, query string, plan []string, err error) {
+	logExplain(f.Logger, ctx, d, query, plan, err)
+}
+
+func (f forwarder) StmtCloseTotal(d, total time.Duration, err error) {
+	logStmtClose(f.Logger, d, total, err)
+}
+
+func (f forwarder) RowsNextRow(d time.Duration, row int, columns []string, dest []driver.Value, err error) {
+	logRowsNext(f.Logger, d, row, columns, dest, err)
+}
@@ -0,0 +1,237 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tagLogger is a Logger of the optional interfaces
+// which records the logs with the tags.
+type tagLogger struct {
+	NopLogger
+	mu     *sync.Mutex
+	logs   *[]string
+	tags   string
+	timing bool
+}
+
+func newTagLogger(timing bool) tagLogger {
+	return tagLogger{mu: new(sync.Mutex), logs: new([]string), timing: timing}
+}
+
+func (l tagLogger) WithRole(role string) Logger {
+	l.tags += " role=" + role
+	return l
+}
+
+func (l tagLogger) WithCorrelation(id uint64) Logger {
+	l.tags += fmt.Sprintf(" correlation=%d", id)
+	return l
+}
+
+func (l tagLogger) WithDriverVersion(version string) Logger {
+	l.tags += " version=" + version
+	return l
+}
+
+func (l tagLogger) WithHostInfo(host string, pid int) Logger {
+	l.tags += fmt.Sprintf(" host=%s pid=%d", host, pid)
+	return l
+}
+
+func (l tagLogger) WithStmtLeak(unclosed int) Logger {
+	l.tags += fmt.Sprintf(" stmt-leak=%d", unclosed)
+	return l
+}
+
+func (l tagLogger) NeedsTiming() bool { return l.timing }
+
+func (l tagLogger) TxCommit(_ time.Duration, err error) {
+	l.log("tx-commit", err)
+}
+
+func (l tagLogger) ConnRetry(_ string, _ time.Duration, _ int, _ time.Duration, err error) {
+	l.log("conn-retry", err)
+}
+
+func (l tagLogger) ConnectorConnect(_ context.Context, _ time.Duration, err error) {
+	l.log("connector-connect", err)
+}
+
+func (l tagLogger) ConnPrepareFallback(_ context.Context, _ time.Duration, _ string, err error) {
+	l.log("conn-prepare-fallback", err)
+}
+
+func (l tagLogger) ConnRaw() {
+	l.log("conn-raw", nil)
+}
+
+func (l tagLogger) ConnExplain(_ context.Context, _ time.Duration, _ string, _ []string, err error) {
+	l.log("conn-explain", err)
+}
+
+func (l tagLogger) StmtCloseTotal(_, _ time.Duration, err error) {
+	l.log("stmt-close-total", err)
+}
+
+func (l tagLogger) RowsNextRow(_ time.Duration, _ int, _ []string, _ []driver.Value, err error) {
+	l.log("rows-next-row", err)
+}
+
+func (l tagLogger) log(topic string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.logs = append(*l.logs, fmt.Sprintf("%s%s error: %v", topic, l.tags, err))
+}
+
+func (l tagLogger) records() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), *l.logs...)
+}
+
+var forwardTests = []struct {
+	name     string
+	line     string
+	decorate func(inner Logger) Logger
+	timing   bool   // decorator needs the timing regardless of the inner logger
+	tags     string // tags of the inner logger added by the decorator
+	noRaw    bool   // decorator drops the raw escape
+}{
+	{
+		name:     "route",
+		line:     line(),
+		decorate: func(inner Logger) Logger { return RouteLogger(map[string]Logger{"tx-commit": inner}, inner) },
+	},
+	{
+		name:     "context",
+		line:     line(),
+		decorate: func(inner Logger) Logger { return ContextLoggerFunc(func(context.Context) Logger { return inner }) },
+	},
+	{
+		name:     "errors only",
+		line:     line(),
+		decorate: ErrorsOnly,
+		noRaw:    true,
+	},
+	{
+		name:     "collapse errors",
+		line:     line(),
+		decorate: func(inner Logger) Logger { return CollapseErrors(inner, time.Minute) },
+	},
+	{
+		name:     "audit",
+		line:     line(),
+		decorate: func(inner Logger) Logger { return AuditLogger(inner, io.Discard) },
+	},
+	{
+		name:     "record",
+		line:     line(),
+		decorate: func(inner Logger) Logger { return newRecordLogger(inner, &stats{}) },
+	},
+	{
+		name:     "histogram",
+		line:     line(),
+		decorate: func(inner Logger) Logger { return Histogram(inner) },
+		timing:   true,
+	},
+	{
+		name:     "ping latency",
+		line:     line(),
+		decorate: func(inner Logger) Logger { return PingLatency(inner) },
+	},
+	{
+		name:     "async",
+		line:     line(),
+		decorate: func(inner Logger) Logger { return AsyncLogger(inner, 1, 16, nil) },
+	},
+	{
+		name: "host info",
+		line: line(),
+		decorate: func(inner Logger) Logger {
+			l, err := HostInfo(inner)
+			if err != nil {
+				panic(err)
+			}
+			return l
+		},
+		tags: fmt.Sprintf(" host=%s pid=%d", resolveHostname(os.Hostname), os.Getpid()),
+	},
+}
+
+func TestForwardOptionalInterfaces(t *testing.T) {
+	for _, tt := range forwardTests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			for _, timing := range []bool{false, true} {
+				l := tt.decorate(newTagLogger(timing))
+
+				tl, ok := l.(TimingLogger)
+				if !ok || tl.NeedsTiming() != (tt.timing || timing) {
+					t.Errorf("unexpected timing of the decorator, expected: %t, recieved: %t %t %s", tt.timing || timing, ok, ok && tl.NeedsTiming(), tt.line)
+				}
+			}
+
+			inner := newTagLogger(false)
+			l := tt.decorate(inner)
+
+			for _, tag := range []func(Logger) Logger{
+				func(l Logger) Logger { return l.(RoleLogger).WithRole("replica") },
+				func(l Logger) Logger { return l.(CorrelationLogger).WithCorrelation(7) },
+				func(l Logger) Logger { return l.(DriverVersionLogger).WithDriverVersion("1.2.3") },
+				func(l Logger) Logger { return l.(HostInfoLogger).WithHostInfo("db1", 42) },
+				func(l Logger) Logger { return l.(StmtLeakLogger).WithStmtLeak(3) },
+			} {
+				l = tag(l)
+			}
+
+			ctx := context.Background()
+			l.TxCommit(42, errors.New("commit failed"))
+			l.(RetryLogger).ConnRetry("dsn", 42, 2, time.Millisecond, errors.New("bad connection"))
+			l.(ConnectorLogger).ConnectorConnect(ctx, 42, errors.New("connection refused"))
+			l.(PrepareFallbackLogger).ConnPrepareFallback(ctx, 42, "SELECT 1", errors.New("prepare failed"))
+			l.(RawLogger).ConnRaw()
+			l.(ExplainLogger).ConnExplain(ctx, 42, "SELECT 1", nil, errors.New("explain failed"))
+			l.(StmtTotalLogger).StmtCloseTotal(42, 42, errors.New("close failed"))
+			l.(RowLogger).RowsNextRow(42, 1, nil, nil, errors.New("next failed"))
+
+			if c, ok := l.(io.Closer); ok {
+				c.Close()
+			}
+
+			tags := tt.tags + " role=replica correlation=7 version=1.2.3 host=db1 pid=42 stmt-leak=3"
+			expected := []string{
+				"tx-commit" + tags + " error: commit failed",
+				"conn-retry" + tags + " error: bad connection",
+				"connector-connect" + tags + " error: connection refused",
+				"conn-prepare-fallback" + tags + " error: prepare failed",
+			}
+			if !tt.noRaw {
+				expected = append(expected, "conn-raw"+tags+" error: <nil>")
+			}
+			expected = append(expected,
+				"conn-explain"+tags+" error: explain failed",
+				"stmt-close-total"+tags+" error: close failed",
+				"rows-next-row"+tags+" error: next failed",
+			)
+
+			if logs := inner.records(); !reflect.DeepEqual(logs, expected) {
+				t.Errorf("unexpected logs of the inner logger, expected: %q, recieved: %q %s", expected, logs, tt.line)
+			}
+		})
+	}
+}
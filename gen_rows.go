@@ -0,0 +1,100 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+// gen_rows generates rows_gen.go with the rowsIterator variants
+// for all combinations of the optional interfaces of the driver.Rows.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// optional is an optional interface of the driver.Rows
+// and its wrapper from rows.go.
+type optional struct {
+	iface   string // interface of the database/sql/driver package
+	wrapper string // wrapper type
+	field   string // field of the wrapper type
+}
+
+var optionals = []optional{
+	{iface: "RowsNextResultSet", wrapper: "rowsNextResultSet", field: "nextResultSet"},
+	{iface: "RowsColumnTypeScanType", wrapper: "rowsColumnTypeScanType", field: "scanType"},
+	{iface: "RowsColumnTypeDatabaseTypeName", wrapper: "rowsColumnTypeDatabaseTypeName", field: "databaseTypeName"},
+	{iface: "RowsColumnTypeLength", wrapper: "rowsColumnTypeLength", field: "length"},
+	{iface: "RowsColumnTypeNullable", wrapper: "rowsColumnTypeNullable", field: "nullable"},
+	{iface: "RowsColumnTypePrecisionScale", wrapper: "rowsColumnTypePrecisionScale", field: "precisionScale"},
+}
+
+func main() {
+	var b bytes.Buffer
+
+	b.WriteString(`// Code generated by gen_rows.go; DO NOT EDIT.
+
+package sqltee
+
+import "database/sql/driver"
+
+`)
+
+	for mask := 1; mask < 1<<len(optionals); mask++ {
+		var ifaces []string
+		for i, o := range optionals {
+			if mask&(1<<i) != 0 {
+				ifaces = append(ifaces, "driver."+o.iface)
+			}
+		}
+
+		fmt.Fprintf(&b, "// rowsIterator%d implements %s.\n", mask, strings.Join(ifaces, ", "))
+		fmt.Fprintf(&b, "type rowsIterator%d struct {\n\trowsIterator\n", mask)
+		for i, o := range optionals {
+			if mask&(1<<i) != 0 {
+				fmt.Fprintf(&b, "\t%s\n", o.wrapper)
+			}
+		}
+		b.WriteString("}\n\n")
+	}
+
+	b.WriteString(`// newRows returns the rows iterator wrapped into the variant
+// which implements exactly the optional interfaces of the rows of the driver.
+func newRows(r rowsIterator) driver.Rows {
+	r.row = new(int)
+	r.cols = new([]string)
+
+	var mask int
+
+`)
+	for i, o := range optionals {
+		fmt.Fprintf(&b, "\t%s, ok := r.rows.(driver.%s)\n\tif ok {\n\t\tmask |= %d\n\t}\n\n", o.field, o.iface, 1<<i)
+	}
+	b.WriteString("\tswitch mask {\n")
+	for mask := 1; mask < 1<<len(optionals); mask++ {
+		fmt.Fprintf(&b, "\tcase %d:\n\t\treturn rowsIterator%d{rowsIterator: r", mask, mask)
+		for i, o := range optionals {
+			if mask&(1<<i) != 0 {
+				fmt.Fprintf(&b, ", %s: %s{%s: %s}", o.wrapper, o.wrapper, o.field, o.field)
+			}
+		}
+		b.WriteString("}\n")
+	}
+	b.WriteString("\t}\n\n\treturn r\n}\n")
+
+	p, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = ioutil.WriteFile("rows_gen.go", p, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
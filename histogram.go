@@ -0,0 +1,194 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql/driver"
+	"errors"
+	"math"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Each power of two range of the durations above the histogramLinear
+// nanoseconds is divided into the histogramSubBuckets linear buckets,
+// durations below the histogramLinear are counted exactly.
+const (
+	histogramSubBits    = 3
+	histogramSubBuckets = 1 << histogramSubBits
+	histogramLinear     = 2 * histogramSubBuckets
+	histogramBuckets    = histogramLinear + (63-histogramSubBits-1)*histogramSubBuckets
+)
+
+// HistogramLogger is a Logger which records the durations of the operations
+// into the per topic histograms (for example for the debug endpoints)
+// and passes all logs through to the underlying logger.
+//
+// Histogram buckets are log-linear (HDR-style): each power of two range
+// of nanoseconds is divided into eight linear buckets,
+// so the relative error of the percentiles is below 12.5%.
+// Skipped operations (driver.ErrSkip) are not recorded.
+type HistogramLogger struct {
+	recordLogger
+	histograms *histograms
+}
+
+// Histogram returns a logger which decorates the logger
+// by the per topic histograms of the durations.
+// The optional tagging interfaces (for example RoleLogger)
+// tag the logger and the tagged loggers share the histograms.
+func Histogram(logger Logger) *HistogramLogger {
+	return newHistogramLogger(logger, &histograms{})
+}
+
+func newHistogramLogger(logger Logger, h *histograms) *HistogramLogger {
+	l := &HistogramLogger{histograms: h}
+	l.recordLogger = recordLogger{recorder: h}
+	l.forwarder = forward(logger, func(inner Logger) Logger { return newHistogramLogger(inner, h) })
+	return l
+}
+
+// NeedsTiming returns true because the histograms record the durations
+// even if the underlying logger opts out of the timing (see TimingLogger).
+func (l *HistogramLogger) NeedsTiming() bool { return true }
+
+// Percentile returns the upper bound of the bucket of the histogram
+// of the topic which contains the percentile p (from 0 to 100)
+// of the durations or zero if there are no durations of the topic.
+func (l *HistogramLogger) Percentile(topic string, p float64) time.Duration {
+	l.histograms.mu.RLock()
+	h, ok := l.histograms.topics[topic]
+	l.histograms.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+	return h.snapshot().Percentile(p)
+}
+
+// Snapshot returns the histograms of the durations per topic.
+func (l *HistogramLogger) Snapshot() map[string]TopicHistogram {
+	l.histograms.mu.RLock()
+	defer l.histograms.mu.RUnlock()
+
+	m := make(map[string]TopicHistogram, len(l.histograms.topics))
+	for topic, h := range l.histograms.topics {
+		m[topic] = h.snapshot()
+	}
+	return m
+}
+
+// TopicHistogram is a histogram of the durations of the operations of the topic.
+type TopicHistogram struct {
+	Count   int64             // number of the operations
+	Buckets []HistogramBucket // non-empty buckets in ascending order
+}
+
+// HistogramBucket is a number of the durations
+// which are less than or equal to the Le and greater than
+// the upper bound of the previous bucket.
+type HistogramBucket struct {
+	Le    time.Duration // upper bound of the bucket
+	Count int64         // number of the durations of the bucket
+}
+
+// Percentile returns the upper bound of the bucket which contains
+// the percentile p (from 0 to 100) of the durations
+// or zero if the histogram is empty.
+func (h TopicHistogram) Percentile(p float64) time.Duration {
+	if h.Count == 0 || len(h.Buckets) == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(h.Count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var n int64
+	for _, b := range h.Buckets {
+		n += b.Count
+		if n >= rank {
+			return b.Le
+		}
+	}
+	return h.Buckets[len(h.Buckets)-1].Le
+}
+
+// histograms is a set of the histograms of the topics.
+type histograms struct {
+	mu     sync.RWMutex
+	topics map[string]*histogram
+}
+
+type histogram struct {
+	buckets [histogramBuckets]int64
+}
+
+func (s *histograms) record(topic string, d time.Duration, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	s.mu.RLock()
+	h, ok := s.topics[topic]
+	s.mu.RUnlock()
+	if !ok {
+		s.mu.Lock()
+		if h, ok = s.topics[topic]; !ok {
+			if s.topics == nil {
+				s.topics = make(map[string]*histogram)
+			}
+			h = new(histogram)
+			s.topics[topic] = h
+		}
+		s.mu.Unlock()
+	}
+
+	atomic.AddInt64(&h.buckets[histogramBucket(d)], 1)
+}
+
+func (h *histogram) snapshot() TopicHistogram {
+	var t TopicHistogram
+	for i := range h.buckets {
+		n := atomic.LoadInt64(&h.buckets[i])
+		if n == 0 {
+			continue
+		}
+		t.Count += n
+		t.Buckets = append(t.Buckets, HistogramBucket{Le: histogramBound(i), Count: n})
+	}
+	return t
+}
+
+// histogramBucket returns the index of the bucket of the duration.
+func histogramBucket(d time.Duration) int {
+	if d < histogramLinear {
+		if d < 0 {
+			return 0
+		}
+		return int(d)
+	}
+	exp := bits.Len64(uint64(d)) - 1
+	sub := int(uint64(d)>>(exp-histogramSubBits)) & (histogramSubBuckets - 1)
+	return histogramLinear + (exp-histogramSubBits-1)*histogramSubBuckets + sub
+}
+
+// histogramBound returns the upper bound of the bucket.
+func histogramBound(i int) time.Duration {
+	if i < histogramLinear {
+		return time.Duration(i)
+	}
+	i -= histogramLinear
+	exp := i/histogramSubBuckets + histogramSubBits + 1
+	sub := i % histogramSubBuckets
+	width := uint64(1) << (exp - histogramSubBits)
+	upper := uint64(1)<<exp + uint64(sub+1)*width - 1
+	if upper > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(upper)
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestHistogramPercentile(t *testing.T) {
+	var topics []string
+	l := Histogram(EventLogger{Callback: func(e Event) { topics = append(topics, e.Topic) }})
+
+	if l.Percentile("conn-ping", 50) != 0 {
+		t.Fatalf("unexpected initial percentile, expected: 0, recieved: %s", l.Percentile("conn-ping", 50))
+	}
+
+	// 98 fast pings, one slow ping and one failed very slow ping
+	for i := 0; i < 98; i++ {
+		l.ConnPing(context.Background(), time.Millisecond, nil)
+	}
+	l.ConnPing(context.Background(), 100*time.Millisecond, nil)
+	l.ConnPing(context.Background(), time.Second, errors.New("bad connection"))
+	l.ConnExecContext(context.Background(), time.Hour, "", nil, nil, driver.ErrSkip)
+
+	var tests = []struct {
+		name string
+		line string
+		p    float64
+		want time.Duration
+	}{
+		{name: "p0", line: line(), p: 0, want: time.Millisecond},
+		{name: "p50", line: line(), p: 50, want: time.Millisecond},
+		{name: "p98", line: line(), p: 98, want: time.Millisecond},
+		{name: "p99", line: line(), p: 99, want: 100 * time.Millisecond},
+		{name: "p100", line: line(), p: 100, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			p := l.Percentile("conn-ping", tt.p)
+			if p < tt.want || p > tt.want+tt.want/8 {
+				t.Errorf("unexpected percentile, expected: bucket of %s, recieved: %s %s", tt.want, p, tt.line)
+			}
+		})
+	}
+
+	snap := l.Snapshot()
+	if len(snap) != 1 || snap["conn-ping"].Count != 100 || len(snap["conn-ping"].Buckets) != 3 {
+		t.Errorf("unexpected snapshot, expected: 100 conn-ping in 3 buckets, recieved: %+v", snap)
+	}
+
+	if len(topics) != 101 {
+		t.Errorf("unexpected logs, expected: 101, recieved: %d", len(topics))
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	var prev time.Duration = -1
+	for i := 0; i < histogramBuckets; i++ {
+		bound := histogramBound(i)
+		if bound <= prev {
+			t.Fatalf("unexpected bound of bucket %d, expected: greater than %d, recieved: %d", i, prev, bound)
+		}
+		if histogramBucket(bound) != i || histogramBucket(prev+1) != i {
+			t.Fatalf("unexpected bucket of bounds %d %d, expected: %d, recieved: %d %d", prev+1, bound, i, histogramBucket(prev+1), histogramBucket(bound))
+		}
+		prev = bound
+	}
+}
+
+func TestHistogramConcurrency(t *testing.T) {
+	l := Histogram(NopLogger{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= 1000; j++ {
+				l.StmtQueryContext(context.Background(), time.Duration(j)*time.Microsecond, "", nil, nil)
+				_ = l.Percentile("stmt-query-context", 99)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := l.Snapshot()["stmt-query-context"].Count; n != 8000 {
+		t.Errorf("unexpected count, expected: 8000, recieved: %d", n)
+	}
+
+	p50 := l.Percentile("stmt-query-context", 50)
+	if p50 < 500*time.Microsecond || p50 > 500*time.Microsecond+500*time.Microsecond/8 {
+		t.Errorf("unexpected p50, expected: bucket of %s, recieved: %s", 500*time.Microsecond, p50)
+	}
+
+	p99 := l.Percentile("stmt-query-context", 99)
+	if p99 < 990*time.Microsecond || p99 > 990*time.Microsecond+990*time.Microsecond/8 {
+		t.Errorf("unexpected p99, expected: bucket of %s, recieved: %s", 990*time.Microsecond, p99)
+	}
+}
+
+func TestHistogramDriver(t *testing.T) {
+	l := Histogram(EventLogger{Callback: func(Event) {}, NewTimer: func() Timer { return fakeTimer{} }})
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("TestHistogramDriver")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	if p := l.Percentile("stmt-exec-context", 50); p < 42 || p > 42+42/8 {
+		t.Errorf("unexpected percentile, expected: bucket of 42ns, recieved: %s", p)
+	}
+}
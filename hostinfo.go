@@ -0,0 +1,77 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// HostInfoLogger may be implemented by the Logger to tag the logs
+// by the hostname and the process id (see HostInfo), so the logs
+// of the many instances are distinguished after the aggregation.
+type HostInfoLogger interface {
+	WithHostInfo(host string, pid int) Logger
+}
+
+var (
+	hostInfoOnce sync.Once
+	host         string
+	pid          int
+)
+
+// ErrHostInfoUnsupported is wrapped by the error of the HostInfo
+// of the logger which does not tag the logs by the host info.
+var ErrHostInfoUnsupported = errors.New("sqltee: host info unsupported")
+
+// HostInfo returns a copy of the inner logger which tags the logs
+// by the hostname and the process id or the error which wraps
+// ErrHostInfoUnsupported if the inner logger does not implement
+// HostInfoLogger, so the logs are never silently left untagged.
+// The hostname and the process id are resolved once per process,
+// the hostname is unknown if the resolution fails.
+//
+// The EventLogger implements HostInfoLogger and the decorators
+// of this package (for example ErrorsOnly or AsyncLogger) support
+// the host info if the loggers they decorate support it, so the custom
+// logger should implement HostInfoLogger or log through the EventLogger.
+func HostInfo(inner Logger) (Logger, error) {
+	if !hostInfoSupported(inner) {
+		return nil, fmt.Errorf("%w: logger %T does not implement sqltee.HostInfoLogger", ErrHostInfoUnsupported, inner)
+	}
+
+	hostInfoOnce.Do(func() {
+		host = resolveHostname(os.Hostname)
+		pid = os.Getpid()
+	})
+
+	return inner.(HostInfoLogger).WithHostInfo(host, pid), nil
+}
+
+// hostInfoSupported reports whether the logger implements HostInfoLogger,
+// the decorator of this package implements it if its inner logger does.
+func hostInfoSupported(l Logger) bool {
+	for {
+		if _, ok := l.(HostInfoLogger); !ok {
+			return false
+		}
+		d, ok := l.(interface{ inner() Logger })
+		if !ok {
+			return true
+		}
+		l = d.inner()
+	}
+}
+
+// resolveHostname returns the hostname or unknown if the resolution fails.
+func resolveHostname(hostname func() (string, error)) string {
+	name, err := hostname()
+	if err != nil || name == "" {
+		return "unknown"
+	}
+	return name
+}
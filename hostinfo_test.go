@@ -0,0 +1,143 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestHostInfo(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+
+	l, err := HostInfo(EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	})
+	if err != nil {
+		t.Fatalf("host info error: %#v", err)
+	}
+
+	drv := &Driver{Driver: fakedb.Driver, Logger: l, Role: "primary"}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_host_info")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+
+	_, err = db.Exec("CREATE|host_info|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	db.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(events) < 2 {
+		t.Fatalf("unexpected number of the events, expected: more than 1, recieved: %d", len(events))
+	}
+
+	expected := resolveHostname(os.Hostname)
+	for _, e := range events {
+		if e.Host != expected {
+			t.Errorf("unexpected host of the %s, expected: %q, recieved: %q", e.Topic, expected, e.Host)
+		}
+		if e.PID != os.Getpid() {
+			t.Errorf("unexpected pid of the %s, expected: %d, recieved: %d", e.Topic, os.Getpid(), e.PID)
+		}
+		if e.Role != "primary" {
+			t.Errorf("unexpected role of the %s, expected: primary, recieved: %q", e.Topic, e.Role)
+		}
+	}
+}
+
+func TestHostInfoDecorator(t *testing.T) {
+	var events []Event
+
+	l, err := HostInfo(ErrorsOnly(EventLogger{Callback: func(e Event) { events = append(events, e) }}))
+	if err != nil {
+		t.Fatalf("host info error: %#v", err)
+	}
+
+	l.TxCommit(42, errors.New("commit failed"))
+
+	if len(events) != 1 {
+		t.Fatalf("unexpected number of the events, expected: %d, recieved: %d", 1, len(events))
+	}
+
+	expected := resolveHostname(os.Hostname)
+	if events[0].Host != expected || events[0].PID != os.Getpid() {
+		t.Errorf("unexpected host info, expected: %q %d, recieved: %q %d", expected, os.Getpid(), events[0].Host, events[0].PID)
+	}
+}
+
+var hostInfoUnsupportedTests = []struct {
+	name  string
+	line  string
+	inner Logger
+}{
+	{
+		name:  "nop logger",
+		line:  line(),
+		inner: NopLogger{},
+	},
+	{
+		name:  "decorator of nop logger",
+		line:  line(),
+		inner: ErrorsOnly(NopLogger{}),
+	},
+	{
+		name:  "decorators of nop logger",
+		line:  line(),
+		inner: CollapseErrors(ErrorsOnly(NopLogger{}), time.Minute),
+	},
+}
+
+func TestHostInfoUnsupportedLogger(t *testing.T) {
+	for _, tt := range hostInfoUnsupportedTests {
+		tt := tt
+
+		t.Run(tt.line+"/"+tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := HostInfo(tt.inner)
+			if !errors.Is(err, ErrHostInfoUnsupported) {
+				t.Errorf("unexpected error, expected: %v, recieved: %v", ErrHostInfoUnsupported, err)
+			}
+			if l != nil {
+				t.Errorf("unexpected logger, expected: nil, recieved: %T", l)
+			}
+		})
+	}
+}
+
+func TestResolveHostname(t *testing.T) {
+	name := resolveHostname(func() (string, error) { return "", errors.New("no hostname") })
+	if name != "unknown" {
+		t.Errorf("unexpected hostname, expected: unknown, recieved: %q", name)
+	}
+
+	name = resolveHostname(func() (string, error) { return "db-worker-3", nil })
+	if name != "db-worker-3" {
+		t.Errorf("unexpected hostname, expected: db-worker-3, recieved: %q", name)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type implicitCommitKey struct{}
+
+// ImplicitCommit returns true if the operation is the DDL statement
+// (CREATE, ALTER, DROP or TRUNCATE) executed while the transaction
+// of the connection is active, so the loggers may warn that some
+// databases (for example MySQL) implicitly commit the transaction
+// before the DDL statement.
+func ImplicitCommit(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	commit, _ := ctx.Value(implicitCommitKey{}).(bool)
+	return commit
+}
+
+// withImplicitCommit returns a copy of the parent context which
+// flags the implicit commit or the parent context as is if
+// the connection is not in the transaction or the query is not a DDL.
+func (c connection) withImplicitCommit(ctx context.Context, query string) context.Context {
+	if c.inTx == nil || atomic.LoadInt32(c.inTx) == 0 || !isDDL(query) {
+		return ctx
+	}
+
+	return context.WithValue(ctx, implicitCommitKey{}, true)
+}
+
+// isDDL returns true if the first keyword of the query
+// after the leading comments is CREATE, ALTER, DROP or TRUNCATE.
+func isDDL(query string) bool {
+	switch queryVerb(query) {
+	case "CREATE", "ALTER", "DROP", "TRUNCATE":
+		return true
+	}
+	return false
+}
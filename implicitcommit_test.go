@@ -0,0 +1,102 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestIsDDL(t *testing.T) {
+	var tests = []struct {
+		query    string
+		expected bool
+	}{
+		{query: "CREATE TABLE foo (id int)", expected: true},
+		{query: "  alter table foo ADD bar int", expected: true},
+		{query: "-- cleanup\nDROP TABLE foo", expected: true},
+		{query: "/* app */ TRUNCATE foo", expected: true},
+		{query: "INSERT INTO foo VALUES (1)", expected: false},
+		{query: "CREATED", expected: false},
+		{query: "/* DROP */ SELECT 1", expected: false},
+		{query: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		if ddl := isDDL(tt.query); ddl != tt.expected {
+			t.Errorf("unexpected DDL detection of %q, expected: %t, recieved: %t", tt.query, tt.expected, ddl)
+		}
+	}
+}
+
+func TestImplicitCommit(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		commits []string
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			if ImplicitCommit(e.Ctx) && e.Err != driver.ErrSkip {
+				mu.Lock()
+				defer mu.Unlock()
+				commits = append(commits, e.Topic+" "+e.Query)
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_implicit_commit")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE|t1|id=int64`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("db begin error: %#v", err)
+	}
+
+	_, err = tx.Exec("INSERT|t1|id=?", 1)
+	if err != nil {
+		t.Fatalf("tx exec error: %#v", err)
+	}
+
+	_, err = tx.Exec("CREATE|t2|id=int64")
+	if err != nil {
+		t.Fatalf("tx exec error: %#v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf("tx commit error: %#v", err)
+	}
+
+	_, err = db.Exec("CREATE|t3|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(commits) != 1 || commits[0] != "stmt-exec-context CREATE|t2|id=int64" {
+		t.Errorf("unexpected implicit commits, expected: [stmt-exec-context CREATE|t2|id=int64], recieved: %q", commits)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql/driver"
+	"strconv"
+)
+
+// isolationNames are the names of the isolation levels indexed
+// by the sql.IsolationLevel values.
+var isolationNames = [...]string{
+	"Default",
+	"Read Uncommitted",
+	"Read Committed",
+	"Write Committed",
+	"Repeatable Read",
+	"Snapshot",
+	"Serializable",
+	"Linearizable",
+}
+
+// IsolationName returns the readable name of the transaction isolation level
+// (for example Read Committed) the same as sql.IsolationLevel.String
+// or IsolationLevel(N) if the level is out of the sql.IsolationLevel range.
+func IsolationName(level driver.IsolationLevel) string {
+	if level >= 0 && int(level) < len(isolationNames) {
+		return isolationNames[level]
+	}
+	return "IsolationLevel(" + strconv.Itoa(int(level)) + ")"
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+// txOptions returns the driver transaction options of the isolation level.
+func txOptions(level sql.IsolationLevel, readOnly bool) driver.TxOptions {
+	return driver.TxOptions{Isolation: driver.IsolationLevel(level), ReadOnly: readOnly}
+}
+
+func TestIsolationName(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		opts     driver.TxOptions
+		expected string
+	}{
+		{name: "default", line: line(), opts: txOptions(sql.LevelDefault, false), expected: "Default"},
+		{name: "read uncommitted", line: line(), opts: txOptions(sql.LevelReadUncommitted, false), expected: "Read Uncommitted"},
+		{name: "read committed", line: line(), opts: txOptions(sql.LevelReadCommitted, false), expected: "Read Committed"},
+		{name: "write committed", line: line(), opts: txOptions(sql.LevelWriteCommitted, false), expected: "Write Committed"},
+		{name: "repeatable read", line: line(), opts: txOptions(sql.LevelRepeatableRead, true), expected: "Repeatable Read"},
+		{name: "snapshot", line: line(), opts: txOptions(sql.LevelSnapshot, false), expected: "Snapshot"},
+		{name: "serializable", line: line(), opts: txOptions(sql.LevelSerializable, true), expected: "Serializable"},
+		{name: "linearizable", line: line(), opts: txOptions(sql.LevelLinearizable, false), expected: "Linearizable"},
+		{name: "out of range", line: line(), opts: txOptions(8, false), expected: "IsolationLevel(8)"},
+		{name: "negative", line: line(), opts: txOptions(-1, false), expected: "IsolationLevel(-1)"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			name := IsolationName(tt.opts.Isolation)
+			if name != tt.expected {
+				t.Errorf("unexpected isolation name, expected: %q, recieved: %q %s", tt.expected, name, tt.line)
+			}
+
+			if level := sql.IsolationLevel(tt.opts.Isolation); name != level.String() {
+				t.Errorf("unexpected isolation name, expected the same as sql: %q, recieved: %q %s", level.String(), name, tt.line)
+			}
+		})
+	}
+}
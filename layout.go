@@ -0,0 +1,36 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+type queryLayoutKey struct{}
+
+// QueryLayout returns the placeholder layout of the prepared statement
+// which is reused by the interpolations of the subsequent executions
+// of the statement and true or false if the operation is not
+// the execution of the prepared statement. The statement is used
+// by one goroutine at a time so the layout is used without locking.
+func QueryLayout(ctx context.Context) (*sqlteescan.Layout, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	layout, ok := ctx.Value(queryLayoutKey{}).(*sqlteescan.Layout)
+	return layout, ok
+}
+
+// withQueryLayout returns a copy of the parent context which stores the layout.
+func withQueryLayout(ctx context.Context, layout *sqlteescan.Layout) context.Context {
+	if layout == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, queryLayoutKey{}, layout)
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestQueryLayout(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		layouts = map[string][]*sqlteescan.Layout{}
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			layout, _ := QueryLayout(e.Ctx)
+			layouts[e.Topic] = append(layouts[e.Topic], layout)
+		},
+		Placeholder: "?",
+		NewTimer:    func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_query_layout")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE|tbl|id=int64`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	stmt, err := db.Prepare("INSERT|tbl|id=?")
+	if err != nil {
+		t.Fatalf("db prepare error: %#v", err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err = stmt.Exec(i)
+		if err != nil {
+			t.Fatalf("stmt exec error: %#v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	execs := layouts["stmt-exec-context"]
+	if len(execs) != 4 {
+		t.Fatalf("unexpected number of the stmt-exec-context events, expected: %d, recieved: %d", 4, len(execs))
+	}
+
+	for i, layout := range execs[1:] {
+		if layout == nil || layout != execs[1] {
+			t.Errorf("unexpected layout of the execution %d, expected: %p, recieved: %p", i+1, execs[1], layout)
+		}
+	}
+
+	if execs[0] == execs[1] {
+		t.Errorf("unexpected layout shared by the different statements: %p", execs[0])
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// logfmtLogger is an EventLogger which writes the events
+// as the logfmt lines to the writer.
+type logfmtLogger struct {
+	EventLogger
+	mu    sync.Mutex
+	w     io.Writer
+	clock Clock
+}
+
+// LogfmtLogger returns a logger which writes each operation to the writer
+// as one greppable line of the timestamp and the logfmt key=value pairs
+// of the topic, the duration, the query with interpolated parameters
+// (or the query as is if nothing was substituted) and the error:
+//
+//	2024-01-02T15:04:05Z topic=conn-exec-context dur=42ns query="INSERT INTO foo VALUES (42)" err=-
+//
+// The values containing the spaces, the quotes, the equal signs
+// or the control characters are quoted by strconv.Quote,
+// the empty values are written as the - hyphen.
+// The skipped operations (driver.ErrSkip) are not written.
+func LogfmtLogger(w io.Writer) Logger {
+	l := &logfmtLogger{w: w, clock: realClock{}}
+	l.EventLogger = EventLogger{Callback: l.write}
+	return l
+}
+
+func (l *logfmtLogger) write(e Event) {
+	if errors.Is(e.Err, driver.ErrSkip) {
+		return
+	}
+
+	query := e.Interpolated
+	if query == "" {
+		query = e.Query
+	}
+
+	var msg string
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := make([]byte, 0, len(query)+len(msg)+96)
+	b = l.clock.Now().UTC().AppendFormat(b, time.RFC3339Nano)
+	b = appendLogfmt(b, "topic", e.Topic)
+	b = appendLogfmt(b, "dur", e.Duration.String())
+	b = appendLogfmt(b, "query", query)
+	b = appendLogfmt(b, "err", msg)
+	b = append(b, '\n')
+
+	l.w.Write(b)
+}
+
+// appendLogfmt appends the space separated key=value pair
+// of the value quoted if necessary or of the - hyphen if the value is empty.
+func appendLogfmt(b []byte, key, value string) []byte {
+	b = append(b, ' ')
+	b = append(b, key...)
+	b = append(b, '=')
+
+	switch {
+	case value == "":
+		return append(b, '-')
+
+	case needsQuote(value):
+		return strconv.AppendQuote(b, value)
+
+	default:
+		return append(b, value...)
+	}
+}
+
+// needsQuote returns true if the logfmt value contains the spaces,
+// the quotes, the backslashes, the equal signs, the control characters
+// or the invalid UTF-8.
+func needsQuote(s string) bool {
+	if !utf8.ValidString(s) {
+		return true
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '"' || r == '=' || r == '\\' || r == 0x7f
+	}) != -1
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+var logfmtTests = []struct {
+	name     string
+	line     string
+	log      func(Logger)
+	expected string
+}{
+	{
+		name: "query with spaces",
+		line: line(),
+		log: func(l Logger) {
+			l.ConnExecContext(context.Background(), 42, "INSERT INTO foo VALUES ($1)", []driver.NamedValue{{Ordinal: 1, Value: "bar"}}, nil, nil)
+		},
+		expected: `2021-01-02T03:04:05Z topic=conn-exec-context dur=42ns query="INSERT INTO foo VALUES ('bar')" err=-` + "\n",
+	},
+	{
+		name: "error with quotes",
+		line: line(),
+		log: func(l Logger) {
+			l.StmtQueryContext(context.Background(), 42, "SELECT|nonexistent|id|", nil, errors.New(`fakedb: table "nonexistent" doesn't exist`))
+		},
+		expected: `2021-01-02T03:04:05Z topic=stmt-query-context dur=42ns query=SELECT|nonexistent|id| err="fakedb: table \"nonexistent\" doesn't exist"` + "\n",
+	},
+	{
+		name: "empty fields",
+		line: line(),
+		log: func(l Logger) {
+			l.TxCommit(42, nil)
+		},
+		expected: "2021-01-02T03:04:05Z topic=tx-commit dur=42ns query=- err=-\n",
+	},
+	{
+		name: "query with equal sign and new line",
+		line: line(),
+		log: func(l Logger) {
+			l.ConnQuery(42, "SELECT *\nFROM foo WHERE id=1", nil, nil)
+		},
+		expected: `2021-01-02T03:04:05Z topic=conn-query dur=42ns query="SELECT *\nFROM foo WHERE id=1" err=-` + "\n",
+	},
+	{
+		name: "skipped operation",
+		line: line(),
+		log: func(l Logger) {
+			l.ConnExec(42, "UPDATE foo SET bar = 1", nil, nil, driver.ErrSkip)
+		},
+		expected: "",
+	},
+}
+
+func TestLogfmtLogger(t *testing.T) {
+	for _, tt := range logfmtTests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			l := LogfmtLogger(&buf)
+			l.(*logfmtLogger).clock = fakeClock{now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+			tt.log(l)
+
+			if buf.String() != tt.expected {
+				t.Errorf("unexpected line, expected: %q, recieved: %q %s", tt.expected, buf.String(), tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+// NopLogger is a Logger which discards all logs and does not measure
+// the time, for example to benchmark the overhead of the wrapper itself.
+type NopLogger struct{}
+
+func (NopLogger) DriverOpen(string, time.Duration, error) {}
+
+func (NopLogger) ConnPrepare(time.Duration, string, error) {}
+
+func (NopLogger) ConnClose(time.Duration, error) {}
+
+func (NopLogger) ConnBegin(time.Duration, error) {}
+
+func (NopLogger) ConnBeginTx(context.Context, time.Duration, driver.TxOptions, error) {}
+
+func (NopLogger) ConnPrepareContext(context.Context, time.Duration, string, error) {}
+
+func (NopLogger) ConnExec(time.Duration, string, []driver.Value, driver.Result, error) {}
+
+func (NopLogger) ConnExecContext(context.Context, time.Duration, string, []driver.NamedValue, driver.Result, error) {
+}
+
+func (NopLogger) ConnPing(context.Context, time.Duration, error) {}
+
+func (NopLogger) ConnQuery(time.Duration, string, []driver.Value, error) {}
+
+func (NopLogger) ConnQueryContext(context.Context, time.Duration, string, []driver.NamedValue, error) {
+}
+
+func (NopLogger) StmtClose(time.Duration, error) {}
+
+func (NopLogger) StmtExec(time.Duration, string, []driver.Value, driver.Result, error) {}
+
+func (NopLogger) StmtExecContext(context.Context, time.Duration, string, []driver.NamedValue, driver.Result, error) {
+}
+
+func (NopLogger) StmtQuery(time.Duration, string, []driver.Value, error) {}
+
+func (NopLogger) StmtQueryContext(context.Context, time.Duration, string, []driver.NamedValue, error) {
+}
+
+func (NopLogger) RowsNext(time.Duration, []driver.Value, error) {}
+
+func (NopLogger) TxCommit(time.Duration, error) {}
+
+func (NopLogger) TxRollback(time.Duration, error) {}
+
+func (NopLogger) TxSavepoint(context.Context, time.Duration, string, string, string, error) {}
+
+func (NopLogger) Timer() Timer {
+	return nopTimer{}
+}
+
+// NeedsTiming returns false because the nop logger discards the durations.
+func (NopLogger) NeedsTiming() bool { return false }
+
+type nopTimer struct{}
+
+func (nopTimer) Stop() time.Duration { return 0 }
@@ -0,0 +1,112 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// dsnConnector is a connector of the driver without the wrapper.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.driver }
+
+// workload executes the insert, the select and the wipe.
+func workload(db *sql.DB) error {
+	_, err := db.Exec("CREATE|tbl|id=int64,name=string")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("INSERT|tbl|id=?,name=?", 42, "foo")
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query("SELECT|tbl|id|name=?", "foo")
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var id int64
+		err = rows.Scan(&id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = rows.Close()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("WIPE")
+	return err
+}
+
+// BenchmarkWorkload compares the identical workload through the driver
+// without the wrapper and through the wrapper with the nop logger,
+// so the difference is the overhead of the wrapper itself.
+func BenchmarkWorkload(b *testing.B) {
+	var benchmarks = []struct {
+		name      string
+		connector func(dsn string) (driver.Connector, error)
+	}{
+		{
+			name: "fakedb",
+			connector: func(dsn string) (driver.Connector, error) {
+				return dsnConnector{dsn: dsn, driver: fakedb.Driver}, nil
+			},
+		},
+		{
+			name: "sqltee nop logger",
+			connector: func(dsn string) (driver.Connector, error) {
+				return (&Driver{Driver: fakedb.Driver, Logger: NopLogger{}}).OpenConnector(dsn)
+			},
+		},
+		{
+			name: "sqltee event logger",
+			connector: func(dsn string) (driver.Connector, error) {
+				l := EventLogger{Callback: func(Event) {}, NewTimer: func() Timer { return nopTimer{} }}
+				return (&Driver{Driver: fakedb.Driver, Logger: l}).OpenConnector(dsn)
+			},
+		},
+	}
+
+	for i, bb := range benchmarks {
+		bb := bb
+		dsn := fmt.Sprintf("BenchmarkWorkload_%d", i)
+		b.Run(bb.name, func(b *testing.B) {
+			c, err := bb.connector(dsn)
+			if err != nil {
+				b.Fatalf("open connector error: %#v", err)
+			}
+
+			db := sql.OpenDB(c)
+			defer db.Close()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				err = workload(db)
+				if err != nil {
+					b.Fatalf("workload error: %#v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+type nPlusOneKey struct{}
+
+// NPlusOne returns the number of the executions of the same normalized
+// query (see sqlteescan.Normalize) under the same request context
+// within the window of the Driver.NPlusOneWindow and true if the number
+// reaches the Driver.NPlusOneThreshold, so the loggers may warn about
+// the likely N+1 query pattern (for example the query per each row
+// of the previous query).
+func NPlusOne(ctx context.Context) (int, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	n, ok := ctx.Value(nPlusOneKey{}).(int)
+	return n, ok
+}
+
+// defaultNPlusOneWindow is the window of the N+1 detection
+// if the Driver.NPlusOneWindow is not positive.
+const defaultNPlusOneWindow = time.Second
+
+// nPlusOne is the N+1 detection of the connection.
+type nPlusOne struct {
+	threshold int
+	window    time.Duration
+	counts    *nPlusOneCounts // shared by the connections of the driver
+}
+
+// nPlusOneCounts counts the executions per request context and normalized query.
+type nPlusOneCounts struct {
+	mu     sync.Mutex
+	counts map[nPlusOneQuery]*nPlusOneCount
+	swept  time.Time // time of the last removal of the expired counts
+}
+
+type nPlusOneQuery struct {
+	ctx   context.Context
+	query string
+}
+
+type nPlusOneCount struct {
+	start time.Time // time of the first execution within the window
+	n     int
+}
+
+// count counts the execution of the normalized query under
+// the request context at the time and returns the number
+// of the executions within the window.
+func (d *nPlusOneCounts) count(ctx context.Context, query string, now time.Time, window time.Duration) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.counts == nil {
+		d.counts = make(map[nPlusOneQuery]*nPlusOneCount)
+	}
+
+	// the contexts of the finished requests are not referenced
+	// after their counts expire
+	if now.Sub(d.swept) > window {
+		for k, c := range d.counts {
+			if now.Sub(c.start) > window {
+				delete(d.counts, k)
+			}
+		}
+		d.swept = now
+	}
+
+	k := nPlusOneQuery{ctx: ctx, query: query}
+	c, ok := d.counts[k]
+	if !ok || now.Sub(c.start) > window {
+		c = &nPlusOneCount{start: now}
+		d.counts[k] = c
+	}
+	c.n++
+
+	return c.n
+}
+
+// withNPlusOne counts the execution of the query under the request
+// context and returns a copy of the parent context which stores
+// the number of the executions or the parent context as is if
+// the detection is disabled, the number is below the threshold
+// or the driver skipped the operation (the operation is counted
+// by the fallback of the database/sql instead).
+func (c connection) withNPlusOne(ctx, rctx context.Context, query string, err error) context.Context {
+	if c.nPlusOne.threshold <= 0 || rctx == nil || errors.Is(err, driver.ErrSkip) {
+		return ctx
+	}
+
+	n := c.nPlusOne.counts.count(rctx, sqlteescan.Normalize(query), c.clock.Now(), c.nPlusOne.window)
+	if n < c.nPlusOne.threshold {
+		return ctx
+	}
+
+	return context.WithValue(ctx, nPlusOneKey{}, n)
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestNPlusOne(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		flagged []int
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			if n, ok := NPlusOne(e.Ctx); ok {
+				mu.Lock()
+				defer mu.Unlock()
+				flagged = append(flagged, n)
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l, NPlusOneThreshold: 50}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_n_plus_one")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE|n_plus_one|id=int64`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 1; i <= 50; i++ {
+		_, err = db.ExecContext(ctx, "INSERT|n_plus_one|id=?", i)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+	}
+
+	// the same query under the other request is counted separately
+	_, err = db.ExecContext(context.Background(), "INSERT|n_plus_one|id=?", 51)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.ExecContext(ctx, "INSERT|n_plus_one|id=?", 52)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := []int{50, 51}
+	if !reflect.DeepEqual(flagged, expected) {
+		t.Errorf("unexpected N+1 counts, expected: %v, recieved: %v", expected, flagged)
+	}
+}
+
+func TestNPlusOneCountsWindow(t *testing.T) {
+	var (
+		d     nPlusOneCounts
+		ctx   = context.Background()
+		start = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+
+	for i := 0; i < 3; i++ {
+		d.count(ctx, "SELECT ?", start.Add(time.Duration(i)*time.Millisecond), time.Second)
+	}
+
+	if n := d.count(ctx, "SELECT ?", start.Add(500*time.Millisecond), time.Second); n != 4 {
+		t.Errorf("unexpected count within the window, expected: 4, recieved: %d", n)
+	}
+
+	if n := d.count(ctx, "SELECT ?", start.Add(2*time.Second), time.Second); n != 1 {
+		t.Errorf("unexpected count after the window, expected: 1, recieved: %d", n)
+	}
+
+	if len(d.counts) != 1 {
+		t.Errorf("unexpected number of the counts, expected: 1, recieved: %d", len(d.counts))
+	}
+}
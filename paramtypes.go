@@ -0,0 +1,50 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// StmtParamTypes may be implemented by driver.Stmt
+// of the driver which reports the SQL types of the bind parameters
+// (for example the parameter descriptions of the PostgreSQL).
+// ParamTypes returns the types by the position of the parameters,
+// the blank type is the unknown type.
+type StmtParamTypes interface {
+	driver.Stmt
+	ParamTypes() []string
+}
+
+type paramTypesKey struct{}
+
+// ParamTypes returns the SQL types of the bind parameters
+// of the prepared statement and true or false if the driver
+// does not report the types.
+func ParamTypes(ctx context.Context) ([]string, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	types, ok := ctx.Value(paramTypesKey{}).([]string)
+	return types, ok
+}
+
+// withParamTypes returns a copy of the parent context which stores
+// the types of the parameters of the statement if the statement reports them.
+func withParamTypes(ctx context.Context, stmt driver.Stmt) context.Context {
+	s, ok := stmt.(StmtParamTypes)
+	if !ok {
+		return ctx
+	}
+
+	types := s.ParamTypes()
+	if len(types) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, paramTypesKey{}, types)
+}
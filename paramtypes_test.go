@@ -0,0 +1,121 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+// typedDriver is a driver which statements report
+// the SQL types of the bind parameters.
+type typedDriver struct{}
+
+func (typedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := fakedb.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return typedConn{Conn: conn}, nil
+}
+
+type typedConn struct {
+	driver.Conn
+}
+
+func (c typedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(query, "INSERT") {
+		return stmt, nil
+	}
+	return typedStmt{Stmt: stmt, types: []string{"int8", "text"}}, nil
+}
+
+func (c typedConn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+type typedStmt struct {
+	driver.Stmt
+	types []string
+}
+
+func (s typedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.Stmt.(driver.StmtExecContext).ExecContext(ctx, args)
+}
+
+func (s typedStmt) ParamTypes() []string {
+	return s.types
+}
+
+func TestParamTypes(t *testing.T) {
+	var (
+		mu             sync.Mutex
+		interpolations []string
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			types, ok := ParamTypes(e.Ctx)
+			if !ok || e.Topic != "stmt-exec-context" {
+				return
+			}
+
+			scan := sqlteescan.GetScanner()
+			defer sqlteescan.PutScanner(scan)
+			scan.NamedValues = e.Args
+			scan.Types = types
+
+			interpolation, err := scan.Interpolate(e.Query, "?")
+			if err != nil {
+				t.Errorf("interpolate error: %#v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			interpolations = append(interpolations, interpolation)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: typedDriver{}, Logger: l}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_param_types")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	// the statement of the fake driver does not report the types
+	_, err = db.Exec("CREATE|param_types|id=int64,name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|param_types|id=?,name=?", 42, "foo")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := []string{"INSERT|param_types|id=42::int8,name='foo'::text"}
+	if !reflect.DeepEqual(interpolations, expected) {
+		t.Errorf("unexpected interpolations, expected: %q, recieved: %q", expected, interpolations)
+	}
+}
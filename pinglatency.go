@@ -0,0 +1,80 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pingLatencyWeight is a weight of the latest ping duration
+// in the exponentially weighted moving average of the ping latency.
+const pingLatencyWeight = 0.2
+
+// PingLatencyLogger is a Logger which maintains an exponentially weighted
+// moving average of the ping latency (for example for the health endpoints)
+// and passes all logs through to the underlying logger.
+type PingLatencyLogger struct {
+	forwarder
+	*pingLatency
+}
+
+// pingLatency is shared by the PingLatencyLogger
+// and its decorators of the tagged loggers.
+type pingLatency struct {
+	mu      sync.Mutex
+	latency float64
+	pings   int64
+	errors  int64
+}
+
+// PingLatency returns a logger which decorates the logger
+// by the ping latency average.
+// The optional tagging interfaces (for example RoleLogger)
+// tag the logger and the tagged loggers share the average.
+func PingLatency(logger Logger) *PingLatencyLogger {
+	return newPingLatencyLogger(logger, &pingLatency{})
+}
+
+func newPingLatencyLogger(logger Logger, p *pingLatency) *PingLatencyLogger {
+	l := &PingLatencyLogger{pingLatency: p}
+	l.forwarder = forward(logger, func(inner Logger) Logger { return newPingLatencyLogger(inner, p) })
+	return l
+}
+
+func (l *PingLatencyLogger) ConnPing(ctx context.Context, d time.Duration, err error) {
+	if err == ErrPingUnsupported {
+		l.Logger.ConnPing(ctx, d, err)
+		return
+	}
+
+	l.mu.Lock()
+	if err != nil {
+		l.errors++
+	} else if l.pings++; l.pings == 1 {
+		l.latency = float64(d)
+	} else {
+		l.latency += pingLatencyWeight * (float64(d) - l.latency)
+	}
+	l.mu.Unlock()
+
+	l.Logger.ConnPing(ctx, d, err)
+}
+
+// Latency returns the moving average of the successful ping durations
+// or zero if there were no successful pings.
+func (l *PingLatencyLogger) Latency() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return time.Duration(l.latency)
+}
+
+// Pings returns the numbers of the successful and the failed pings.
+func (l *PingLatencyLogger) Pings() (ok, failed int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pings, l.errors
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestPingLatency(t *testing.T) {
+	var topics []string
+	l := PingLatency(EventLogger{Callback: func(e Event) { topics = append(topics, e.Topic) }})
+
+	if l.Latency() != 0 {
+		t.Fatalf("unexpected initial latency, expected: 0, recieved: %s", l.Latency())
+	}
+
+	l.ConnPing(context.Background(), 100*time.Millisecond, nil)
+	if l.Latency() != 100*time.Millisecond {
+		t.Fatalf("unexpected first latency, expected: %s, recieved: %s", 100*time.Millisecond, l.Latency())
+	}
+
+	prev := l.Latency()
+	for i := 0; i < 30; i++ {
+		l.ConnPing(context.Background(), 10*time.Millisecond, nil)
+		if l.Latency() >= prev {
+			t.Fatalf("unexpected latency after ping %d, expected: less than %s, recieved: %s", i, prev, l.Latency())
+		}
+		prev = l.Latency()
+	}
+
+	if l.Latency() > 11*time.Millisecond || l.Latency() < 10*time.Millisecond {
+		t.Errorf("unexpected latency, expected: about %s, recieved: %s", 10*time.Millisecond, l.Latency())
+	}
+
+	l.ConnPing(context.Background(), time.Second, errors.New("bad connection"))
+	if l.Latency() != prev {
+		t.Errorf("unexpected latency after failed ping, expected: %s, recieved: %s", prev, l.Latency())
+	}
+
+	ok, failed := l.Pings()
+	if ok != 31 || failed != 1 {
+		t.Errorf("unexpected pings, expected: 31 1, recieved: %d %d", ok, failed)
+	}
+
+	if len(topics) != 32 || topics[0] != "conn-ping" {
+		t.Errorf("unexpected logs, expected: 32 conn-ping, recieved: %v", topics)
+	}
+}
+
+// pingDriver is a driver which connections implement driver.Pinger.
+type pingDriver struct{ driver.Driver }
+
+func (d pingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return pingConn{conn}, nil
+}
+
+type pingConn struct{ driver.Conn }
+
+func (pingConn) Ping(context.Context) error { return nil }
+
+func TestPingLatencyDriver(t *testing.T) {
+	l := PingLatency(EventLogger{Callback: func(Event) {}, NewTimer: func() Timer { return fakeTimer{} }})
+	drv := &Driver{Driver: pingDriver{fakedb.Driver}, Logger: l}
+
+	c, err := drv.OpenConnector("TestPingLatencyDriver")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	err = db.Ping()
+	if err != nil {
+		t.Fatalf("db ping error: %#v", err)
+	}
+
+	if l.Latency() != 42 {
+		t.Errorf("unexpected latency, expected: 42ns, recieved: %s", l.Latency())
+	}
+}
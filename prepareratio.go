@@ -0,0 +1,76 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+)
+
+type prepareRatioKey struct{}
+
+// PrepareRatio returns the number of the prepares per execution
+// or query of the driver since the driver is created and true
+// if the operation is each Driver.PrepareRatioEvery-th execution
+// or query. The database/sql prepares the statement per execution
+// unless the statement is reused (for example by the *sql.Stmt),
+// so the ratio well below one means the statements are reused.
+func PrepareRatio(ctx context.Context) (float64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	ratio, ok := ctx.Value(prepareRatioKey{}).(float64)
+	return ratio, ok
+}
+
+// prepareRatio is the accounting of the prepares per execution of the connection.
+type prepareRatio struct {
+	every  int
+	counts *prepareCounts // shared by the connections of the driver
+}
+
+// prepareCounts counts the prepares and the executions of the driver.
+type prepareCounts struct {
+	mu         sync.Mutex
+	prepares   int
+	executions int
+}
+
+// prepare counts the prepare.
+func (p *prepareCounts) prepare() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prepares++
+}
+
+// execute counts the execution and returns the numbers
+// of the executions and the prepares.
+func (p *prepareCounts) execute() (int, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.executions++
+	return p.executions, p.prepares
+}
+
+// withPrepareRatio counts the execution or the query and returns a copy
+// of the parent context which stores the ratio of the prepares per execution
+// if the execution is each every-th execution or the parent context as is.
+// The execution skipped by the driver (driver.ErrSkip) is not counted
+// because the database/sql retries it by the prepared statement.
+func (c connection) withPrepareRatio(ctx context.Context, err error) context.Context {
+	if c.prepareRatio.every <= 0 || errors.Is(err, driver.ErrSkip) {
+		return ctx
+	}
+
+	n, prepares := c.prepareRatio.counts.execute()
+	if n%c.prepareRatio.every != 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, prepareRatioKey{}, float64(prepares)/float64(n))
+}
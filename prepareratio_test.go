@@ -0,0 +1,75 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestPrepareRatio(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		ratios []float64
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			if ratio, ok := PrepareRatio(e.Ctx); ok {
+				mu.Lock()
+				defer mu.Unlock()
+				ratios = append(ratios, ratio)
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l, PrepareRatioEvery: 5}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_prepare_ratio")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	// the execution prepares the statement
+	_, err = db.Exec("CREATE|prepare_ratio|id=int64")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	// the statement is prepared once and reused
+	stmt, err := db.Prepare("INSERT|prepare_ratio|id=?")
+	if err != nil {
+		t.Fatalf("db prepare error: %#v", err)
+	}
+	defer stmt.Close()
+
+	for i := 1; i <= 9; i++ {
+		_, err = stmt.Exec(i)
+		if err != nil {
+			t.Fatalf("stmt exec error: %#v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := []float64{0.4, 0.2}
+	if !reflect.DeepEqual(ratios, expected) {
+		t.Errorf("unexpected prepare ratios, expected: %v, recieved: %v", expected, ratios)
+	}
+
+	for _, ratio := range ratios {
+		if ratio >= 1 {
+			t.Errorf("unexpected prepare ratio, expected: below 1, recieved: %v", ratio)
+		}
+	}
+}
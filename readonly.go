@@ -0,0 +1,84 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+)
+
+type readOnlyViolationKey struct{}
+
+// ReadOnlyViolation returns true if the operation is the write statement
+// (INSERT, UPDATE or DELETE) executed in the read-only transaction
+// (begun with driver.TxOptions.ReadOnly), so the loggers may flag
+// the violation before the database rejects the statement.
+func ReadOnlyViolation(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	violation, _ := ctx.Value(readOnlyViolationKey{}).(bool)
+	return violation
+}
+
+// withReadOnlyViolation returns a copy of the parent context which
+// flags the read-only violation or the parent context as is if
+// the connection is not in the read-only transaction or the query is not a write.
+func (c connection) withReadOnlyViolation(ctx context.Context, query string) context.Context {
+	if c.readOnly == nil || atomic.LoadInt32(c.readOnly) == 0 || !isWrite(query) {
+		return ctx
+	}
+
+	return context.WithValue(ctx, readOnlyViolationKey{}, true)
+}
+
+// isWrite returns true if the first keyword of the query
+// after the leading comments is INSERT, UPDATE or DELETE.
+func isWrite(query string) bool {
+	switch queryVerb(query) {
+	case "INSERT", "UPDATE", "DELETE":
+		return true
+	}
+	return false
+}
+
+// queryVerb returns the upper cased first keyword
+// of the query after the leading comments or blank string.
+func queryVerb(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(")
+
+		if strings.HasPrefix(query, "--") {
+			i := strings.IndexByte(query, '\n')
+			if i == -1 {
+				return ""
+			}
+			query = query[i+1:]
+
+		} else if strings.HasPrefix(query, "/*") {
+			i := strings.Index(query, "*/")
+			if i == -1 {
+				return ""
+			}
+			query = query[i+2:]
+
+		} else {
+			break
+		}
+	}
+
+	i := 0
+	for i < len(query) && isIdentifierByte(query[i]) {
+		i++
+	}
+
+	return strings.ToUpper(query[:i])
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
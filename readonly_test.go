@@ -0,0 +1,104 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestIsWrite(t *testing.T) {
+	var tests = []struct {
+		query    string
+		expected bool
+	}{
+		{query: "INSERT INTO foo VALUES (1)", expected: true},
+		{query: "  update foo SET bar = 1", expected: true},
+		{query: "-- by id\nDELETE FROM foo WHERE id = 1", expected: true},
+		{query: "/* app */ INSERT|tbl|id=?", expected: true},
+		{query: "SELECT * FROM foo", expected: false},
+		{query: "INSERTS", expected: false},
+		{query: "updated_at", expected: false},
+		{query: "/* DELETE */ SELECT 1", expected: false},
+		{query: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		if w := isWrite(tt.query); w != tt.expected {
+			t.Errorf("unexpected write detection of %q, expected: %t, recieved: %t", tt.query, tt.expected, w)
+		}
+	}
+}
+
+func TestReadOnlyViolation(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		violations []string
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			if ReadOnlyViolation(e.Ctx) && e.Err != driver.ErrSkip {
+				mu.Lock()
+				defer mu.Unlock()
+				violations = append(violations, e.Topic+" "+e.Query)
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_read_only_violation")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE|tbl|id=int64`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("db begin error: %#v", err)
+	}
+
+	_, err = tx.Exec("INSERT|tbl|id=?", 1)
+	if err != nil {
+		t.Fatalf("tx exec error: %#v", err)
+	}
+
+	rows, err := tx.Query("SELECT|tbl|id|")
+	if err != nil {
+		t.Fatalf("tx query error: %#v", err)
+	}
+	rows.Close()
+
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf("tx commit error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|tbl|id=?", 2)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(violations) != 1 || violations[0] != "stmt-exec-context INSERT|tbl|id=?" {
+		t.Errorf("unexpected read-only violations, expected: [stmt-exec-context INSERT|tbl|id=?], recieved: %q", violations)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrContextInterfaces is wrapped by the error of the driver-open
+// of the connection which does not implement the context interfaces
+// required by the Driver.RequireContextInterfaces.
+var ErrContextInterfaces = errors.New("sqltee: context interfaces unsupported")
+
+// requireContextInterfaces returns the error which names the context
+// interfaces the connection does not implement or nil if the connection
+// implements driver.ConnPrepareContext, driver.ExecerContext
+// and driver.QueryerContext.
+func requireContextInterfaces(conn driver.Conn) error {
+	var missing []string
+
+	if _, ok := conn.(driver.ConnPrepareContext); !ok {
+		missing = append(missing, "driver.ConnPrepareContext")
+	}
+	if _, ok := conn.(driver.ExecerContext); !ok {
+		missing = append(missing, "driver.ExecerContext")
+	}
+	if _, ok := conn.(driver.QueryerContext); !ok {
+		missing = append(missing, "driver.QueryerContext")
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: connection %T does not implement %s", ErrContextInterfaces, conn, strings.Join(missing, ", "))
+}
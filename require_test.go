@@ -0,0 +1,105 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// minimalDriver is a driver which connections implement
+// only the driver.Conn interface.
+type minimalDriver struct {
+	closed *bool
+}
+
+func (d minimalDriver) Open(name string) (driver.Conn, error) {
+	return minimalConn{closed: d.closed}, nil
+}
+
+type minimalConn struct {
+	closed *bool
+}
+
+func (minimalConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("minimal: prepare unsupported")
+}
+
+func (c minimalConn) Close() error {
+	*c.closed = true
+	return nil
+}
+
+func (minimalConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("minimal: begin unsupported")
+}
+
+func TestRequireContextInterfaces(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		driver   func(closed *bool) driver.Driver
+		expected string
+	}{
+		{
+			name:     "minimal connection",
+			line:     line(),
+			driver:   func(closed *bool) driver.Driver { return minimalDriver{closed: closed} },
+			expected: "sqltee: context interfaces unsupported: connection sqltee.minimalConn does not implement driver.ConnPrepareContext, driver.ExecerContext, driver.QueryerContext",
+		},
+		{
+			name:   "full connection",
+			line:   line(),
+			driver: func(*bool) driver.Driver { return fakedb.Driver },
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				closed bool
+				logged error
+			)
+
+			l := EventLogger{
+				Callback: func(e Event) {
+					if e.Topic == "driver-open" {
+						logged = e.Err
+					}
+				},
+				NewTimer: func() Timer { return fakeTimer{} },
+			}
+			drv := &Driver{Driver: tt.driver(&closed), Logger: l, RequireContextInterfaces: true}
+
+			conn, err := drv.Open("fakedb_sqltee_test_require_context_interfaces")
+
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("unexpected driver open error: %#v %s", err, tt.line)
+				}
+				conn.Close()
+				return
+			}
+
+			if !errors.Is(err, ErrContextInterfaces) || err.Error() != tt.expected {
+				t.Errorf("unexpected error, expected: %s, recieved: %v %s", tt.expected, err, tt.line)
+			}
+
+			if !errors.Is(logged, ErrContextInterfaces) {
+				t.Errorf("unexpected logged error, expected: %v, recieved: %v %s", ErrContextInterfaces, logged, tt.line)
+			}
+
+			if !closed {
+				t.Errorf("unexpected connection, expected: closed %s", tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// defaultRetryBackoff is the backoff of the retries if the Driver.RetryBackoff is nil.
+var defaultRetryBackoff = ExponentialBackoff(10 * time.Millisecond)
+
+// RetryLogger may be implemented by the Logger to log each retry
+// of the driver-open failed by the driver.ErrBadConn (see Driver.RetryBadConn):
+// d is the duration of the failed attempt, attempt is the number
+// of the next attempt (2 is the first retry) and backoff is the time
+// slept before the next attempt.
+type RetryLogger interface {
+	ConnRetry(name string, d time.Duration, attempt int, backoff time.Duration, err error)
+}
+
+func (l recordLogger) ConnRetry(name string, d time.Duration, attempt int, backoff time.Duration, err error) {
+	l.recorder.record("conn-retry", d, err)
+	if rl, ok := l.Logger.(RetryLogger); ok {
+		rl.ConnRetry(name, d, attempt, backoff, err)
+	}
+}
+
+// LinearBackoff returns the backoff schedule which sleeps
+// step, 2*step, 3*step and so on before the retries.
+func LinearBackoff(step time.Duration) func(retry int) time.Duration {
+	return func(retry int) time.Duration {
+		return time.Duration(retry) * step
+	}
+}
+
+// maxBackoff is the longest backoff of the ExponentialBackoff
+// unless the base is longer.
+const maxBackoff = 10 * time.Minute
+
+// ExponentialBackoff returns the backoff schedule which sleeps
+// base, 2*base, 4*base and so on before the retries
+// up to 10 minutes or the base if the base is longer.
+func ExponentialBackoff(base time.Duration) func(retry int) time.Duration {
+	limit := maxBackoff
+	if base > limit {
+		limit = base
+	}
+
+	return func(retry int) time.Duration {
+		b := base
+		for i := 1; i < retry && b > 0; i++ {
+			if b > limit/2 {
+				return limit
+			}
+			b *= 2
+		}
+		return b
+	}
+}
+
+// open opens the connection of the base driver and retries the open
+// failed by the driver.ErrBadConn up to the RetryBadConn times
+// sleeping the backoff before each retry unless the context is done.
+func (d *Driver) open(ctx context.Context, logger recordLogger, name string) (driver.Conn, error) {
+	if d.RetryBadConn <= 0 {
+		return d.Driver.Open(name)
+	}
+
+	backoff := d.RetryBackoff
+	if backoff == nil {
+		backoff = defaultRetryBackoff
+	}
+
+	for retry := 0; ; retry++ {
+		t := startTimer(logger)
+		conn, err := d.Driver.Open(name)
+		if err == nil || retry >= d.RetryBadConn || !errors.Is(err, driver.ErrBadConn) {
+			return conn, err
+		}
+
+		b := backoff(retry + 1)
+		logger.ConnRetry(name, t.Stop(), retry+2, b, err)
+
+		timer := time.NewTimer(b)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// flakyDriver is a driver which fails the first opens by driver.ErrBadConn.
+type flakyDriver struct {
+	mu    sync.Mutex
+	fails int
+	opens int
+}
+
+func (d *flakyDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.opens++
+	if d.opens <= d.fails {
+		return nil, driver.ErrBadConn
+	}
+	return fakedb.Driver.Open(name)
+}
+
+func TestDriverRetryBadConn(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	base := &flakyDriver{fails: 2}
+	drv := &Driver{Driver: base, Logger: l, RetryBadConn: 3, RetryBackoff: LinearBackoff(time.Millisecond)}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_retry_bad_conn")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	err = db.Ping()
+	if err != nil {
+		t.Fatalf("db ping error: %#v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var (
+		attempts []int
+		backoffs []time.Duration
+	)
+	for _, e := range events {
+		if e.Topic != "conn-retry" {
+			continue
+		}
+		if !errors.Is(e.Err, driver.ErrBadConn) {
+			t.Errorf("unexpected retry error, expected: %v, recieved: %v", driver.ErrBadConn, e.Err)
+		}
+		attempts = append(attempts, e.Attempt)
+		backoffs = append(backoffs, e.Backoff)
+	}
+
+	if !reflect.DeepEqual(attempts, []int{2, 3}) {
+		t.Errorf("unexpected attempts, expected: %v, recieved: %v", []int{2, 3}, attempts)
+	}
+
+	expected := []time.Duration{time.Millisecond, 2 * time.Millisecond}
+	if !reflect.DeepEqual(backoffs, expected) {
+		t.Errorf("unexpected backoffs, expected: %v, recieved: %v", expected, backoffs)
+	}
+
+	if base.opens != 3 {
+		t.Errorf("unexpected number of the opens, expected: 3, recieved: %d", base.opens)
+	}
+}
+
+func TestDriverRetryBadConnExhausted(t *testing.T) {
+	var retries int
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			if e.Topic == "conn-retry" {
+				retries++
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: &flakyDriver{fails: 5}, Logger: l, RetryBadConn: 2, RetryBackoff: LinearBackoff(0)}
+
+	_, err := drv.Open("fakedb_sqltee_test_retry_bad_conn_exhausted")
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("unexpected error, expected: %v, recieved: %v", driver.ErrBadConn, err)
+	}
+
+	if retries != 2 {
+		t.Errorf("unexpected number of the retries, expected: 2, recieved: %d", retries)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		backoff  func(retry int) time.Duration
+		expected []time.Duration
+	}{
+		{
+			name:     "linear",
+			line:     line(),
+			backoff:  LinearBackoff(10 * time.Millisecond),
+			expected: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond, 40 * time.Millisecond},
+		},
+		{
+			name:     "exponential",
+			line:     line(),
+			backoff:  ExponentialBackoff(10 * time.Millisecond),
+			expected: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond},
+		},
+		{
+			name:     "exponential up to the limit",
+			line:     line(),
+			backoff:  ExponentialBackoff(time.Minute),
+			expected: []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute},
+		},
+		{
+			name:     "exponential of the base longer than the limit",
+			line:     line(),
+			backoff:  ExponentialBackoff(time.Hour),
+			expected: []time.Duration{time.Hour, time.Hour, time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			var backoffs []time.Duration
+			for retry := 1; retry <= len(tt.expected); retry++ {
+				backoffs = append(backoffs, tt.backoff(retry))
+			}
+
+			if !reflect.DeepEqual(backoffs, tt.expected) {
+				t.Errorf("unexpected backoffs, expected: %v, recieved: %v %s", tt.expected, backoffs, tt.line)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoffOverflow(t *testing.T) {
+	backoff := ExponentialBackoff(time.Hour)
+
+	for _, retry := range []int{1, 32, 33, 64, 1000} {
+		b := backoff(retry)
+		if b != time.Hour {
+			t.Errorf("unexpected backoff of the retry %d, expected: %v, recieved: %v", retry, time.Hour, b)
+		}
+	}
+}
+
+func TestDriverRetryBadConnCanceled(t *testing.T) {
+	drv := &Driver{Driver: &flakyDriver{fails: 5}, Logger: NopLogger{}, RetryBadConn: 3, RetryBackoff: ExponentialBackoff(time.Hour)}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_retry_bad_conn_canceled")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Connect(ctx)
+		done <- err
+	}()
+
+	// the backoff of an hour is interrupted by the context
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected connect to return after the context is done")
+	}
+
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("unexpected error, expected: %v, recieved: %v", driver.ErrBadConn, err)
+	}
+}
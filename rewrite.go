@@ -0,0 +1,18 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import "context"
+
+// rewrite returns the query rewritten by the rewrite hook
+// of the connection (see Driver.RewriteQuery) or the query as is
+// if the hook is nil.
+func (c connection) rewrite(ctx context.Context, query string) string {
+	if c.rewriteQuery == nil {
+		return query
+	}
+
+	return c.rewriteQuery(ctx, query)
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// commentDriver is a driver which records the prepared queries
+// and strips the trailing comments the fake driver can not parse.
+type commentDriver struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (d *commentDriver) Open(name string) (driver.Conn, error) {
+	conn, err := fakedb.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return commentConn{Conn: conn, driver: d}, nil
+}
+
+type commentConn struct {
+	driver.Conn
+	driver *commentDriver
+}
+
+func (c commentConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	c.driver.mu.Lock()
+	c.driver.queries = append(c.driver.queries, query)
+	c.driver.mu.Unlock()
+
+	if i := strings.Index(query, " /*"); i != -1 {
+		query = query[:i]
+	}
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+func (c commentConn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+type routeKey struct{}
+
+func TestDriverRewriteQuery(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		logged = map[string]string{}
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			logged[e.Topic] = e.Query
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+
+	drv := &Driver{
+		Driver: &commentDriver{},
+		Logger: l,
+		RewriteQuery: func(ctx context.Context, query string) string {
+			route, _ := ctx.Value(routeKey{}).(string)
+			return query + " /*application='svc',route='" + route + "'*/"
+		},
+	}
+
+	c, err := drv.OpenConnector("TestDriverRewriteQuery")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	ctx := context.WithValue(context.Background(), routeKey{}, "/x")
+
+	_, err = db.ExecContext(ctx, "CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT|t1|name|")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+	rows.Close()
+
+	expected := []string{
+		"CREATE|t1|name=string /*application='svc',route='/x'*/",
+		"SELECT|t1|name| /*application='svc',route='/x'*/",
+	}
+
+	received := drv.Driver.(*commentDriver).queries
+	if len(received) != len(expected) || received[0] != expected[0] || received[1] != expected[1] {
+		t.Errorf("unexpected queries of the driver, expected: %q, recieved: %q", expected, received)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for topic, query := range map[string]string{
+		"conn-exec-context":    expected[0],
+		"stmt-exec-context":    expected[0],
+		"conn-query-context":   expected[1],
+		"conn-prepare-context": expected[1],
+		"stmt-query-context":   expected[1],
+	} {
+		if logged[topic] != query {
+			t.Errorf("unexpected logged query of %s, expected: %q, recieved: %q", topic, query, logged[topic])
+		}
+	}
+}
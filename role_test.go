@@ -0,0 +1,92 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestDriverRole(t *testing.T) {
+	var tests = []struct {
+		name string
+		line string
+		role string
+	}{
+		{name: "primary", line: line(), role: "primary"},
+		{name: "replica", line: line(), role: "replica"},
+		{name: "untagged", line: line(), role: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu     sync.Mutex
+				events []Event
+			)
+
+			l := EventLogger{
+				Callback: func(e Event) {
+					mu.Lock()
+					defer mu.Unlock()
+					events = append(events, e)
+				},
+				NewTimer: func() Timer { return fakeTimer{} },
+			}
+
+			drv := &Driver{Driver: fakedb.Driver, Logger: l, Role: tt.role}
+
+			c, err := drv.OpenConnector("TestDriverRole_" + tt.name)
+			if err != nil {
+				t.Fatalf("driver open connector error: %#v %s", err, tt.line)
+			}
+
+			db := sql.OpenDB(c)
+
+			_, err = db.Exec("CREATE|t1|name=string")
+			if err != nil {
+				t.Fatalf("db exec error: %#v %s", err, tt.line)
+			}
+
+			rows, err := db.Query("SELECT|t1|name|")
+			if err != nil {
+				t.Fatalf("db query error: %#v %s", err, tt.line)
+			}
+			rows.Close()
+
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("db close error: %#v %s", err, tt.line)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(events) == 0 || events[0].Topic != "driver-open" || events[len(events)-1].Topic != "conn-close" {
+				t.Fatalf("unexpected events, expected: from driver-open to conn-close, recieved: %d %s", len(events), tt.line)
+			}
+
+			for _, e := range events {
+				if e.Role != tt.role {
+					t.Errorf("unexpected role of %s, expected: %q, recieved: %q %s", e.Topic, tt.role, e.Role, tt.line)
+				}
+			}
+		})
+	}
+}
+
+func TestDriverRoleUnsupported(t *testing.T) {
+	drv := &Driver{Logger: NopLogger{}, Role: "primary"}
+
+	if _, ok := drv.logger().(NopLogger); !ok {
+		t.Errorf("unexpected logger, expected: NopLogger, recieved: %T", drv.logger())
+	}
+}
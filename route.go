@@ -0,0 +1,167 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+// RouteLogger returns a Logger which dispatches each log to the logger
+// of the routes by the topic of the log (for example conn-exec-context
+// or tx-commit) or to the def logger if the topic has no route.
+//
+// The topic of the operation is unknown when the timer is started so
+// the routing happens at the log time and the Timer method always
+// returns the timer of the def logger (so the def logger reports
+// whether the timing is needed, see TimingLogger).
+// The optional tagging interfaces (for example RoleLogger)
+// tag the loggers of all the routes and the def logger.
+func RouteLogger(routes map[string]Logger, def Logger) Logger {
+	l := routeLogger{routes: routes, def: def}
+	l.forwarder = forwarder{Logger: def, with: l.with}
+	return l
+}
+
+type routeLogger struct {
+	forwarder
+	routes map[string]Logger
+	def    Logger
+}
+
+// with returns the route logger of the tagged loggers of the routes.
+func (l routeLogger) with(tag func(Logger) Logger) Logger {
+	routes := make(map[string]Logger, len(l.routes))
+	for topic, r := range l.routes {
+		if r != nil {
+			routes[topic] = tag(r)
+		}
+	}
+	return RouteLogger(routes, tag(l.def))
+}
+
+func (l routeLogger) route(topic string) Logger {
+	if r, ok := l.routes[topic]; ok && r != nil {
+		return r
+	}
+	return l.def
+}
+
+func (l routeLogger) DriverOpen(name string, d time.Duration, err error) {
+	l.route("driver-open").DriverOpen(name, d, err)
+}
+
+func (l routeLogger) ConnPrepare(d time.Duration, query string, err error) {
+	l.route("conn-prepare").ConnPrepare(d, query, err)
+}
+
+func (l routeLogger) ConnClose(d time.Duration, err error) {
+	l.route("conn-close").ConnClose(d, err)
+}
+
+func (l routeLogger) ConnBegin(d time.Duration, err error) {
+	l.route("conn-begin").ConnBegin(d, err)
+}
+
+func (l routeLogger) ConnBeginTx(ctx context.Context, d time.Duration, opts driver.TxOptions, err error) {
+	l.route("conn-begin-tx").ConnBeginTx(ctx, d, opts, err)
+}
+
+func (l routeLogger) ConnPrepareContext(ctx context.Context, d time.Duration, query string, err error) {
+	l.route("conn-prepare-context").ConnPrepareContext(ctx, d, query, err)
+}
+
+func (l routeLogger) ConnPrepareFallback(ctx context.Context, d time.Duration, query string, err error) {
+	logPrepareFallback(l.route("conn-prepare-fallback"), ctx, d, query, err)
+}
+
+func (l routeLogger) ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.route("conn-exec").ConnExec(d, query, dargs, res, err)
+}
+
+func (l routeLogger) ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.route("conn-exec-context").ConnExecContext(ctx, d, query, nvdargs, res, err)
+}
+
+func (l routeLogger) ConnPing(ctx context.Context, d time.Duration, err error) {
+	l.route("conn-ping").ConnPing(ctx, d, err)
+}
+
+func (l routeLogger) ConnRaw() {
+	logRaw(l.route("conn-raw"))
+}
+
+func (l routeLogger) ConnExplain(ctx context.Context, d time.Duration, query string, plan []string, err error) {
+	logExplain(l.route("conn-explain"), ctx, d, query, plan, err)
+}
+
+func (l routeLogger) ConnQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.route("conn-query").ConnQuery(d, query, dargs, err)
+}
+
+func (l routeLogger) ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.route("conn-query-context").ConnQueryContext(ctx, d, query, nvdargs, err)
+}
+
+func (l routeLogger) StmtClose(d time.Duration, err error) {
+	l.route("stmt-close").StmtClose(d, err)
+}
+
+func (l routeLogger) StmtCloseTotal(d, total time.Duration, err error) {
+	logStmtClose(l.route("stmt-close"), d, total, err)
+}
+
+func (l routeLogger) StmtExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.route("stmt-exec").StmtExec(d, query, dargs, res, err)
+}
+
+func (l routeLogger) StmtExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.route("stmt-exec-context").StmtExecContext(ctx, d, query, nvdargs, res, err)
+}
+
+func (l routeLogger) StmtQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.route("stmt-query").StmtQuery(d, query, dargs, err)
+}
+
+func (l routeLogger) StmtQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.route("stmt-query-context").StmtQueryContext(ctx, d, query, nvdargs, err)
+}
+
+func (l routeLogger) RowsNext(d time.Duration, dest []driver.Value, err error) {
+	l.route("rows-next").RowsNext(d, dest, err)
+}
+
+func (l routeLogger) RowsNextRow(d time.Duration, row int, columns []string, dest []driver.Value, err error) {
+	logRowsNext(l.route("rows-next"), d, row, columns, dest, err)
+}
+
+func (l routeLogger) TxCommit(d time.Duration, err error) {
+	l.route("tx-commit").TxCommit(d, err)
+}
+
+func (l routeLogger) TxRollback(d time.Duration, err error) {
+	l.route("tx-rollback").TxRollback(d, err)
+}
+
+func (l routeLogger) TxSavepoint(ctx context.Context, d time.Duration, query, command, name string, err error) {
+	l.route("tx-savepoint").TxSavepoint(ctx, d, query, command, name, err)
+}
+
+func (l routeLogger) ConnRetry(name string, d time.Duration, attempt int, backoff time.Duration, err error) {
+	if rl, ok := l.route("conn-retry").(RetryLogger); ok {
+		rl.ConnRetry(name, d, attempt, backoff, err)
+	}
+}
+
+func (l routeLogger) ConnectorConnect(ctx context.Context, d time.Duration, err error) {
+	if cl, ok := l.route("connector-connect").(ConnectorLogger); ok {
+		cl.ConnectorConnect(ctx, d, err)
+	}
+}
+
+func (l routeLogger) Timer() Timer {
+	return l.def.Timer()
+}
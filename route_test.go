@@ -0,0 +1,90 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// recorder records the topics of the events.
+type recorder struct {
+	mu     sync.Mutex
+	topics []string
+}
+
+func (r *recorder) logger() EventLogger {
+	return EventLogger{
+		Callback: func(e Event) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.topics = append(r.topics, e.Topic)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+}
+
+func TestRouteLogger(t *testing.T) {
+	var execs, closes, defs recorder
+
+	l := RouteLogger(map[string]Logger{
+		"conn-exec-context": execs.logger(),
+		"stmt-exec-context": execs.logger(),
+		"conn-close":        closes.logger(),
+		"stmt-close":        closes.logger(),
+	}, defs.logger())
+
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("TestRouteLogger")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+
+	_, err = db.Exec("WIPE")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	db.Close()
+
+	var tests = []struct {
+		name     string
+		line     string
+		recorder *recorder
+		expected []string
+	}{
+		{
+			name:     "exec",
+			line:     line(),
+			recorder: &execs,
+			expected: []string{"conn-exec-context", "stmt-exec-context"},
+		},
+		{
+			name:     "close",
+			line:     line(),
+			recorder: &closes,
+			expected: []string{"stmt-close", "conn-close"},
+		},
+		{
+			name:     "default",
+			line:     line(),
+			recorder: &defs,
+			expected: []string{"driver-open", "conn-prepare-context"},
+		},
+	}
+
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.recorder.topics, tt.expected) {
+			t.Errorf("unexpected %s topics, expected: %v, recieved: %v %s", tt.name, tt.expected, tt.recorder.topics, tt.line)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+//go:generate go run gen_rows.go
+
+import (
+	"database/sql/driver"
+	"reflect"
+)
+
+// The optional interfaces of the driver.Rows are passed through
+// by the rowsIterator variants (see rows_gen.go) which embed
+// the rowsIterator and the subset of the following wrappers,
+// so the method set of the variant exactly matches
+// the method set of the rows of the driver.
+
+type rowsNextResultSet struct {
+	nextResultSet driver.RowsNextResultSet
+}
+
+func (r rowsNextResultSet) HasNextResultSet() bool {
+	return r.nextResultSet.HasNextResultSet()
+}
+
+func (r rowsNextResultSet) NextResultSet() error {
+	return r.nextResultSet.NextResultSet()
+}
+
+type rowsColumnTypeScanType struct {
+	scanType driver.RowsColumnTypeScanType
+}
+
+func (r rowsColumnTypeScanType) ColumnTypeScanType(index int) reflect.Type {
+	return r.scanType.ColumnTypeScanType(index)
+}
+
+type rowsColumnTypeDatabaseTypeName struct {
+	databaseTypeName driver.RowsColumnTypeDatabaseTypeName
+}
+
+func (r rowsColumnTypeDatabaseTypeName) ColumnTypeDatabaseTypeName(index int) string {
+	return r.databaseTypeName.ColumnTypeDatabaseTypeName(index)
+}
+
+type rowsColumnTypeLength struct {
+	length driver.RowsColumnTypeLength
+}
+
+func (r rowsColumnTypeLength) ColumnTypeLength(index int) (int64, bool) {
+	return r.length.ColumnTypeLength(index)
+}
+
+type rowsColumnTypeNullable struct {
+	nullable driver.RowsColumnTypeNullable
+}
+
+func (r rowsColumnTypeNullable) ColumnTypeNullable(index int) (bool, bool) {
+	return r.nullable.ColumnTypeNullable(index)
+}
+
+type rowsColumnTypePrecisionScale struct {
+	precisionScale driver.RowsColumnTypePrecisionScale
+}
+
+func (r rowsColumnTypePrecisionScale) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	return r.precisionScale.ColumnTypePrecisionScale(index)
+}
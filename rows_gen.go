@@ -0,0 +1,682 @@
+// Code generated by gen_rows.go; DO NOT EDIT.
+
+package sqltee
+
+import "database/sql/driver"
+
+// rowsIterator1 implements driver.RowsNextResultSet.
+type rowsIterator1 struct {
+	rowsIterator
+	rowsNextResultSet
+}
+
+// rowsIterator2 implements driver.RowsColumnTypeScanType.
+type rowsIterator2 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+}
+
+// rowsIterator3 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType.
+type rowsIterator3 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+}
+
+// rowsIterator4 implements driver.RowsColumnTypeDatabaseTypeName.
+type rowsIterator4 struct {
+	rowsIterator
+	rowsColumnTypeDatabaseTypeName
+}
+
+// rowsIterator5 implements driver.RowsNextResultSet, driver.RowsColumnTypeDatabaseTypeName.
+type rowsIterator5 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeDatabaseTypeName
+}
+
+// rowsIterator6 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName.
+type rowsIterator6 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+}
+
+// rowsIterator7 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName.
+type rowsIterator7 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+}
+
+// rowsIterator8 implements driver.RowsColumnTypeLength.
+type rowsIterator8 struct {
+	rowsIterator
+	rowsColumnTypeLength
+}
+
+// rowsIterator9 implements driver.RowsNextResultSet, driver.RowsColumnTypeLength.
+type rowsIterator9 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeLength
+}
+
+// rowsIterator10 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeLength.
+type rowsIterator10 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeLength
+}
+
+// rowsIterator11 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeLength.
+type rowsIterator11 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeLength
+}
+
+// rowsIterator12 implements driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength.
+type rowsIterator12 struct {
+	rowsIterator
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+}
+
+// rowsIterator13 implements driver.RowsNextResultSet, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength.
+type rowsIterator13 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+}
+
+// rowsIterator14 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength.
+type rowsIterator14 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+}
+
+// rowsIterator15 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength.
+type rowsIterator15 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+}
+
+// rowsIterator16 implements driver.RowsColumnTypeNullable.
+type rowsIterator16 struct {
+	rowsIterator
+	rowsColumnTypeNullable
+}
+
+// rowsIterator17 implements driver.RowsNextResultSet, driver.RowsColumnTypeNullable.
+type rowsIterator17 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeNullable
+}
+
+// rowsIterator18 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeNullable.
+type rowsIterator18 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeNullable
+}
+
+// rowsIterator19 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeNullable.
+type rowsIterator19 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeNullable
+}
+
+// rowsIterator20 implements driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeNullable.
+type rowsIterator20 struct {
+	rowsIterator
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeNullable
+}
+
+// rowsIterator21 implements driver.RowsNextResultSet, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeNullable.
+type rowsIterator21 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeNullable
+}
+
+// rowsIterator22 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeNullable.
+type rowsIterator22 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeNullable
+}
+
+// rowsIterator23 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeNullable.
+type rowsIterator23 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeNullable
+}
+
+// rowsIterator24 implements driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable.
+type rowsIterator24 struct {
+	rowsIterator
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+}
+
+// rowsIterator25 implements driver.RowsNextResultSet, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable.
+type rowsIterator25 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+}
+
+// rowsIterator26 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable.
+type rowsIterator26 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+}
+
+// rowsIterator27 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable.
+type rowsIterator27 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+}
+
+// rowsIterator28 implements driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable.
+type rowsIterator28 struct {
+	rowsIterator
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+}
+
+// rowsIterator29 implements driver.RowsNextResultSet, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable.
+type rowsIterator29 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+}
+
+// rowsIterator30 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable.
+type rowsIterator30 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+}
+
+// rowsIterator31 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable.
+type rowsIterator31 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+}
+
+// rowsIterator32 implements driver.RowsColumnTypePrecisionScale.
+type rowsIterator32 struct {
+	rowsIterator
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator33 implements driver.RowsNextResultSet, driver.RowsColumnTypePrecisionScale.
+type rowsIterator33 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator34 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypePrecisionScale.
+type rowsIterator34 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator35 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypePrecisionScale.
+type rowsIterator35 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator36 implements driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypePrecisionScale.
+type rowsIterator36 struct {
+	rowsIterator
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator37 implements driver.RowsNextResultSet, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypePrecisionScale.
+type rowsIterator37 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator38 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypePrecisionScale.
+type rowsIterator38 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator39 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypePrecisionScale.
+type rowsIterator39 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator40 implements driver.RowsColumnTypeLength, driver.RowsColumnTypePrecisionScale.
+type rowsIterator40 struct {
+	rowsIterator
+	rowsColumnTypeLength
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator41 implements driver.RowsNextResultSet, driver.RowsColumnTypeLength, driver.RowsColumnTypePrecisionScale.
+type rowsIterator41 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeLength
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator42 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeLength, driver.RowsColumnTypePrecisionScale.
+type rowsIterator42 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeLength
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator43 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeLength, driver.RowsColumnTypePrecisionScale.
+type rowsIterator43 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeLength
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator44 implements driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypePrecisionScale.
+type rowsIterator44 struct {
+	rowsIterator
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator45 implements driver.RowsNextResultSet, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypePrecisionScale.
+type rowsIterator45 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator46 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypePrecisionScale.
+type rowsIterator46 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator47 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypePrecisionScale.
+type rowsIterator47 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator48 implements driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator48 struct {
+	rowsIterator
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator49 implements driver.RowsNextResultSet, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator49 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator50 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator50 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator51 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator51 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator52 implements driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator52 struct {
+	rowsIterator
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator53 implements driver.RowsNextResultSet, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator53 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator54 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator54 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator55 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator55 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator56 implements driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator56 struct {
+	rowsIterator
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator57 implements driver.RowsNextResultSet, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator57 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator58 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator58 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator59 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator59 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator60 implements driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator60 struct {
+	rowsIterator
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator61 implements driver.RowsNextResultSet, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator61 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator62 implements driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator62 struct {
+	rowsIterator
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// rowsIterator63 implements driver.RowsNextResultSet, driver.RowsColumnTypeScanType, driver.RowsColumnTypeDatabaseTypeName, driver.RowsColumnTypeLength, driver.RowsColumnTypeNullable, driver.RowsColumnTypePrecisionScale.
+type rowsIterator63 struct {
+	rowsIterator
+	rowsNextResultSet
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+}
+
+// newRows returns the rows iterator wrapped into the variant
+// which implements exactly the optional interfaces of the rows of the driver.
+func newRows(r rowsIterator) driver.Rows {
+	r.row = new(int)
+	r.cols = new([]string)
+
+	var mask int
+
+	nextResultSet, ok := r.rows.(driver.RowsNextResultSet)
+	if ok {
+		mask |= 1
+	}
+
+	scanType, ok := r.rows.(driver.RowsColumnTypeScanType)
+	if ok {
+		mask |= 2
+	}
+
+	databaseTypeName, ok := r.rows.(driver.RowsColumnTypeDatabaseTypeName)
+	if ok {
+		mask |= 4
+	}
+
+	length, ok := r.rows.(driver.RowsColumnTypeLength)
+	if ok {
+		mask |= 8
+	}
+
+	nullable, ok := r.rows.(driver.RowsColumnTypeNullable)
+	if ok {
+		mask |= 16
+	}
+
+	precisionScale, ok := r.rows.(driver.RowsColumnTypePrecisionScale)
+	if ok {
+		mask |= 32
+	}
+
+	switch mask {
+	case 1:
+		return rowsIterator1{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}}
+	case 2:
+		return rowsIterator2{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}}
+	case 3:
+		return rowsIterator3{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}}
+	case 4:
+		return rowsIterator4{rowsIterator: r, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}}
+	case 5:
+		return rowsIterator5{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}}
+	case 6:
+		return rowsIterator6{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}}
+	case 7:
+		return rowsIterator7{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}}
+	case 8:
+		return rowsIterator8{rowsIterator: r, rowsColumnTypeLength: rowsColumnTypeLength{length: length}}
+	case 9:
+		return rowsIterator9{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}}
+	case 10:
+		return rowsIterator10{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}}
+	case 11:
+		return rowsIterator11{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}}
+	case 12:
+		return rowsIterator12{rowsIterator: r, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}}
+	case 13:
+		return rowsIterator13{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}}
+	case 14:
+		return rowsIterator14{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}}
+	case 15:
+		return rowsIterator15{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}}
+	case 16:
+		return rowsIterator16{rowsIterator: r, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 17:
+		return rowsIterator17{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 18:
+		return rowsIterator18{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 19:
+		return rowsIterator19{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 20:
+		return rowsIterator20{rowsIterator: r, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 21:
+		return rowsIterator21{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 22:
+		return rowsIterator22{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 23:
+		return rowsIterator23{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 24:
+		return rowsIterator24{rowsIterator: r, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 25:
+		return rowsIterator25{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 26:
+		return rowsIterator26{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 27:
+		return rowsIterator27{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 28:
+		return rowsIterator28{rowsIterator: r, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 29:
+		return rowsIterator29{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 30:
+		return rowsIterator30{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 31:
+		return rowsIterator31{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}}
+	case 32:
+		return rowsIterator32{rowsIterator: r, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 33:
+		return rowsIterator33{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 34:
+		return rowsIterator34{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 35:
+		return rowsIterator35{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 36:
+		return rowsIterator36{rowsIterator: r, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 37:
+		return rowsIterator37{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 38:
+		return rowsIterator38{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 39:
+		return rowsIterator39{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 40:
+		return rowsIterator40{rowsIterator: r, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 41:
+		return rowsIterator41{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 42:
+		return rowsIterator42{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 43:
+		return rowsIterator43{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 44:
+		return rowsIterator44{rowsIterator: r, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 45:
+		return rowsIterator45{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 46:
+		return rowsIterator46{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 47:
+		return rowsIterator47{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 48:
+		return rowsIterator48{rowsIterator: r, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 49:
+		return rowsIterator49{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 50:
+		return rowsIterator50{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 51:
+		return rowsIterator51{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 52:
+		return rowsIterator52{rowsIterator: r, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 53:
+		return rowsIterator53{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 54:
+		return rowsIterator54{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 55:
+		return rowsIterator55{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 56:
+		return rowsIterator56{rowsIterator: r, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 57:
+		return rowsIterator57{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 58:
+		return rowsIterator58{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 59:
+		return rowsIterator59{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 60:
+		return rowsIterator60{rowsIterator: r, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 61:
+		return rowsIterator61{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 62:
+		return rowsIterator62{rowsIterator: r, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	case 63:
+		return rowsIterator63{rowsIterator: r, rowsNextResultSet: rowsNextResultSet{nextResultSet: nextResultSet}, rowsColumnTypeScanType: rowsColumnTypeScanType{scanType: scanType}, rowsColumnTypeDatabaseTypeName: rowsColumnTypeDatabaseTypeName{databaseTypeName: databaseTypeName}, rowsColumnTypeLength: rowsColumnTypeLength{length: length}, rowsColumnTypeNullable: rowsColumnTypeNullable{nullable: nullable}, rowsColumnTypePrecisionScale: rowsColumnTypePrecisionScale{precisionScale: precisionScale}}
+	}
+
+	return r
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+type baseRows struct{}
+
+func (baseRows) Columns() []string              { return []string{"id"} }
+func (baseRows) Close() error                   { return nil }
+func (baseRows) Next(dest []driver.Value) error { return io.EOF }
+
+type nextResultSetRows struct{}
+
+func (nextResultSetRows) HasNextResultSet() bool { return true }
+func (nextResultSetRows) NextResultSet() error   { return io.EOF }
+
+type scanTypeRows struct{}
+
+func (scanTypeRows) ColumnTypeScanType(int) reflect.Type { return reflect.TypeOf(int64(0)) }
+
+type databaseTypeNameRows struct{}
+
+func (databaseTypeNameRows) ColumnTypeDatabaseTypeName(int) string { return "BIGINT" }
+
+type lengthRows struct{}
+
+func (lengthRows) ColumnTypeLength(int) (int64, bool) { return 42, true }
+
+type nullableRows struct{}
+
+func (nullableRows) ColumnTypeNullable(int) (bool, bool) { return true, true }
+
+type precisionScaleRows struct{}
+
+func (precisionScaleRows) ColumnTypePrecisionScale(int) (int64, int64, bool) { return 10, 2, true }
+
+var rowsInterfaces = []struct {
+	name string
+	is   func(interface{}) bool
+}{
+	{"RowsNextResultSet", func(v interface{}) bool { _, ok := v.(driver.RowsNextResultSet); return ok }},
+	{"RowsColumnTypeScanType", func(v interface{}) bool { _, ok := v.(driver.RowsColumnTypeScanType); return ok }},
+	{"RowsColumnTypeDatabaseTypeName", func(v interface{}) bool { _, ok := v.(driver.RowsColumnTypeDatabaseTypeName); return ok }},
+	{"RowsColumnTypeLength", func(v interface{}) bool { _, ok := v.(driver.RowsColumnTypeLength); return ok }},
+	{"RowsColumnTypeNullable", func(v interface{}) bool { _, ok := v.(driver.RowsColumnTypeNullable); return ok }},
+	{"RowsColumnTypePrecisionScale", func(v interface{}) bool { _, ok := v.(driver.RowsColumnTypePrecisionScale); return ok }},
+}
+
+func TestNewRows(t *testing.T) {
+	var tests = []struct {
+		name string
+		line string
+		rows driver.Rows
+	}{
+		{
+			name: "without optional interfaces",
+			line: line(),
+			rows: baseRows{},
+		},
+		{
+			name: "next result set",
+			line: line(),
+			rows: struct {
+				baseRows
+				nextResultSetRows
+			}{},
+		},
+		{
+			name: "scan type and nullable",
+			line: line(),
+			rows: struct {
+				baseRows
+				scanTypeRows
+				nullableRows
+			}{},
+		},
+		{
+			name: "database type name, length and precision scale",
+			line: line(),
+			rows: struct {
+				baseRows
+				databaseTypeNameRows
+				lengthRows
+				precisionScaleRows
+			}{},
+		},
+		{
+			name: "all optional interfaces",
+			line: line(),
+			rows: struct {
+				baseRows
+				nextResultSetRows
+				scanTypeRows
+				databaseTypeNameRows
+				lengthRows
+				nullableRows
+				precisionScaleRows
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			rows := newRows(rowsIterator{Logger: TestLogger(t), rows: tt.rows})
+
+			for _, i := range rowsInterfaces {
+				if i.is(rows) != i.is(tt.rows) {
+					t.Errorf("unexpected %s implementation, expected: %t, recieved: %t %s", i.name, i.is(tt.rows), i.is(rows), tt.line)
+				}
+			}
+
+			if r, ok := rows.(driver.RowsColumnTypeLength); ok {
+				if n, ok := r.ColumnTypeLength(0); n != 42 || !ok {
+					t.Errorf("unexpected column type length, expected: 42 true, recieved: %d %t %s", n, ok, tt.line)
+				}
+			}
+
+			if r, ok := rows.(driver.RowsColumnTypePrecisionScale); ok {
+				if p, s, ok := r.ColumnTypePrecisionScale(0); p != 10 || s != 2 || !ok {
+					t.Errorf("unexpected column type precision scale, expected: 10 2 true, recieved: %d %d %t %s", p, s, ok, tt.line)
+				}
+			}
+		})
+	}
+}
+
+func TestRowsColumnTypes(t *testing.T) {
+	drv := &Driver{Driver: fakedb.Driver, Logger: EventLogger{Callback: func(Event) {}}}
+
+	c, err := drv.OpenConnector("TestRowsColumnTypes")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|tbl|id=int64,name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	rows, err := db.Query("SELECT|tbl|id,name|")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("rows column types error: %#v", err)
+	}
+
+	if len(types) != 2 || types[0].ScanType() != reflect.TypeOf(int64(0)) || types[1].ScanType() != reflect.TypeOf("") {
+		t.Errorf("unexpected column scan types, expected: int64 string, recieved: %v %v", types[0].ScanType(), types[1].ScanType())
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"strings"
+)
+
+// savepoint is a savepoint statement (nested transaction)
+// issued by the driver or by the user as a plain exec.
+type savepoint struct {
+	command string // SAVEPOINT, RELEASE SAVEPOINT or ROLLBACK TO SAVEPOINT
+	name    string // name of the savepoint
+}
+
+// parseSavepoint returns the savepoint and true if the query is
+// one of the SAVEPOINT x, RELEASE [SAVEPOINT] x
+// or ROLLBACK [TRANSACTION|WORK] TO [SAVEPOINT] x statements.
+func parseSavepoint(query string) (savepoint, bool) {
+	fields := strings.Fields(strings.TrimRight(strings.TrimSpace(query), ";"))
+	if len(fields) < 2 {
+		return savepoint{}, false
+	}
+
+	var command string
+
+	switch strings.ToUpper(fields[0]) {
+	case "SAVEPOINT":
+		command = "SAVEPOINT"
+		fields = fields[1:]
+
+	case "RELEASE":
+		command = "RELEASE SAVEPOINT"
+		fields = fields[1:]
+		if len(fields) > 1 && strings.EqualFold(fields[0], "SAVEPOINT") {
+			fields = fields[1:]
+		}
+
+	case "ROLLBACK":
+		command = "ROLLBACK TO SAVEPOINT"
+		fields = fields[1:]
+		if len(fields) > 0 && (strings.EqualFold(fields[0], "TRANSACTION") || strings.EqualFold(fields[0], "WORK")) {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || !strings.EqualFold(fields[0], "TO") {
+			return savepoint{}, false
+		}
+		fields = fields[1:]
+		if len(fields) > 1 && strings.EqualFold(fields[0], "SAVEPOINT") {
+			fields = fields[1:]
+		}
+
+	default:
+		return savepoint{}, false
+	}
+
+	if len(fields) == 0 {
+		return savepoint{}, false
+	}
+
+	name := strings.Join(fields, " ")
+	unquoted := unquoteIdentifier(name)
+	if len(fields) != 1 && unquoted == name { // only the quoted identifier may contain spaces
+		return savepoint{}, false
+	}
+
+	return savepoint{command: command, name: unquoted}, true
+}
+
+// unquoteIdentifier returns the SQL identifier without
+// the double quotes, the backticks or the square brackets.
+func unquoteIdentifier(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"',
+		s[0] == '`' && s[len(s)-1] == '`',
+		s[0] == '[' && s[len(s)-1] == ']':
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
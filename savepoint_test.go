@@ -0,0 +1,158 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestParseSavepoint(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		query    string
+		expected savepoint
+		ok       bool
+	}{
+		{
+			name:     "savepoint",
+			line:     line(),
+			query:    "SAVEPOINT sp1",
+			expected: savepoint{command: "SAVEPOINT", name: "sp1"},
+			ok:       true,
+		},
+		{
+			name:     "release savepoint",
+			line:     line(),
+			query:    "RELEASE SAVEPOINT sp1",
+			expected: savepoint{command: "RELEASE SAVEPOINT", name: "sp1"},
+			ok:       true,
+		},
+		{
+			name:     "rollback to savepoint",
+			line:     line(),
+			query:    "ROLLBACK TO SAVEPOINT sp1",
+			expected: savepoint{command: "ROLLBACK TO SAVEPOINT", name: "sp1"},
+			ok:       true,
+		},
+		{
+			name:     "lower case with semicolon",
+			line:     line(),
+			query:    " savepoint sp_2; ",
+			expected: savepoint{command: "SAVEPOINT", name: "sp_2"},
+			ok:       true,
+		},
+		{
+			name:     "release without savepoint keyword",
+			line:     line(),
+			query:    "RELEASE sp1",
+			expected: savepoint{command: "RELEASE SAVEPOINT", name: "sp1"},
+			ok:       true,
+		},
+		{
+			name:     "rollback work to savepoint",
+			line:     line(),
+			query:    "ROLLBACK WORK TO sp1",
+			expected: savepoint{command: "ROLLBACK TO SAVEPOINT", name: "sp1"},
+			ok:       true,
+		},
+		{
+			name:     "quoted name",
+			line:     line(),
+			query:    `SAVEPOINT "Nested Tx"`,
+			expected: savepoint{command: "SAVEPOINT", name: "Nested Tx"},
+			ok:       true,
+		},
+		{
+			name:     "unquoted name with space",
+			line:     line(),
+			query:    "SAVEPOINT nested tx",
+			expected: savepoint{},
+		},
+		{
+			name:     "double quoted name",
+			line:     line(),
+			query:    `SAVEPOINT "nested"`,
+			expected: savepoint{command: "SAVEPOINT", name: "nested"},
+			ok:       true,
+		},
+		{
+			name:     "rollback",
+			line:     line(),
+			query:    "ROLLBACK",
+			expected: savepoint{},
+		},
+		{
+			name:     "select",
+			line:     line(),
+			query:    "SELECT savepoint FROM foo",
+			expected: savepoint{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			sp, ok := parseSavepoint(tt.query)
+			if ok != tt.ok {
+				t.Errorf("unexpected classification, expected: %t, recieved: %t %s", tt.ok, ok, tt.line)
+			}
+
+			if sp != tt.expected {
+				t.Errorf("unexpected savepoint, expected: %+v, recieved: %+v %s", tt.expected, sp, tt.line)
+			}
+		})
+	}
+}
+
+func TestSavepointTopic(t *testing.T) {
+	var rec recorder
+	drv := &Driver{Driver: fakedb.Driver, Logger: rec.logger()}
+
+	c, err := drv.OpenConnector("TestSavepointTopic")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	db.Exec("SAVEPOINT sp1") // fakedb does not support savepoints
+
+	var n int
+	for _, topic := range rec.topics {
+		if topic == "tx-savepoint" {
+			n++
+		}
+		if topic == "conn-exec-context" || topic == "stmt-exec-context" {
+			t.Errorf("unexpected topic: %s", topic)
+		}
+	}
+
+	if n == 0 {
+		t.Errorf("unexpected topics, expected: tx-savepoint, recieved: %v", rec.topics)
+	}
+}
+
+func TestSavepointExecContext(t *testing.T) {
+	var ctxs []context.Context
+	l := EventLogger{
+		Callback: func(e Event) { ctxs = append(ctxs, e.Ctx) },
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	c := connection{Logger: l, conn: skipConn{}}
+
+	c.Exec("SAVEPOINT sp1", nil)
+
+	if len(ctxs) != 1 || ctxs[0] == nil {
+		t.Errorf("unexpected contexts of the tx-savepoint, expected: one non-nil context, recieved: %v", ctxs)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import "context"
+
+// connIDs is the id of the last opened connection.
+var connIDs uint64
+
+type sequenceKey struct{}
+
+type sequence struct {
+	conn uint64
+	seq  int
+}
+
+// Sequence returns the unique id of the connection, the sequence number
+// of the operation of the connection and true or false if the operation
+// is not numbered. Sequence numbers increase monotonically from one
+// per connection, so the operations of the connection may be ordered
+// even if the timestamps of the interleaved logs collide.
+// Only the operations which are logged with the context are numbered.
+func Sequence(ctx context.Context) (conn uint64, seq int, ok bool) {
+	if ctx == nil {
+		return 0, 0, false
+	}
+
+	s, ok := ctx.Value(sequenceKey{}).(sequence)
+	return s.conn, s.seq, ok
+}
+
+// withSequence returns a copy of the parent context which stores
+// the id of the connection and the next sequence number of the connection.
+// The connection is used by one goroutine at a time
+// so the sequence number is incremented without locking.
+func (c connection) withSequence(ctx context.Context) context.Context {
+	if c.seq == nil {
+		return ctx
+	}
+
+	*c.seq++
+	return context.WithValue(ctx, sequenceKey{}, sequence{conn: c.id, seq: *c.seq})
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestSequence(t *testing.T) {
+	if _, _, ok := Sequence(context.Background()); ok {
+		t.Fatal("unexpected sequence of the background context")
+	}
+
+	type numbered struct {
+		topic string
+		conn  uint64
+		seq   int
+	}
+
+	var (
+		mu   sync.Mutex
+		logs []numbered
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			if conn, seq, ok := Sequence(e.Ctx); ok {
+				mu.Lock()
+				defer mu.Unlock()
+				logs = append(logs, numbered{topic: e.Topic, conn: conn, seq: seq})
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("TestSequence")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err = db.Exec("INSERT|t1|name=?", "foo")
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+	}
+
+	rows, err := db.Query("SELECT|t1|name|")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+	rows.Close()
+
+	err = db.Ping()
+	if err != nil {
+		t.Fatalf("db ping error: %#v", err)
+	}
+
+	// each exec and query is numbered by conn-*-context skipped
+	// by fakedb, conn-prepare-context and stmt-*-context
+	if len(logs) != 16 {
+		t.Fatalf("unexpected numbered logs, expected: 16, recieved: %d %v", len(logs), logs)
+	}
+
+	for i, n := range logs {
+		if n.conn != logs[0].conn {
+			t.Errorf("unexpected connection id of %s, expected: %d, recieved: %d", n.topic, logs[0].conn, n.conn)
+		}
+		if n.seq != i+1 {
+			t.Errorf("unexpected sequence number of %s, expected: %d, recieved: %d", n.topic, i+1, n.seq)
+		}
+	}
+
+	if logs[len(logs)-1].topic != "conn-ping" {
+		t.Errorf("unexpected last numbered log, expected: conn-ping, recieved: %s", logs[len(logs)-1].topic)
+	}
+}
+
+func TestSequenceConnections(t *testing.T) {
+	var ids []uint64
+	l := EventLogger{
+		Callback: func(e Event) {
+			if conn, _, ok := Sequence(e.Ctx); ok {
+				ids = append(ids, conn)
+			}
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+
+	drv := &Driver{Driver: pingDriver{fakedb.Driver}, Logger: l}
+
+	for i := 0; i < 2; i++ {
+		conn, err := drv.Open("TestSequenceConnections")
+		if err != nil {
+			t.Fatalf("driver open error: %#v", err)
+		}
+
+		err = conn.(driver.Pinger).Ping(context.Background())
+		if err != nil {
+			t.Fatalf("conn ping error: %#v", err)
+		}
+
+		conn.Close()
+	}
+
+	if len(ids) != 2 || ids[0] == ids[1] {
+		t.Errorf("unexpected connection ids, expected: 2 unique, recieved: %v", ids)
+	}
+}
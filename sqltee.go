@@ -8,13 +8,20 @@ package sqltee
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/danil/sqltee/sqlteescan"
 )
 
 type Logger interface {
-	DriverOpen(d time.Duration, err error)
+	DriverOpen(name string, d time.Duration, err error)
 	ConnPrepare(d time.Duration, query string, err error)
 	ConnClose(d time.Duration, err error)
 	ConnBegin(d time.Duration, err error)
@@ -22,7 +29,7 @@ type Logger interface {
 	ConnPrepareContext(ctx context.Context, d time.Duration, query string, err error)
 	ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error)
 	ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error)
-	ConnPing(d time.Duration, err error)
+	ConnPing(ctx context.Context, d time.Duration, err error)
 	ConnQuery(d time.Duration, query string, dargs []driver.Value, err error)
 	ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error)
 	StmtClose(d time.Duration, err error)
@@ -33,27 +40,204 @@ type Logger interface {
 	RowsNext(d time.Duration, dest []driver.Value, err error)
 	TxCommit(d time.Duration, err error)
 	TxRollback(d time.Duration, err error)
+	TxSavepoint(ctx context.Context, d time.Duration, query, command, name string, err error)
 	Timer() Timer
 }
 
+// Register makes a database driver available by the provided name
+// which wraps the base driver and logs through the logger.
+// Unlike sql.Register it returns an error instead of panicking
+// if Register is called twice with the same name or if base is nil.
+func Register(name string, base driver.Driver, logger Logger) (err error) {
+	if base == nil {
+		return errors.New("sqltee: Register driver is nil")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	sql.Register(name, &Driver{Driver: base, Logger: logger})
+
+	return nil
+}
+
+// RegisterWrapped makes a database driver available by the new name
+// which wraps the base driver previously registered by the name
+// (for example by the import of the driver package) and logs through the logger.
+//
+// The database/sql does not allow to replace the driver of the opened *sql.DB,
+// so the existing databases can not be retrofitted: only the databases
+// opened by the new name are logged.
+func RegisterWrapped(name, newName string, logger Logger) error {
+	base, err := registered(name)
+	if err != nil {
+		return err
+	}
+
+	return Register(newName, base, logger)
+}
+
+// Open opens a database specified by the base database driver name
+// (previously registered by sql.Register) and a driver-specific data source name
+// which wraps the base driver and logs through the logger.
+func Open(name, dsn string, logger Logger) (*sql.DB, error) {
+	base, err := registered(name)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Driver{Driver: base, Logger: logger}
+
+	c, err := d.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.OpenDB(c), nil
+}
+
+// registered returns the driver registered by the name.
+// The sql.Open does not connect to the database,
+// so the *sql.DB is only used to get the driver.
+func registered(name string) (driver.Driver, error) {
+	db, err := sql.Open(name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	base := db.Driver()
+
+	err = db.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return base, nil
+}
+
 type Driver struct {
-	Driver driver.Driver
-	Logger Logger
+	Driver                   driver.Driver
+	Logger                   Logger
+	ExplainSlowerThan        time.Duration                                  // if positive then the plan of the SELECT query slower than ExplainSlowerThan is logged
+	WrapErrors               bool                                           // if true then the returned errors are wrapped with the topic of the operation (for example sqltee conn-exec: ...)
+	Clock                    Clock                                          // if not nil then used instead of the wall clock (for example to compute the deadline budget)
+	Role                     string                                         // if not blank then the logs are tagged by the database role (for example primary or replica) if the Logger implements RoleLogger
+	Correlate                bool                                           // if true then the logs of one logical query or execution share the correlation id if the Logger implements CorrelationLogger
+	StatementTimeout         time.Duration                                  // if positive then each execution or query with the context is canceled after StatementTimeout (the rows are canceled by the close)
+	RewriteQuery             func(ctx context.Context, query string) string // if not nil then rewrites the query of each execution, query or prepare with the context before the query is passed to the driver and logged (for example appends the sqlcommenter tags)
+	NPlusOneThreshold        int                                            // if positive then the executions of the same normalized query under the same context are flagged as the likely N+1 pattern after NPlusOneThreshold executions within the NPlusOneWindow (see NPlusOne)
+	NPlusOneWindow           time.Duration                                  // window of the N+1 detection, one second if not positive
+	ConsiderTxThreshold      int                                            // if positive then the consecutive writes (INSERT, UPDATE or DELETE) outside the transaction under the same context are flagged after ConsiderTxThreshold writes (see ConsiderTransaction)
+	RetryBadConn             int                                            // if positive then the driver-open failed by driver.ErrBadConn is retried up to RetryBadConn times and each retry is logged as the conn-retry if the Logger implements RetryLogger
+	RetryBackoff             func(retry int) time.Duration                  // time slept before the retry (1 is the first retry, see LinearBackoff and ExponentialBackoff), exponential from 10 milliseconds if nil
+	RequireContextInterfaces bool                                           // if true then the driver-open fails by the error wrapping ErrContextInterfaces if the connection does not implement driver.ConnPrepareContext, driver.ExecerContext and driver.QueryerContext instead of the silent fallbacks
+	LogConnect               bool                                           // if true then each Connector.Connect is timed and logged as the connector-connect if the Logger implements ConnectorLogger
+	PrepareRatioEvery        int                                            // if positive then each PrepareRatioEvery-th execution or query with the context holds the ratio of the prepares per execution (see PrepareRatio)
+	DetectStmtLeaks          bool                                           // if true then the conn-close of the connection used directly as the driver.Conn is tagged by the number of the unclosed prepared statements if the Logger implements StmtLeakLogger (the database/sql closes the statements before the connection, so the leaks of the *sql.DB are never detected)
+	stats                    stats                                          // statistics of the operations per topic (see Stats)
+	nPlusOne                 nPlusOneCounts                                 // executions per context and normalized query of the N+1 detection
+	considerTx               considerTxCounts                               // consecutive writes outside the transaction per context
+	prepareCounts            prepareCounts                                  // prepares and executions of the prepare ratio
+	versionOnce              sync.Once                                      // detects the version of the driver logged by the first driver-open (see DriverVersionLogger)
 }
 
 func (d *Driver) Open(name string) (driver.Conn, error) {
-	t := d.Logger.Timer()
-	var err error
+	return d.openContext(context.Background(), name)
+}
+
+func (d *Driver) openContext(ctx context.Context, name string) (driver.Conn, error) {
+	logger := newRecordLogger(d.logger(), &d.stats)
+	t := startTimer(logger)
+	var (
+		err     error
+		version string
+	)
 
-	defer func() { d.Logger.DriverOpen(t.Stop(), err) }()
+	defer func() {
+		l := logger
+		l.Logger = withDriverVersion(l.Logger, version)
+		l.DriverOpen(name, t.Stop(), err)
+	}()
 
 	var conn driver.Conn
-	conn, err = d.Driver.Open(name)
+	conn, err = d.open(ctx, logger, name)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(d.WrapErrors, "driver-open", err)
+	}
+
+	if d.RequireContextInterfaces {
+		err = requireContextInterfaces(conn)
+		if err != nil {
+			conn.Close()
+			return nil, wrapError(d.WrapErrors, "driver-open", err)
+		}
 	}
 
-	return connection{Logger: d.Logger, conn: conn}, nil
+	version = d.version(conn)
+
+	clock := d.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	c := connection{Logger: logger, conn: conn, explainSlowerThan: d.ExplainSlowerThan, statementTimeout: d.StatementTimeout, rewriteQuery: d.RewriteQuery, wrapErrors: d.WrapErrors, clock: clock, pingUnsupported: new(int32), readOnly: new(int32), inTx: new(int32), id: atomic.AddUint64(&connIDs, 1), seq: new(int)}
+	if d.Correlate {
+		c.correlation = new(correlation)
+	}
+	if d.DetectStmtLeaks {
+		c.stmts = new(int32)
+	}
+	if d.NPlusOneThreshold > 0 {
+		c.nPlusOne = nPlusOne{threshold: d.NPlusOneThreshold, window: d.NPlusOneWindow, counts: &d.nPlusOne}
+		if c.nPlusOne.window <= 0 {
+			c.nPlusOne.window = defaultNPlusOneWindow
+		}
+	}
+	if d.ConsiderTxThreshold > 0 {
+		c.considerTx = considerTx{threshold: d.ConsiderTxThreshold, counts: &d.considerTx}
+	}
+	if d.PrepareRatioEvery > 0 {
+		c.prepareRatio = prepareRatio{every: d.PrepareRatioEvery, counts: &d.prepareCounts}
+	}
+
+	return c, nil
+}
+
+// RoleLogger may be implemented by the Logger
+// to tag the logs by the database role of the driver,
+// so a single log stream distinguishes for example
+// the primary and the replica traffic (see Driver.Role).
+type RoleLogger interface {
+	WithRole(role string) Logger
+}
+
+// logger returns the Logger tagged by the role
+// if the role is not blank and the Logger implements RoleLogger.
+func (d *Driver) logger() Logger {
+	if d.Role == "" {
+		return d.Logger
+	}
+
+	if l, ok := d.Logger.(RoleLogger); ok {
+		return l.WithRole(d.Role)
+	}
+
+	return d.Logger
+}
+
+// Close closes the Logger if the Logger implements io.Closer
+// (for example flushes buffered records), otherwise Close does nothing.
+// Close should be called before the program exits
+// (after the database is closed by sql.DB.Close).
+func (d *Driver) Close() error {
+	if closer, ok := d.Logger.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
 }
 
 func (d *Driver) OpenConnector(name string) (driver.Connector, error) {
@@ -65,8 +249,18 @@ type Connector struct {
 	name   string
 }
 
-func (c Connector) Connect(_ context.Context) (driver.Conn, error) {
-	return c.driver.Open(c.name)
+func (c Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	if !c.driver.LogConnect {
+		return c.driver.openContext(ctx, c.name)
+	}
+
+	logger := newRecordLogger(c.driver.logger(), &c.driver.stats)
+	t := startTimer(logger)
+
+	conn, err := c.driver.openContext(ctx, c.name)
+	logger.ConnectorConnect(ctx, t.Stop(), err)
+
+	return conn, err
 }
 
 func (c Connector) Driver() driver.Driver {
@@ -75,33 +269,50 @@ func (c Connector) Driver() driver.Driver {
 
 type connection struct {
 	Logger
-	conn driver.Conn
+	conn              driver.Conn
+	explainSlowerThan time.Duration
+	statementTimeout  time.Duration
+	rewriteQuery      func(ctx context.Context, query string) string
+	wrapErrors        bool
+	clock             Clock
+	pingUnsupported   *int32       // non-zero if the unsupported ping has been logged
+	readOnly          *int32       // non-zero while the connection is in the read-only transaction
+	inTx              *int32       // non-zero while the connection is in the transaction
+	stmts             *int32       // number of the prepared statements of the connection which are not closed if the Driver.DetectStmtLeaks is true
+	id                uint64       // unique id of the connection
+	seq               *int         // sequence number of the last operation of the connection
+	correlation       *correlation // correlation id of the query skipped by the driver, nil if the correlation is disabled
+	nPlusOne          nPlusOne     // N+1 detection, disabled if the threshold is not positive
+	considerTx        considerTx   // detection of the writes outside the transaction, disabled if the threshold is not positive
+	prepareRatio      prepareRatio // accounting of the prepares per execution, disabled if every is not positive
 }
 
 func (c connection) Prepare(query string) (driver.Stmt, error) {
-	t := c.Logger.Timer()
+	t := startTimer(c.Logger)
 	var err error
 
-	defer func() { c.Logger.ConnPrepare(t.Stop(), query, err) }()
+	el := new(elapsed)
+	defer func() { c.Logger.ConnPrepare(el.add(t.Stop()), query, err) }()
 
 	var stmt driver.Stmt
 	stmt, err = c.conn.Prepare(query)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(c.wrapErrors, "conn-prepare", err)
 	}
 
-	return statement{Logger: c.Logger, query: query, stmt: stmt}, nil
+	c.prepared()
+	return statement{Logger: c.Logger, conn: c, query: query, stmt: stmt, elapsed: el, layout: new(sqlteescan.Layout)}, nil
 }
 
 func (c connection) Close() error {
-	t := c.Logger.Timer()
+	t := startTimer(c.Logger)
 	err := c.conn.Close()
-	c.Logger.ConnClose(t.Stop(), err)
-	return err
+	c.withStmtLeak(c.Logger).ConnClose(t.Stop(), err)
+	return wrapError(c.wrapErrors, "conn-close", err)
 }
 
 func (c connection) Begin() (driver.Tx, error) {
-	t := c.Logger.Timer()
+	t := startTimer(c.Logger)
 	var err error
 
 	defer func() { c.Logger.ConnBegin(t.Stop(), err) }()
@@ -109,91 +320,180 @@ func (c connection) Begin() (driver.Tx, error) {
 	var tx driver.Tx
 	tx, err = c.conn.Begin()
 	if err != nil {
-		return nil, err
+		return nil, wrapError(c.wrapErrors, "conn-begin", err)
+	}
+
+	if c.inTx != nil {
+		atomic.StoreInt32(c.inTx, 1)
 	}
 
-	return transaction{Logger: c.Logger, tx: tx}, nil
+	return transaction{Logger: c.Logger, tx: tx, wrapErrors: c.wrapErrors, inTx: c.inTx}, nil
 }
 
 func (c connection) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
 	var (
 		tx  driver.Tx
-		t   = c.Logger.Timer()
+		t   = startTimer(c.Logger)
 		err error
 	)
 
-	defer func() { c.Logger.ConnBeginTx(ctx, t.Stop(), opts, err) }()
+	sctx := c.withSequence(ctx)
+	defer func() { c.Logger.ConnBeginTx(sctx, recordDuration(ctx, t.Stop(), err), opts, err) }()
 
 	if connBeginTx, ok := c.conn.(driver.ConnBeginTx); ok {
 		tx, err = connBeginTx.BeginTx(ctx, opts)
-		if err != nil {
-			return nil, err
-		}
+	} else {
+		tx, err = c.conn.Begin()
+	}
+	if err != nil {
+		return nil, wrapError(c.wrapErrors, "conn-begin-tx", err)
+	}
 
-		return transaction{Logger: c.Logger, ctx: ctx, tx: tx}, nil
+	if opts.ReadOnly {
+		atomic.StoreInt32(c.readOnly, 1)
+	}
+	if c.inTx != nil {
+		atomic.StoreInt32(c.inTx, 1)
+	}
+	if c.considerTx.threshold > 0 {
+		c.considerTx.counts.reset(ctx)
 	}
 
-	tx, err = c.conn.Begin()
+	return transaction{Logger: c.Logger, ctx: ctx, tx: tx, wrapErrors: c.wrapErrors, readOnly: c.readOnly, inTx: c.inTx}, nil
+}
+
+func (c connection) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query = c.rewrite(ctx, query)
+	c = c.correlatedPrepare(query)
+
+	connPrepareCtx, ok := c.conn.(driver.ConnPrepareContext)
+	if !ok {
+		return c.prepareFallback(ctx, query)
+	}
+
+	t := startTimer(c.Logger)
+	var err error
+
+	el := new(elapsed)
+	sctx := c.withSequence(ctx)
+	defer func() { c.Logger.ConnPrepareContext(sctx, recordDuration(ctx, el.add(t.Stop()), err), query, err) }()
+
+	var stmt driver.Stmt
+	stmt, err = connPrepareCtx.PrepareContext(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(c.wrapErrors, "conn-prepare-context", err)
 	}
 
-	return transaction{Logger: c.Logger, ctx: ctx, tx: tx}, nil
+	c.prepared()
+	return statement{Logger: c.Logger, conn: c, ctx: ctx, query: query, stmt: stmt, elapsed: el, layout: new(sqlteescan.Layout)}, nil
 }
 
-func (c connection) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
-	t := c.Logger.Timer()
+// PrepareFallbackLogger may be implemented by the Logger to log
+// the PrepareContext which falls back to the driver.Conn.Prepare
+// because the driver does not support driver.ConnPrepareContext
+// (the fallback is logged by the ConnPrepare otherwise).
+type PrepareFallbackLogger interface {
+	ConnPrepareFallback(ctx context.Context, d time.Duration, query string, err error)
+}
+
+// logPrepareFallback logs the fallback by the ConnPrepareFallback
+// if the logger implements PrepareFallbackLogger or by the ConnPrepare otherwise.
+func logPrepareFallback(l Logger, ctx context.Context, d time.Duration, query string, err error) {
+	if fl, ok := l.(PrepareFallbackLogger); ok {
+		fl.ConnPrepareFallback(ctx, d, query, err)
+		return
+	}
+	l.ConnPrepare(d, query, err)
+}
+
+// prepareFallback prepares the statement by driver.Conn.Prepare
+// if the driver does not support driver.ConnPrepareContext.
+func (c connection) prepareFallback(ctx context.Context, query string) (driver.Stmt, error) {
+	t := startTimer(c.Logger)
 	var err error
 
-	defer func() { c.Logger.ConnPrepareContext(ctx, t.Stop(), query, err) }()
+	el := new(elapsed)
+	sctx := c.withSequence(ctx)
+	defer func() { logPrepareFallback(c.Logger, sctx, recordDuration(ctx, el.add(t.Stop()), err), query, err) }()
 
-	if connPrepareCtx, ok := c.conn.(driver.ConnPrepareContext); ok {
-		var stmt driver.Stmt
-		stmt, err = connPrepareCtx.PrepareContext(ctx, query)
-		if err != nil {
-			return nil, err
-		}
+	select {
+	default:
+	case <-ctx.Done():
+		err = ctx.Err()
+		return nil, wrapError(c.wrapErrors, "conn-prepare-fallback", err)
+	}
 
-		return statement{Logger: c.Logger, ctx: ctx, stmt: stmt}, nil
+	var stmt driver.Stmt
+	stmt, err = c.conn.Prepare(query)
+	if err != nil {
+		return nil, wrapError(c.wrapErrors, "conn-prepare-fallback", err)
 	}
 
-	return c.Prepare(query)
+	c.prepared()
+	return statement{Logger: c.Logger, conn: c, ctx: ctx, query: query, stmt: stmt, elapsed: el, layout: new(sqlteescan.Layout)}, nil
 }
 
 func (c connection) Exec(query string, dargs []driver.Value) (driver.Result, error) {
 	var (
-		t   = c.Logger.Timer()
+		t   = startTimer(c.Logger)
 		res driver.Result
 		err error
 	)
 
-	defer func() { c.Logger.ConnExec(t.Stop(), query, dargs, res, err) }()
+	sp, isSavepoint := parseSavepoint(query)
+	defer func() {
+		if isSavepoint {
+			c.Logger.TxSavepoint(context.Background(), t.Stop(), query, sp.command, sp.name, err)
+		} else {
+			c.Logger.ConnExec(t.Stop(), query, dargs, res, err)
+		}
+	}()
 
 	if execer, ok := c.conn.(driver.Execer); ok {
 		res, err = execer.Exec(query, dargs)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(c.wrapErrors, "conn-exec", err)
 		}
 
 		return result{Logger: c.Logger, result: res}, nil
 	}
 
+	err = ErrSkipUnsupported
 	return nil, driver.ErrSkip
 }
 
 func (c connection) ExecContext(ctx context.Context, query string, nvdargs []driver.NamedValue) (driver.Result, error) {
+	c, id := c.correlated()
+
 	var (
-		t   = c.Logger.Timer()
-		res driver.Result
-		err error
+		t        = startTimer(c.Logger)
+		res      driver.Result
+		err      error
+		fallback bool
 	)
 
-	defer func() { c.Logger.ConnExecContext(ctx, t.Stop(), query, nvdargs, res, err) }()
+	sp, isSavepoint := parseSavepoint(query)
+	query = c.rewrite(ctx, query)
+
+	rctx := ctx
+	ctx, cancel := c.withStatementTimeout(ctx)
+	defer cancel()
+
+	bctx := c.withImplicitCommit(c.withReadOnlyViolation(withDeadlineBudget(c.withSequence(ctx), c.clock), query), query)
+	defer func() {
+		bctx := withFallback(c.withPrepareRatio(c.withConsiderTx(c.withNPlusOne(bctx, rctx, query, err), rctx, query, err), err), fallback)
+		if isSavepoint {
+			c.Logger.TxSavepoint(bctx, recordDuration(ctx, t.Stop(), err), query, sp.command, sp.name, err)
+		} else {
+			c.Logger.ConnExecContext(bctx, recordDuration(ctx, t.Stop(), err), query, nvdargs, res, err)
+		}
+		c.skipped(query, id, err)
+	}()
 
 	if execContext, ok := c.conn.(driver.ExecerContext); ok {
 		res, err = execContext.ExecContext(ctx, query, nvdargs)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(c.wrapErrors, "conn-exec-context", err)
 		}
 
 		return result{Logger: c.Logger, ctx: ctx, result: res}, nil
@@ -202,80 +502,149 @@ func (c connection) ExecContext(ctx context.Context, query string, nvdargs []dri
 	var dargs []driver.Value
 	dargs, err = namedValueToValue(nvdargs)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(c.wrapErrors, "conn-exec-context", err)
 	}
 
 	select {
 	default:
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, wrapError(c.wrapErrors, "conn-exec-context", ctx.Err())
 	}
 
-	return c.Exec(query, dargs)
+	fallback = true
+	res, xerr := c.Exec(query, dargs)
+	c.skipped(query, id, xerr)
+
+	return res, xerr
 }
 
-func (c connection) Ping(ctx context.Context) error {
-	t := c.Logger.Timer()
-	var err error
+// ErrPingUnsupported is logged by the conn-ping once per connection
+// if the underlying connection does not implement driver.Pinger
+// so the health checks of the database/sql can not verify the connection.
+// Ping returns nil in this case as the database/sql expects.
+var ErrPingUnsupported = errors.New("sqltee: ping unsupported")
 
-	defer func() { c.Logger.ConnPing(t.Stop(), err) }()
+// ErrSkipUnsupported is logged by the conn-exec and the conn-query
+// instead of the driver.ErrSkip if the underlying connection does not
+// implement driver.Execer or driver.Queryer, so the loggers may distinguish
+// the missing fast-path from the driver.ErrSkip returned by the driver itself
+// for the specific query. The database/sql receives the driver.ErrSkip as is.
+var ErrSkipUnsupported = fmt.Errorf("sqltee: fast-path unsupported: %w", driver.ErrSkip)
 
-	if pinger, ok := c.conn.(driver.Pinger); ok {
-		err = pinger.Ping(ctx)
-		return err
+func (c connection) Ping(ctx context.Context) error {
+	t := startTimer(c.Logger)
+
+	pinger, ok := c.conn.(driver.Pinger)
+	if !ok {
+		d := t.Stop()
+		if atomic.CompareAndSwapInt32(c.pingUnsupported, 0, 1) {
+			c.Logger.ConnPing(c.withSequence(ctx), d, ErrPingUnsupported)
+		}
+		return nil
 	}
 
-	return nil
+	var err error
+
+	sctx := c.withSequence(ctx)
+	defer func() { c.Logger.ConnPing(sctx, recordDuration(ctx, t.Stop(), err), err) }()
+
+	err = pinger.Ping(ctx)
+	return wrapError(c.wrapErrors, "conn-ping", err)
 }
 
 func (c connection) Query(query string, dargs []driver.Value) (driver.Rows, error) {
-	t := c.Logger.Timer()
+	t := startTimer(c.Logger)
 	var err error
 
-	defer func() { c.Logger.ConnQuery(t.Stop(), query, dargs, err) }()
+	ex := c.explanation(nil, query, dargs, nil)
+	defer func() { c.Logger.ConnQuery(ex.stop(t.Stop()), query, dargs, err) }()
 
 	if queryer, ok := c.conn.(driver.Queryer); ok {
 		var rows driver.Rows
 		rows, err = queryer.Query(query, dargs)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(c.wrapErrors, "conn-query", err)
 		}
 
-		return rowsIterator{Logger: c.Logger, rows: rows}, nil
+		return newRows(rowsIterator{Logger: c.Logger, rows: rows, explanation: ex, wrapErrors: c.wrapErrors}), nil
 	}
 
+	err = ErrSkipUnsupported
 	return nil, driver.ErrSkip
 }
 
 func (c connection) QueryContext(ctx context.Context, query string, nvdargs []driver.NamedValue) (driver.Rows, error) {
-	t := c.Logger.Timer()
-	var err error
+	c, id := c.correlated()
+
+	t := startTimer(c.Logger)
+	var (
+		err      error
+		fallback bool
+	)
 
-	defer func() { c.Logger.ConnQueryContext(ctx, t.Stop(), query, nvdargs, err) }()
+	query = c.rewrite(ctx, query)
+	ex := c.explanation(ctx, query, nil, nvdargs)
+	rctx := ctx
+	ctx, cancel := c.withStatementTimeout(ctx)
+	bctx := c.withImplicitCommit(c.withReadOnlyViolation(withDeadlineBudget(c.withSequence(ctx), c.clock), query), query)
+	defer func() {
+		bctx := withFallback(c.withPrepareRatio(c.withConsiderTx(c.withNPlusOne(bctx, rctx, query, err), rctx, query, err), err), fallback)
+		c.Logger.ConnQueryContext(bctx, recordDuration(ctx, ex.stop(t.Stop()), err), query, nvdargs, err)
+		c.skipped(query, id, err)
+	}()
 
 	if queryerContext, ok := c.conn.(driver.QueryerContext); ok {
 		var rows driver.Rows
 		rows, err = queryerContext.QueryContext(ctx, query, nvdargs)
 		if err != nil {
-			return nil, err
+			cancel()
+			return nil, wrapError(c.wrapErrors, "conn-query-context", err)
 		}
 
-		return rowsIterator{Logger: c.Logger, ctx: ctx, rows: rows}, nil
+		return newRows(rowsIterator{Logger: c.Logger, ctx: ctx, rows: rows, explanation: ex, wrapErrors: c.wrapErrors, cancel: cancel}), nil
 	}
 
+	defer cancel()
+
 	var dargs []driver.Value
 	dargs, err = namedValueToValue(nvdargs)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(c.wrapErrors, "conn-query-context", err)
 	}
 
 	select {
 	default:
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, wrapError(c.wrapErrors, "conn-query-context", ctx.Err())
 	}
 
-	return c.Query(query, dargs)
+	fallback = true
+	rows, qerr := c.Query(query, dargs)
+	c.skipped(query, id, qerr)
+
+	return rows, qerr
+}
+
+// RawLogger may be implemented by the Logger to log the raw escape
+// (see connection.Unwrap) because the calls of the underlying
+// connection bypass the logger.
+type RawLogger interface {
+	ConnRaw()
+}
+
+// Unwrap returns the underlying driver connection (for example for
+// the driver-specific calls by sql.Conn.Raw) and logs the raw escape
+// if the Logger implements RawLogger.
+func (c connection) Unwrap() driver.Conn {
+	logRaw(c.Logger)
+	return c.conn
+}
+
+// logRaw logs the raw escape if the logger implements RawLogger.
+func logRaw(l Logger) {
+	if rl, ok := l.(RawLogger); ok {
+		rl.ConnRaw()
+	}
 }
 
 func (c connection) ResetSession(ctx context.Context) error {
@@ -286,6 +655,22 @@ func (c connection) ResetSession(ctx context.Context) error {
 	return driver.ErrSkip
 }
 
+// ResultAllRowsAffected may be implemented by driver.Result
+// of the multiple statements executed by the one Exec call.
+// AllRowsAffected returns the number of rows affected by each statement.
+type ResultAllRowsAffected interface {
+	driver.Result
+	AllRowsAffected() []int64
+}
+
+// ResultWarnings may be implemented by driver.Result
+// of the statement which produced the warnings (for example the notices
+// of the MySQL SHOW WARNINGS). Warnings returns the messages of the warnings.
+type ResultWarnings interface {
+	driver.Result
+	Warnings() []string
+}
+
 type result struct {
 	Logger
 	ctx    context.Context
@@ -302,16 +687,20 @@ func (r result) RowsAffected() (int64, error) {
 
 type statement struct {
 	Logger
-	ctx   context.Context
-	query string
-	stmt  driver.Stmt
+	conn    connection
+	ctx     context.Context
+	query   string
+	stmt    driver.Stmt
+	elapsed *elapsed
+	layout  *sqlteescan.Layout // placeholder layout of the query reused by the loggers
 }
 
 func (s statement) Close() error {
-	t := s.Logger.Timer()
+	t := startTimer(s.Logger)
 	err := s.stmt.Close()
-	s.Logger.StmtClose(t.Stop(), err)
-	return err
+	s.conn.closed()
+	logStmtClose(s.Logger, s.elapsed.add(t.Stop()), s.elapsed.total(), err)
+	return wrapError(s.conn.wrapErrors, "stmt-close", err)
 }
 
 func (s statement) NumInput() int {
@@ -320,16 +709,23 @@ func (s statement) NumInput() int {
 
 func (s statement) Exec(dargs []driver.Value) (driver.Result, error) {
 	var (
-		t   = s.Logger.Timer()
+		t   = startTimer(s.Logger)
 		res driver.Result
 		err error
 	)
 
-	defer func() { s.Logger.StmtExec(t.Stop(), s.query, dargs, res, err) }()
+	sp, isSavepoint := parseSavepoint(s.query)
+	defer func() {
+		if isSavepoint {
+			s.Logger.TxSavepoint(s.ctx, s.elapsed.add(t.Stop()), s.query, sp.command, sp.name, err)
+		} else {
+			s.Logger.StmtExec(s.elapsed.add(t.Stop()), s.query, dargs, res, err)
+		}
+	}()
 
 	res, err = s.stmt.Exec(dargs)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(s.conn.wrapErrors, "stmt-exec", err)
 	}
 
 	return result{Logger: s.Logger, ctx: s.ctx, result: res}, nil
@@ -337,17 +733,31 @@ func (s statement) Exec(dargs []driver.Value) (driver.Result, error) {
 
 func (s statement) ExecContext(ctx context.Context, nvdargs []driver.NamedValue) (driver.Result, error) {
 	var (
-		t   = s.Logger.Timer()
+		t   = startTimer(s.Logger)
 		res driver.Result
 		err error
 	)
 
-	defer func() { s.Logger.StmtExecContext(ctx, t.Stop(), s.query, nvdargs, res, err) }()
+	rctx := ctx
+	ctx, cancel := s.conn.withStatementTimeout(ctx)
+	defer cancel()
+
+	el := s.elapsed
+	bctx := s.conn.withImplicitCommit(s.conn.withReadOnlyViolation(withParamTypes(withQueryLayout(withDeadlineBudget(s.conn.withSequence(ctx), s.conn.clock), s.layout), s.stmt), s.query), s.query)
+	sp, isSavepoint := parseSavepoint(s.query)
+	defer func() {
+		bctx := s.conn.withPrepareRatio(s.conn.withConsiderTx(s.conn.withNPlusOne(bctx, rctx, s.query, err), rctx, s.query, err), err)
+		if isSavepoint {
+			s.Logger.TxSavepoint(bctx, recordDuration(ctx, el.add(t.Stop()), err), s.query, sp.command, sp.name, err)
+		} else {
+			s.Logger.StmtExecContext(bctx, recordDuration(ctx, el.add(t.Stop()), err), s.query, nvdargs, res, err)
+		}
+	}()
 
 	if stmtExecContext, ok := s.stmt.(driver.StmtExecContext); ok {
 		res, err = stmtExecContext.ExecContext(ctx, nvdargs)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(s.conn.wrapErrors, "stmt-exec-context", err)
 		}
 
 		return result{Logger: s.Logger, ctx: ctx, result: res}, nil
@@ -356,103 +766,180 @@ func (s statement) ExecContext(ctx context.Context, nvdargs []driver.NamedValue)
 	var dargs []driver.Value
 	dargs, err = namedValueToValue(nvdargs)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(s.conn.wrapErrors, "stmt-exec-context", err)
 	}
 
 	select {
 	default:
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, wrapError(s.conn.wrapErrors, "stmt-exec-context", ctx.Err())
 	}
 
+	el = nil // duration is accumulated by the Exec
 	return s.Exec(dargs)
 }
 
 func (s statement) Query(dargs []driver.Value) (driver.Rows, error) {
-	t := s.Logger.Timer()
+	t := startTimer(s.Logger)
 	var err error
 
-	defer func() { s.Logger.StmtQuery(t.Stop(), s.query, dargs, err) }()
+	ex := s.conn.explanation(s.ctx, s.query, dargs, nil)
+	defer func() { s.Logger.StmtQuery(s.elapsed.add(ex.stop(t.Stop())), s.query, dargs, err) }()
 
 	var rows driver.Rows
 	rows, err = s.stmt.Query(dargs)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(s.conn.wrapErrors, "stmt-query", err)
 	}
 
-	return rowsIterator{Logger: s.Logger, ctx: s.ctx, rows: rows}, nil
+	return newRows(rowsIterator{Logger: s.Logger, ctx: s.ctx, rows: rows, explanation: ex, wrapErrors: s.conn.wrapErrors}), nil
 }
 
 func (s statement) QueryContext(ctx context.Context, nvdargs []driver.NamedValue) (driver.Rows, error) {
-	t := s.Logger.Timer()
+	t := startTimer(s.Logger)
 	var err error
 
-	defer func() { s.Logger.StmtQueryContext(ctx, t.Stop(), s.query, nvdargs, err) }()
+	ex := s.conn.explanation(ctx, s.query, nil, nvdargs)
+	rctx := ctx
+	ctx, cancel := s.conn.withStatementTimeout(ctx)
+	el := s.elapsed
+	bctx := s.conn.withImplicitCommit(s.conn.withReadOnlyViolation(withParamTypes(withQueryLayout(withDeadlineBudget(s.conn.withSequence(ctx), s.conn.clock), s.layout), s.stmt), s.query), s.query)
+	defer func() {
+		bctx := s.conn.withPrepareRatio(s.conn.withConsiderTx(s.conn.withNPlusOne(bctx, rctx, s.query, err), rctx, s.query, err), err)
+		s.Logger.StmtQueryContext(bctx, recordDuration(ctx, el.add(ex.stop(t.Stop())), err), s.query, nvdargs, err)
+	}()
 
 	if stmtQueryContext, ok := s.stmt.(driver.StmtQueryContext); ok {
 		var rows driver.Rows
 		rows, err = stmtQueryContext.QueryContext(ctx, nvdargs)
 		if err != nil {
-			return nil, err
+			cancel()
+			return nil, wrapError(s.conn.wrapErrors, "stmt-query-context", err)
 		}
 
-		return rowsIterator{Logger: s.Logger, ctx: ctx, rows: rows}, nil
+		return newRows(rowsIterator{Logger: s.Logger, ctx: ctx, rows: rows, explanation: ex, wrapErrors: s.conn.wrapErrors, cancel: cancel}), nil
 	}
 
+	defer cancel()
+
 	var dargs []driver.Value
 	dargs, err = namedValueToValue(nvdargs)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(s.conn.wrapErrors, "stmt-query-context", err)
 	}
 
 	select {
 	default:
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, wrapError(s.conn.wrapErrors, "stmt-query-context", ctx.Err())
 	}
 
+	el = nil // duration is accumulated by the Query
 	return s.Query(dargs)
 }
 
+// RowLogger may be implemented by the Logger to log the rows-next
+// with the number of the row (1 is the first row of the rows, the number
+// of the io.EOF is the number of the rows plus one) and the columns
+// of the current result set (the RowsNext is logged otherwise).
+type RowLogger interface {
+	RowsNextRow(d time.Duration, row int, columns []string, dest []driver.Value, err error)
+}
+
+// logRowsNext logs the rows-next by the RowsNextRow
+// if the logger implements RowLogger or by the RowsNext otherwise.
+func logRowsNext(l Logger, d time.Duration, row int, columns []string, dest []driver.Value, err error) {
+	if rl, ok := l.(RowLogger); ok {
+		rl.RowsNextRow(d, row, columns, dest, err)
+		return
+	}
+	l.RowsNext(d, dest, err)
+}
+
 type rowsIterator struct {
 	Logger
-	ctx  context.Context
-	rows driver.Rows
+	ctx         context.Context
+	rows        driver.Rows
+	explanation *explanation
+	wrapErrors  bool
+	row         *int               // number of the Next calls
+	cols        *[]string          // columns of the current result set cached for the logger
+	cancel      context.CancelFunc // cancels the statement timeout of the query after the close, nil if the rows have no timeout
+}
+
+// Columns returns the columns of the underlying rows and caches them
+// for the logger. The database/sql requests the columns before
+// the first Next of each result set, so the cache follows NextResultSet.
+func (r rowsIterator) Columns() []string {
+	cols := r.rows.Columns()
+	if r.cols != nil {
+		*r.cols = cols
+	}
+	return cols
 }
 
-func (r rowsIterator) Columns() []string {
-	return r.rows.Columns()
+// columns returns the cached columns of the current result set
+// or caches the columns of the underlying rows if they have not been requested.
+func (r rowsIterator) columns() []string {
+	if r.cols == nil {
+		return r.rows.Columns()
+	}
+	if *r.cols == nil {
+		*r.cols = r.rows.Columns()
+	}
+	return *r.cols
 }
 
 func (r rowsIterator) Close() error {
-	return r.rows.Close()
+	err := r.rows.Close()
+	r.explanation.explain()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return err
 }
 
 func (r rowsIterator) Next(dest []driver.Value) error {
-	t := r.Logger.Timer()
+	t := startTimer(r.Logger)
 	err := r.rows.Next(dest)
-	r.Logger.RowsNext(t.Stop(), dest, err)
-	return err
+	*r.row++
+	logRowsNext(r.Logger, t.Stop(), *r.row, r.columns(), dest, err)
+	return wrapError(r.wrapErrors, "rows-next", err)
 }
 
 type transaction struct {
 	Logger
-	ctx context.Context
-	tx  driver.Tx
+	ctx        context.Context
+	tx         driver.Tx
+	wrapErrors bool
+	readOnly   *int32 // read-only flag of the connection reset by the end of the transaction
+	inTx       *int32 // transaction flag of the connection reset by the end of the transaction
 }
 
 func (tx transaction) Commit() error {
-	t := tx.Logger.Timer()
+	t := startTimer(tx.Logger)
 	err := tx.tx.Commit()
+	tx.end()
 	tx.Logger.TxCommit(t.Stop(), err)
-	return err
+	return wrapError(tx.wrapErrors, "tx-commit", err)
 }
 
 func (tx transaction) Rollback() error {
-	t := tx.Logger.Timer()
+	t := startTimer(tx.Logger)
 	err := tx.tx.Rollback()
+	tx.end()
 	tx.Logger.TxRollback(t.Stop(), err)
-	return err
+	return wrapError(tx.wrapErrors, "tx-rollback", err)
+}
+
+// end resets the read-only and the transaction flags of the connection.
+func (tx transaction) end() {
+	if tx.readOnly != nil {
+		atomic.StoreInt32(tx.readOnly, 0)
+	}
+	if tx.inTx != nil {
+		atomic.StoreInt32(tx.inTx, 0)
+	}
 }
 
 // namedValueToValue is a helper function copied from the database/sql package
@@ -467,6 +954,45 @@ func namedValueToValue(named []driver.NamedValue) ([]driver.Value, error) {
 	return dargs, nil
 }
 
+// StmtTotalLogger may be implemented by the Logger to log the stmt-close
+// with the total time of the statement from the prepare start
+// to the close end (the StmtClose is logged otherwise).
+type StmtTotalLogger interface {
+	StmtCloseTotal(d, total time.Duration, err error)
+}
+
+// logStmtClose logs the stmt-close by the StmtCloseTotal
+// if the logger implements StmtTotalLogger or by the StmtClose otherwise.
+func logStmtClose(l Logger, d, total time.Duration, err error) {
+	if tl, ok := l.(StmtTotalLogger); ok {
+		tl.StmtCloseTotal(d, total, err)
+		return
+	}
+	l.StmtClose(d, err)
+}
+
+// elapsed accumulates the execution time of the statement
+// from the prepare start to the close end.
+type elapsed struct {
+	d int64
+}
+
+// add accumulates and returns the duration.
+func (e *elapsed) add(d time.Duration) time.Duration {
+	if e != nil {
+		atomic.AddInt64(&e.d, int64(d))
+	}
+	return d
+}
+
+// total returns the accumulated duration.
+func (e *elapsed) total() time.Duration {
+	if e == nil {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&e.d))
+}
+
 type Timer interface {
 	Stop() time.Duration
 }
@@ -5,8 +5,13 @@
 package sqltee
 
 import (
+	"context"
+	"database/sql"
 	"database/sql/driver"
+	"sync"
 	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
 )
 
 func TestLogFuncSQLOpenDB(_ *testing.T) {
@@ -68,3 +73,116 @@ func TestLogFuncSQLOpenDB(_ *testing.T) {
 		_ driver.Tx = &transaction{}
 	)
 }
+
+func TestConnRaw(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		topics []string
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			topics = append(topics, e.Topic)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_conn_raw")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("db conn error: %#v", err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(dc interface{}) error {
+		u, ok := dc.(interface{ Unwrap() driver.Conn })
+		if !ok {
+			t.Fatalf("unexpected raw connection, expected unwrapper, recieved: %T", dc)
+		}
+
+		if raw, ok := u.Unwrap().(connection); ok {
+			t.Errorf("unexpected unwrapped connection, expected: driver connection, recieved: %T", raw)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("conn raw error: %#v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var n int
+	for _, topic := range topics {
+		if topic == "conn-raw" {
+			n++
+		}
+	}
+
+	if n != 1 {
+		t.Errorf("unexpected number of the conn-raw events, expected: %d, recieved: %d", 1, n)
+	}
+}
+
+func TestConnPingUnsupported(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+
+	l := EventLogger{
+		Callback: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		},
+		NewTimer: func() Timer { return fakeTimer{} },
+	}
+	drv := &Driver{Driver: failDriver{}, Logger: l}
+
+	c, err := drv.OpenConnector("")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	for i := 0; i < 3; i++ {
+		err = db.Ping()
+		if err != nil {
+			t.Fatalf("db ping error: %#v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var n int
+	for _, e := range events {
+		if e.Topic != "conn-ping" {
+			continue
+		}
+
+		if e.Err != ErrPingUnsupported {
+			t.Errorf("unexpected conn-ping error, expected: %v, recieved: %v", ErrPingUnsupported, e.Err)
+		}
+		n++
+	}
+
+	if n != 1 {
+		t.Errorf("unexpected number of the unsupported conn-ping events, expected: %d, recieved: %d", 1, n)
+	}
+}
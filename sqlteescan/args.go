@@ -0,0 +1,81 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// FormatArgs returns the parameters rendered by the ValueString
+// as the bracketed comma-separated list (for example [42, 'foo', NULL]),
+// the named parameters are prefixed by the name (for example [id=42]).
+// The positional parameters are followed by the named or ordinal parameters.
+// The values which ValueString does not support are rendered by fmt.
+func FormatArgs(dargs []driver.Value, nvdargs []driver.NamedValue) string {
+	return FormatArgsFunc(nil, dargs, nvdargs)
+}
+
+// FormatArgsFunc is like FormatArgs but renders the values
+// by the assert function (or by ValueString if assert is nil).
+func FormatArgsFunc(assert AssertFunc, dargs []driver.Value, nvdargs []driver.NamedValue) string {
+	return FormatTypedArgs(assert, nil, dargs, nvdargs)
+}
+
+// FormatTypedArgs is like FormatArgsFunc but annotates the values
+// by the SQL types of the parameters by position if the types
+// are not blank (for example [42::int8, 'foo'::text]).
+func FormatTypedArgs(assert AssertFunc, types []string, dargs []driver.Value, nvdargs []driver.NamedValue) string {
+	if assert == nil {
+		assert = ValueString
+	}
+
+	var b strings.Builder
+	b.WriteByte('[')
+
+	for i, v := range dargs {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(formatArg(assert, v))
+		writeType(&b, types, i)
+	}
+
+	for i, v := range nvdargs {
+		if i != 0 || len(dargs) != 0 {
+			b.WriteString(", ")
+		}
+		if v.Name != "" {
+			b.WriteString(v.Name)
+			b.WriteByte('=')
+		}
+		b.WriteString(formatArg(assert, v.Value))
+		writeType(&b, types, len(dargs)+i)
+	}
+
+	b.WriteByte(']')
+	return b.String()
+}
+
+func formatArg(assert AssertFunc, value interface{}) string {
+	if value == nil {
+		return "NULL"
+	}
+
+	s, err := assert(value)
+	if err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	return s
+}
+
+// writeType writes the SQL type of the parameter of the index if the type is known.
+func writeType(b *strings.Builder, types []string, i int) {
+	if i < len(types) && types[i] != "" {
+		b.WriteString("::")
+		b.WriteString(types[i])
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestFormatArgs(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		dargs    []driver.Value
+		nvdargs  []driver.NamedValue
+		expected string
+	}{
+		{
+			name:     "empty",
+			line:     line(),
+			expected: "[]",
+		},
+		{
+			name:     "positional",
+			line:     line(),
+			dargs:    []driver.Value{int64(42), "foo", nil},
+			expected: "[42, 'foo', NULL]",
+		},
+		{
+			name:     "positional bytes",
+			line:     line(),
+			dargs:    []driver.Value{[]byte("*foo"), true},
+			expected: `[E'\\x2a666f6f', TRUE]`,
+		},
+		{
+			name:     "ordinal",
+			line:     line(),
+			nvdargs:  []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "it's"}},
+			expected: "[42, 'it''s']",
+		},
+		{
+			name:     "named",
+			line:     line(),
+			nvdargs:  []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(42)}, {Name: "name", Ordinal: 2, Value: nil}},
+			expected: "[id=42, name=NULL]",
+		},
+		{
+			name:     "mix of named and ordinal",
+			line:     line(),
+			nvdargs:  []driver.NamedValue{{Ordinal: 1, Value: []byte{}}, {Name: "name", Ordinal: 2, Value: "foo"}},
+			expected: `[E'\\x', name='foo']`,
+		},
+		{
+			name:     "mix of positional and named",
+			line:     line(),
+			dargs:    []driver.Value{int64(1)},
+			nvdargs:  []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(2)}},
+			expected: "[1, id=2]",
+		},
+		{
+			name:     "unsupported value",
+			line:     line(),
+			dargs:    []driver.Value{[]int{1, 2}},
+			expected: "[[1 2]]",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			s := sqlteescan.FormatArgs(tt.dargs, tt.nvdargs)
+			if s != tt.expected {
+				t.Errorf("unexpected args, expected: %q, recieved: %q %s", tt.expected, s, tt.line)
+			}
+		})
+	}
+}
+
+func TestFormatArgsFunc(t *testing.T) {
+	s := sqlteescan.FormatArgsFunc(sqlteescan.SizeString(3, nil), []driver.Value{"foo bar", "baz"}, nil)
+	if s != "[<string:7>, 'baz']" {
+		t.Errorf("unexpected args, expected: %q, recieved: %q", "[<string:7>, 'baz']", s)
+	}
+}
+
+func TestFormatTypedArgs(t *testing.T) {
+	s := sqlteescan.FormatTypedArgs(nil, []string{"int8", "", "text"}, []driver.Value{int64(42), true}, []driver.NamedValue{{Name: "name", Ordinal: 1, Value: "foo"}})
+	if s != "[42::int8, TRUE, name='foo'::text]" {
+		t.Errorf("unexpected args, expected: %q, recieved: %q", "[42::int8, TRUE, name='foo'::text]", s)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dialect is a SQL dialect of the string representation of the SQL parameters,
+// so the interpolated query may be pasted into the console of the database.
+//
+//	            string     bytes      bool       time                                identifier
+//	PostgreSQL  'it''s'    E'\\x2a'   TRUE       '2021-01-02T03:04:05Z'              "a""b"
+//	MySQL       'it\'s'    X'2a'      TRUE       '2021-01-02 03:04:05' (UTC)         `a``b`
+//	SQLite      'it''s'    X'2a'      1          '2021-01-02 03:04:05+00:00'         "a""b"
+//	SQL Server  N'it''s'   0x2a       1          '2021-01-02T03:04:05Z'              [a]]b]
+//
+// NULL is the literal of the nil values of all dialects.
+type Dialect int
+
+const (
+	DialectDefault   Dialect = iota // PostgreSQL compatible literals
+	DialectSQLServer                // Microsoft SQL Server literals
+	DialectMySQL                    // MySQL literals (with the default sql_mode where the backslash is the escape character)
+	DialectSQLite                   // SQLite literals
+
+	DialectPostgreSQL = DialectDefault // PostgreSQL literals
+)
+
+// QuoteString returns the single-quoted string literal of the dialect.
+func (d Dialect) QuoteString(s string) string {
+	switch d {
+	case DialectSQLServer:
+		return "N'" + strings.ReplaceAll(s, "'", "''") + "'"
+
+	case DialectMySQL:
+		return "'" + mysqlEscaper.Replace(s) + "'"
+
+	default:
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+}
+
+// mysqlEscaper escapes the special characters of the MySQL string literal
+// <https://dev.mysql.com/doc/refman/8.0/en/string-literals.html>.
+var mysqlEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"'", "\\'",
+	"\x00", "\\0",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\x1a", "\\Z",
+)
+
+// QuoteBytes returns the binary string literal of the dialect.
+func (d Dialect) QuoteBytes(p []byte) string {
+	dst := make([]byte, hex.EncodedLen(len(p)))
+	hex.Encode(dst, p)
+
+	switch d {
+	case DialectSQLServer:
+		return fmt.Sprintf("0x%s", dst)
+
+	case DialectMySQL, DialectSQLite:
+		return fmt.Sprintf("X'%s'", dst)
+
+	default: // bytea hex format <https://www.postgresql.org/docs/current/datatype-binary.html#id-1.5.7.12.9>.
+		return fmt.Sprintf("E'\\\\x%s'", dst)
+	}
+}
+
+// Bool returns the boolean literal of the dialect.
+func (d Dialect) Bool(b bool) string {
+	switch d {
+	case DialectSQLServer, DialectSQLite:
+		if b {
+			return "1"
+		}
+		return "0"
+
+	default:
+		if b {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+}
+
+// Time returns the single-quoted time literal of the dialect,
+// the MySQL DATETIME has no time zone so the time is converted to UTC.
+func (d Dialect) Time(t time.Time) string {
+	switch d {
+	case DialectMySQL:
+		return "'" + t.UTC().Format("2006-01-02 15:04:05.999999") + "'"
+
+	case DialectSQLite:
+		return "'" + t.Format("2006-01-02 15:04:05.999999999-07:00") + "'"
+
+	default:
+		return time3339(t)
+	}
+}
+
+// QuoteIdentifier returns the quoted identifier of the dialect
+// (for example the table or the column name).
+func (d Dialect) QuoteIdentifier(name string) string {
+	switch d {
+	case DialectSQLServer:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+
+	case DialectMySQL:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+
+	default:
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+}
+
+// timeElement returns the unquoted time of the dialect as the element of the array.
+func (d Dialect) timeElement(t time.Time) string {
+	s := d.Time(t)
+	return s[1 : len(s)-1]
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestDialectMatrix(t *testing.T) {
+	tm := time.Date(2021, 1, 2, 3, 4, 5, 0, time.FixedZone("UTC+3", 3*60*60))
+
+	type cell struct {
+		in   interface{}
+		want string
+	}
+
+	var tests = []struct {
+		name       string
+		line       string
+		dialect    sqlteescan.Dialect
+		str        cell
+		special    cell
+		bytes      cell
+		boolTrue   cell
+		boolFalse  cell
+		null       cell
+		time       cell
+		identifier cell
+	}{
+		{
+			name:       "postgresql",
+			line:       line(),
+			dialect:    sqlteescan.DialectPostgreSQL,
+			str:        cell{in: "it's", want: "'it''s'"},
+			special:    cell{in: `a\b`, want: `'a\b'`},
+			bytes:      cell{in: []byte("*"), want: `E'\\x2a'`},
+			boolTrue:   cell{in: true, want: "TRUE"},
+			boolFalse:  cell{in: false, want: "FALSE"},
+			null:       cell{in: nil, want: "NULL"},
+			time:       cell{in: tm, want: "'2021-01-02T03:04:05+03:00'"},
+			identifier: cell{in: `a"b`, want: `"a""b"`},
+		},
+		{
+			name:       "mysql",
+			line:       line(),
+			dialect:    sqlteescan.DialectMySQL,
+			str:        cell{in: "it's", want: `'it\'s'`},
+			special:    cell{in: "a\\b\n", want: `'a\\b\n'`},
+			bytes:      cell{in: []byte("*"), want: "X'2a'"},
+			boolTrue:   cell{in: true, want: "TRUE"},
+			boolFalse:  cell{in: false, want: "FALSE"},
+			null:       cell{in: nil, want: "NULL"},
+			time:       cell{in: tm, want: "'2021-01-02 00:04:05'"},
+			identifier: cell{in: "a`b", want: "`a``b`"},
+		},
+		{
+			name:       "sqlite",
+			line:       line(),
+			dialect:    sqlteescan.DialectSQLite,
+			str:        cell{in: "it's", want: "'it''s'"},
+			special:    cell{in: `a\b`, want: `'a\b'`},
+			bytes:      cell{in: []byte("*"), want: "X'2a'"},
+			boolTrue:   cell{in: true, want: "1"},
+			boolFalse:  cell{in: false, want: "0"},
+			null:       cell{in: nil, want: "NULL"},
+			time:       cell{in: tm, want: "'2021-01-02 03:04:05+03:00'"},
+			identifier: cell{in: `a"b`, want: `"a""b"`},
+		},
+		{
+			name:       "sql server",
+			line:       line(),
+			dialect:    sqlteescan.DialectSQLServer,
+			str:        cell{in: "it's", want: "N'it''s'"},
+			special:    cell{in: `a\b`, want: `N'a\b'`},
+			bytes:      cell{in: []byte("*"), want: "0x2a"},
+			boolTrue:   cell{in: true, want: "1"},
+			boolFalse:  cell{in: false, want: "0"},
+			null:       cell{in: nil, want: "NULL"},
+			time:       cell{in: tm, want: "'2021-01-02T03:04:05+03:00'"},
+			identifier: cell{in: "a]b", want: "[a]]b]"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			for name, c := range map[string]cell{
+				"string":         tt.str,
+				"special string": tt.special,
+				"bytes":          tt.bytes,
+				"true":           tt.boolTrue,
+				"false":          tt.boolFalse,
+				"null":           tt.null,
+				"time":           tt.time,
+			} {
+				s, err := tt.dialect.ValueString(c.in)
+				if err != nil {
+					t.Fatalf("unexpected error of the %s: %s %s", name, err, tt.line)
+				}
+
+				if s != c.want {
+					t.Errorf("unexpected %s, want: %q, recieved: %q %s", name, c.want, s, tt.line)
+				}
+			}
+
+			if s := tt.dialect.QuoteIdentifier(tt.identifier.in.(string)); s != tt.identifier.want {
+				t.Errorf("unexpected identifier, want: %q, recieved: %q %s", tt.identifier.want, s, tt.line)
+			}
+		})
+	}
+}
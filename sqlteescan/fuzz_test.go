@@ -0,0 +1,65 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func FuzzInterpolate(f *testing.F) {
+	f.Add("SELECT * FROM foo WHERE id = ? AND name = ?", uint8(2), uint8(0), false)
+	f.Add("SELECT * FROM foo WHERE id = $1 AND name = $2", uint8(2), uint8(0), true)
+	f.Add("SELECT * FROM foo WHERE id IN ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)", uint8(11), uint8(0), true)
+	f.Add("SELECT * FROM foo WHERE id = $1 OR parent_id = $1", uint8(1), uint8(0), true)
+	f.Add("SELECT '?' FROM foo WHERE id = ?", uint8(1), uint8(0), false)
+	f.Add("SELECT * FROM foo WHERE id IN (?, ?, ?, ?, ?)", uint8(5), uint8(2), false)
+	f.Add("SELECT * FROM foo WHERE id IN ($1, $2, $3)", uint8(3), uint8(1), true)
+	f.Add("?", uint8(3), uint8(0), false)
+	f.Add("", uint8(1), uint8(0), true)
+
+	f.Fuzz(func(t *testing.T, query string, nargs, maxArgs uint8, ordinal bool) {
+		scan := sqlteescan.GetScanner()
+		defer sqlteescan.PutScanner(scan)
+		scan.MaxArgs = int(maxArgs)
+		scan.Assert = func(interface{}) (string, error) { return "<v>", nil }
+
+		if ordinal {
+			for i := 0; i < int(nargs); i++ {
+				scan.NamedValues = append(scan.NamedValues, driver.NamedValue{Ordinal: i + 1, Value: int64(i)})
+			}
+		} else {
+			for i := 0; i < int(nargs); i++ {
+				scan.Values = append(scan.Values, int64(i))
+			}
+		}
+
+		s, err := scan.Interpolate(query, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		// Each substitution replaces at least one byte of the query
+		// by the three bytes of the value and the marker
+		// of the rest parameters may be appended.
+		max := len(query)*3 + len(" …(+255 more args)")
+		if len(s) > max {
+			t.Errorf("unexpected interpolation length, expected: less or equal than %d, recieved: %d", max, len(s))
+		}
+
+		if !ordinal && s != "" {
+			n := strings.Count(s, "<v>") - strings.Count(query, "<v>")
+			if n > int(nargs) {
+				t.Errorf("unexpected number of substitutions, expected: less or equal than %d, recieved: %d", nargs, n)
+			}
+			if maxArgs > 0 && n > int(maxArgs) {
+				t.Errorf("unexpected number of substitutions, expected: less or equal than max %d, recieved: %d", maxArgs, n)
+			}
+		}
+	})
+}
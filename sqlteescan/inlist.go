@@ -0,0 +1,202 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import (
+	"database/sql/driver"
+	"strconv"
+	"strings"
+)
+
+const (
+	inListMin  = 8 // minimum number of the placeholders of the compressed IN list
+	inListHead = 3 // number of the placeholders kept at the beginning of the compressed IN list
+)
+
+// CompressInLists returns the query where each IN list of more than
+// eight placeholders (for example IN ($1, $2, ..., $500)) is compressed
+// to the first three placeholders and the marker of the number of values
+// (for example IN ($1, $2, $3, … 500 values …)) and the parameters
+// without the values of the dropped placeholders, so the interpolation
+// of the compressed query stays compact. If the placeholder is not blank
+// then it is used as explicit placeholder instead of placeholder from parameters.
+// The query and the parameters are returned as is if there is nothing to compress.
+func CompressInLists(query, placeholder string, dargs []driver.Value, nvdargs []driver.NamedValue) (string, []driver.Value, []driver.NamedValue) {
+	var (
+		b       strings.Builder
+		last    int
+		dropped map[int]bool    // indexes of the dropped positional parameters
+		names   map[string]bool // identifiers of the dropped named or ordinal parameters
+	)
+
+	for i := 0; i < len(query); {
+		start, items, end, ok := inList(query, i, placeholder)
+		if !ok {
+			break
+		}
+		i = end
+
+		if len(items) <= inListMin {
+			continue
+		}
+
+		if b.Len() == 0 {
+			b.Grow(len(query))
+			dropped = map[int]bool{}
+			names = map[string]bool{}
+		}
+
+		b.WriteString(query[last:start])
+		for j, item := range items[:inListHead] {
+			if j != 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(query[item[0]:item[1]])
+		}
+		b.WriteString(", … ")
+		b.WriteString(strconv.Itoa(len(items)))
+		b.WriteString(" values …")
+		last = items[len(items)-1][1]
+
+		for _, item := range items[inListHead:] {
+			name := query[item[0]:item[1]]
+			if placeholder != "" {
+				dropped[strings.Count(query[:item[0]], placeholder)] = true
+			} else if name == "?" {
+				dropped[strings.Count(query[:item[0]], "?")] = true
+			} else {
+				names[name] = true
+			}
+		}
+	}
+
+	if b.Len() == 0 {
+		return query, dargs, nvdargs
+	}
+
+	b.WriteString(query[last:])
+	query = b.String()
+
+	// named identifier may be used outside of the compressed list too
+	for name := range names {
+		if indexToken(query, name, 0) != -1 {
+			delete(names, name)
+		}
+	}
+
+	if len(dargs) != 0 {
+		values := make([]driver.Value, 0, len(dargs))
+		for i, v := range dargs {
+			if !dropped[i] {
+				values = append(values, v)
+			}
+		}
+		return query, values, nvdargs
+	}
+
+	values := make([]driver.NamedValue, 0, len(nvdargs))
+	for i, v := range nvdargs {
+		name := v.Name
+		if name == "" && v.Ordinal != 0 {
+			name = "$" + strconv.Itoa(v.Ordinal)
+		}
+		if dropped[i] || names[name] {
+			continue
+		}
+		values = append(values, v)
+	}
+
+	return query, dargs, values
+}
+
+// inList returns the position of the first placeholder, the positions
+// of the placeholders and the end of the next IN list of the placeholders
+// found from the position i of the query or false if not found.
+func inList(query string, i int, placeholder string) (int, [][2]int, int, bool) {
+	for {
+		j := indexFold(query[i:], "IN")
+		if j == -1 {
+			return 0, nil, 0, false
+		}
+		j += i
+		i = j + 2
+
+		if j > 0 && isIdent(query[j-1]) || i < len(query) && isIdent(query[i]) {
+			continue
+		}
+
+		k := skipSpaces(query, i)
+		if k == len(query) || query[k] != '(' {
+			continue
+		}
+		k = skipSpaces(query, k+1)
+		start := k
+
+		var items [][2]int
+		for {
+			n := placeholderLen(query[k:], placeholder)
+			if n == 0 {
+				break
+			}
+			items = append(items, [2]int{k, k + n})
+
+			k = skipSpaces(query, k+n)
+			if k < len(query) && query[k] == ',' {
+				k = skipSpaces(query, k+1)
+				continue
+			}
+			if k < len(query) && query[k] == ')' {
+				return start, items, k, true
+			}
+			break
+		}
+
+		i = k
+	}
+}
+
+// placeholderLen returns the length of the placeholder
+// ($1, ?, :name, @name or the explicit placeholder)
+// at the beginning of the s or zero.
+func placeholderLen(s, placeholder string) int {
+	if placeholder != "" {
+		if strings.HasPrefix(s, placeholder) {
+			return len(placeholder)
+		}
+		return 0
+	}
+
+	if strings.HasPrefix(s, "?") {
+		return 1
+	}
+
+	if len(s) < 2 || (s[0] != '$' && s[0] != ':' && s[0] != '@') || !isIdent(s[1]) || (s[0] == '$' && !isDigit(s[1])) {
+		return 0
+	}
+
+	n := 1
+	for n < len(s) && isIdent(s[n]) {
+		n++
+	}
+	return n
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+// indexFold returns the index of the first case-insensitive
+// instance of the ASCII substr in s or -1.
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
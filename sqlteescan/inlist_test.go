@@ -0,0 +1,111 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestCompressInLists(t *testing.T) {
+	var (
+		ordinals  []driver.NamedValue
+		values    []driver.Value
+		dollars   []string
+		questions []string
+	)
+	for i := 1; i <= 500; i++ {
+		ordinals = append(ordinals, driver.NamedValue{Ordinal: i, Value: int64(i)})
+		values = append(values, int64(i))
+		dollars = append(dollars, fmt.Sprintf("$%d", i))
+		questions = append(questions, "?")
+	}
+
+	var tests = []struct {
+		name        string
+		line        string
+		query       string
+		placeholder string
+		dargs       []driver.Value
+		nvdargs     []driver.NamedValue
+		want        string
+	}{
+		{
+			name:    "ordinal placeholders",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id IN (" + strings.Join(dollars, ",") + ")",
+			nvdargs: ordinals,
+			want:    "SELECT * FROM foo WHERE id IN (1, 2, 3, … 500 values …)",
+		},
+		{
+			name:  "question placeholders",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE id in (" + strings.Join(questions, ", ") + ") AND name = ?",
+			dargs: append(append([]driver.Value{}, values...), "bar"),
+			want:  "SELECT * FROM foo WHERE id in (1, 2, 3, … 500 values …) AND name = 'bar'",
+		},
+		{
+			name:        "explicit placeholder",
+			line:        line(),
+			query:       "SELECT * FROM foo WHERE name = ? AND id IN ( " + strings.Join(questions, " , ") + " )",
+			placeholder: "?",
+			nvdargs:     append([]driver.NamedValue{{Ordinal: 1, Value: "bar"}}, ordinals...),
+			want:        "SELECT * FROM foo WHERE name = 'bar' AND id IN ( 1, 2, 3, … 500 values … )",
+		},
+		{
+			name:    "short list",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id IN ($1, $2, $3)",
+			nvdargs: ordinals[:3],
+			want:    "SELECT * FROM foo WHERE id IN (1, 2, 3)",
+		},
+		{
+			name:    "not in list",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id = $1 OR parent_id = $2 OR name LIKE 'IN (%'",
+			nvdargs: ordinals[:2],
+			want:    "SELECT * FROM foo WHERE id = 1 OR parent_id = 2 OR name LIKE 'IN (%'",
+		},
+		{
+			name:    "literals list",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id IN (1, 2, 3, 4, 5, 6, 7, 8, 9, 10) AND name = $1",
+			nvdargs: []driver.NamedValue{{Ordinal: 1, Value: "bar"}},
+			want:    "SELECT * FROM foo WHERE id IN (1, 2, 3, 4, 5, 6, 7, 8, 9, 10) AND name = 'bar'",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			query, dargs, nvdargs := sqlteescan.CompressInLists(tt.query, tt.placeholder, tt.dargs, tt.nvdargs)
+
+			s, err := sqlteescan.Interpolate(query, tt.placeholder, dargs, nvdargs)
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if s != tt.want {
+				t.Errorf("unexpected interpolation, want: %q, recieved: %q %s", tt.want, s, tt.line)
+			}
+		})
+	}
+}
+
+func TestCompressInListsUntouched(t *testing.T) {
+	query := "SELECT * FROM foo WHERE id = ? AND name IN (?, ?)"
+	dargs := []driver.Value{int64(1), "bar", "baz"}
+
+	q, d, nv := sqlteescan.CompressInLists(query, "", dargs, nil)
+	if q != query || len(d) != len(dargs) || nv != nil {
+		t.Errorf("unexpected compression, want: %q %v, recieved: %q %v %v", query, dargs, q, d, nv)
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Interpolate substitutes string representations of the SQL parameters
+// into the query. If the placeholder is not blank then it is used as
+// explicit placeholder instead of placeholder from parameters.
+// Interpolate returns an empty string if nothing was substituted.
+func Interpolate(query, placeholder string, dargs []driver.Value, nvdargs []driver.NamedValue) (string, error) {
+	scan := GetScanner()
+	scan.Values = dargs
+	scan.NamedValues = nvdargs
+	defer PutScanner(scan)
+
+	return scan.Interpolate(query, placeholder)
+}
+
+// Interpolate substitutes string representations of the scanner parameters
+// returned by the Assert function into the query. Interpolate scans
+// parameters from ending to beginning so it should be called
+// instead of the Scan method.
+//
+// If the MaxArgs is positive and the scanner has more parameters
+// then Interpolate substitutes only first MaxArgs parameters,
+// cuts the query before the next parameter identifier and appends
+// a marker of the rest parameters (for example …(+9990 more args)).
+//
+// The positional parameters are substituted into the placeholders
+// in order, so if the numbers of the placeholders and the parameters
+// disagree (see PlaceholderMismatch) then the unmatched placeholders
+// are left intact and the unmatched parameters are not substituted.
+//
+// If the explicit placeholder is blank and the Placeholder style
+// of the scanner is not the PlaceholderDefault then the parameter
+// identifiers of the style are substituted (for example :1 or @p1).
+func (scan *Scanner) Interpolate(query, placeholder string) (string, error) {
+	var interpolation, more string
+
+	if n := len(scan.Values) + len(scan.NamedValues); scan.MaxArgs > 0 && n > scan.MaxArgs {
+		query = scan.truncate(query, placeholder)
+		more = fmt.Sprintf(" …(+%d more args)", n-scan.MaxArgs)
+	}
+
+	pos := positional(placeholder, scan.Values, scan.NamedValues)
+	style := scan.style(placeholder)
+	if style == PlaceholderQuestion {
+		pos = "?"
+	} else if style != PlaceholderDefault {
+		pos = ""
+	}
+
+	var offsets []int
+	if pos != "" {
+		offsets = placeholderIndexes(query, pos)
+	}
+
+	scan.Reverse = true
+
+	for i := len(scan.Values) + len(scan.NamedValues) - 1; scan.Scan(); i-- {
+		if interpolation == "" {
+			interpolation = query
+		}
+
+		name, ordinal, value := scan.Param()
+
+		if pos != "" {
+			// offsets of the query stay valid because
+			// the placeholders are substituted from the end
+			if i >= len(offsets) {
+				continue
+			}
+			interpolation = interpolation[:offsets[i]] + value + interpolation[offsets[i]+len(pos):]
+
+		} else if style != PlaceholderDefault {
+			for _, token := range style.tokens(name, ordinal, i+1) {
+				interpolation = replaceToken(interpolation, token, value)
+			}
+
+		} else if placeholder == "" && (name != "" || ordinal != 0) {
+			if name == "" {
+				name = fmt.Sprintf("$%d", ordinal)
+			}
+			interpolation = replaceToken(interpolation, name, value)
+
+		} else {
+			if placeholder != "" {
+				name = placeholder
+			} else if name == "" {
+				name = "?"
+			}
+
+			i := strings.LastIndex(interpolation, name)
+			if i != -1 {
+				interpolation = interpolation[:i] + value + interpolation[i+len(name):]
+			}
+		}
+
+		if interpolation == query {
+			return "", nil
+		}
+	}
+
+	err := scan.Err()
+	if err != nil {
+		return "", err
+	}
+
+	if interpolation == query {
+		return "", nil
+	}
+
+	if interpolation != "" {
+		interpolation += more
+	}
+
+	return interpolation, nil
+}
+
+// PlaceholderMismatch returns the number of the positional placeholders
+// of the query (the explicit placeholder if it is not blank or
+// the ? question characters), the number of the positional parameters
+// and true if the numbers disagree. The named and the ordinal parameters
+// (for example :name or $1) are not counted and never disagree.
+func PlaceholderMismatch(query, placeholder string, dargs []driver.Value, nvdargs []driver.NamedValue) (placeholders, args int, mismatch bool) {
+	pos := positional(placeholder, dargs, nvdargs)
+	if pos == "" {
+		return 0, 0, false
+	}
+
+	placeholders = strings.Count(query, pos)
+	args = len(dargs) + len(nvdargs)
+
+	return placeholders, args, placeholders != args
+}
+
+// positional returns the identifier of the positional parameters
+// (the explicit placeholder if it is not blank or the ? question character)
+// or blank string if the parameters are named or ordinal.
+func positional(placeholder string, dargs []driver.Value, nvdargs []driver.NamedValue) string {
+	if placeholder != "" {
+		return placeholder
+	}
+
+	if len(dargs) != 0 {
+		return "?"
+	}
+
+	for _, nv := range nvdargs {
+		if nv.Name != "" || nv.Ordinal != 0 {
+			return ""
+		}
+	}
+
+	if len(nvdargs) != 0 {
+		return "?"
+	}
+
+	return ""
+}
+
+// placeholderIndexes returns the indexes of the positional placeholders
+// of the query in order.
+func placeholderIndexes(query, placeholder string) []int {
+	var offsets []int
+	for i := 0; ; {
+		j := strings.Index(query[i:], placeholder)
+		if j == -1 {
+			return offsets
+		}
+		offsets = append(offsets, i+j)
+		i += j + len(placeholder)
+	}
+}
+
+// style returns the Placeholder style of the scanner
+// or the PlaceholderDefault if the explicit placeholder is not blank.
+func (scan *Scanner) style(placeholder string) PlaceholderStyle {
+	if placeholder != "" {
+		return PlaceholderDefault
+	}
+	return scan.Placeholder
+}
+
+// truncate cuts the parameters of the scanner to the first MaxArgs
+// and returns the query cut before the first parameter identifier
+// of the rest parameters (or the query as is if identifier is not found).
+func (scan *Scanner) truncate(query, placeholder string) string {
+	cut := -1
+	style := scan.style(placeholder)
+
+	if placeholder != "" || style == PlaceholderQuestion || style == PlaceholderDefault && len(scan.Values) != 0 {
+		name := placeholder
+		if name == "" {
+			name = "?"
+		}
+
+		i := 0
+		for n := 0; n <= scan.MaxArgs; n++ {
+			j := strings.Index(query[i:], name)
+			if j == -1 {
+				i = -1
+				break
+			}
+			i += j
+			if n != scan.MaxArgs {
+				i += len(name)
+			}
+		}
+		cut = i
+
+	} else if style != PlaceholderDefault {
+		if len(scan.Values) != 0 {
+			for n := scan.MaxArgs; n < len(scan.Values); n++ {
+				cut = indexTokens(query, style.tokens("", 0, n+1), cut)
+			}
+		} else {
+			for n := scan.MaxArgs; n < len(scan.NamedValues); n++ {
+				nv := scan.NamedValues[n]
+				cut = indexTokens(query, style.tokens(nv.Name, nv.Ordinal, n+1), cut)
+			}
+		}
+
+	} else {
+		for _, nv := range scan.NamedValues[scan.MaxArgs:] {
+			name := nv.Name
+			if name == "" && nv.Ordinal != 0 {
+				name = fmt.Sprintf("$%d", nv.Ordinal)
+			}
+			if name == "" {
+				continue
+			}
+
+			if i := indexToken(query, name, 0); i != -1 && (cut == -1 || i < cut) {
+				cut = i
+			}
+		}
+	}
+
+	if len(scan.Values) != 0 {
+		scan.Values = scan.Values[:scan.MaxArgs]
+	} else {
+		scan.NamedValues = scan.NamedValues[:scan.MaxArgs]
+	}
+
+	if cut == -1 {
+		return query
+	}
+
+	return strings.TrimRight(query[:cut], " ")
+}
+
+// indexToken returns the index of the first instance of the parameter
+// identifier in s starting from the index from or -1 if not found.
+// The identifier is matched literally and only on the token boundary,
+// so :name does not match the beginning of :namespace and $1 of $10.
+func indexToken(s, name string, from int) int {
+	for from <= len(s)-len(name) {
+		i := strings.Index(s[from:], name)
+		if i == -1 {
+			return -1
+		}
+		i += from
+
+		if isToken(s, i, i+len(name)) {
+			return i
+		}
+		from = i + 1
+	}
+	return -1
+}
+
+// indexTokens returns the least index of the first instances of the parameter
+// identifiers in s found by indexToken and the index cut or -1 if not found.
+func indexTokens(s string, names []string, cut int) int {
+	for _, name := range names {
+		if i := indexToken(s, name, 0); i != -1 && (cut == -1 || i < cut) {
+			cut = i
+		}
+	}
+	return cut
+}
+
+// isToken returns true if the identifier s[i:j] is not glued to the
+// adjacent identifier characters.
+func isToken(s string, i, j int) bool {
+	if isIdent(s[j-1]) && j < len(s) && isIdent(s[j]) {
+		return false
+	}
+	if isIdent(s[i]) && i > 0 && isIdent(s[i-1]) {
+		return false
+	}
+	return true
+}
+
+// replaceToken replaces all instances of the parameter identifier
+// in s found by indexToken with the value.
+func replaceToken(s, name, value string) string {
+	i := indexToken(s, name, 0)
+	if i == -1 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + len(value) - len(name))
+
+	var last int
+	for ; i != -1; i = indexToken(s, name, i+len(name)) {
+		b.WriteString(s[last:i])
+		b.WriteString(value)
+		last = i + len(name)
+	}
+	b.WriteString(s[last:])
+
+	return b.String()
+}
@@ -0,0 +1,362 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestInterpolate(t *testing.T) {
+	var tests = []struct {
+		name        string
+		line        string
+		query       string
+		placeholder string
+		dargs       []driver.Value
+		nvdargs     []driver.NamedValue
+		want        string
+	}{
+		{
+			name:  "values",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE id = ? AND name = ?",
+			dargs: []driver.Value{int64(42), "bar"},
+			want:  "SELECT * FROM foo WHERE id = 42 AND name = 'bar'",
+		},
+		{
+			name:    "ordinal values",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id = $1 AND name = $2",
+			nvdargs: []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "bar"}},
+			want:    "SELECT * FROM foo WHERE id = 42 AND name = 'bar'",
+		},
+		{
+			name:    "named values",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id = :id",
+			nvdargs: []driver.NamedValue{{Name: ":id", Ordinal: 1, Value: int64(42)}},
+			want:    "SELECT * FROM foo WHERE id = 42",
+		},
+		{
+			name:        "explicit placeholder",
+			line:        line(),
+			query:       "SELECT * FROM foo WHERE id = @p AND name = @p",
+			placeholder: "@p",
+			nvdargs:     []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "bar"}},
+			want:        "SELECT * FROM foo WHERE id = 42 AND name = 'bar'",
+		},
+		{
+			name:    "valuer array",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id = ANY($1)",
+			nvdargs: []driver.NamedValue{{Ordinal: 1, Value: valuer{value: "{1,2,3}"}}},
+			want:    "SELECT * FROM foo WHERE id = ANY('{1,2,3}')",
+		},
+		{
+			name:  "quoted string",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE name = ?",
+			dargs: []driver.Value{"it's"},
+			want:  "SELECT * FROM foo WHERE name = 'it''s'",
+		},
+		{
+			name:    "name is prefix of another name",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE name = :name AND namespace = :namespace OR :name IS NULL",
+			nvdargs: []driver.NamedValue{{Name: ":name", Ordinal: 1, Value: "bar"}, {Name: ":namespace", Ordinal: 2, Value: "baz"}},
+			want:    "SELECT * FROM foo WHERE name = 'bar' AND namespace = 'baz' OR 'bar' IS NULL",
+		},
+		{
+			name:    "name is prefix of another name in reverse order",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE namespace = :namespace AND name = :name",
+			nvdargs: []driver.NamedValue{{Name: ":namespace", Ordinal: 1, Value: "baz"}, {Name: ":name", Ordinal: 2, Value: "bar"}},
+			want:    "SELECT * FROM foo WHERE namespace = 'baz' AND name = 'bar'",
+		},
+		{
+			name:    "name is suffix of identifier",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE foo_id = id",
+			nvdargs: []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(42)}},
+			want:    "SELECT * FROM foo WHERE foo_id = 42",
+		},
+		{
+			name:    "names with special characters",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE a = :a.b AND b = :[x]* AND c = :$1 AND d = :\\1",
+			nvdargs: []driver.NamedValue{{Name: ":a.b", Ordinal: 1, Value: int64(1)}, {Name: ":[x]*", Ordinal: 2, Value: int64(2)}, {Name: ":$1", Ordinal: 3, Value: int64(3)}, {Name: ":\\1", Ordinal: 4, Value: "$1"}},
+			want:    "SELECT * FROM foo WHERE a = 1 AND b = 2 AND c = 3 AND d = '$1'",
+		},
+		{
+			name:    "output parameter",
+			line:    line(),
+			query:   "CALL foo(:id, :total)",
+			nvdargs: []driver.NamedValue{{Name: ":id", Ordinal: 1, Value: int64(42)}, {Name: ":total", Ordinal: 2, Value: sql.Out{Dest: new(int64)}}},
+			want:    "CALL foo(42, OUT(*int64))",
+		},
+		{
+			name:    "input/output parameter",
+			line:    line(),
+			query:   "CALL foo($1)",
+			nvdargs: []driver.NamedValue{{Ordinal: 1, Value: sql.Out{Dest: func() *string { s := "bar"; return &s }(), In: true}}},
+			want:    "CALL foo(INOUT('bar'))",
+		},
+		{
+			name:  "too few args",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE id = ? AND name = ? AND age = ?",
+			dargs: []driver.Value{int64(42), "bar"},
+			want:  "SELECT * FROM foo WHERE id = 42 AND name = 'bar' AND age = ?",
+		},
+		{
+			name:  "too many args",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE id = ? AND name = ?",
+			dargs: []driver.Value{int64(42), "bar", int64(7)},
+			want:  "SELECT * FROM foo WHERE id = 42 AND name = 'bar'",
+		},
+		{
+			name:        "explicit placeholder not found",
+			line:        line(),
+			query:       "SELECT * FROM foo WHERE id = :id",
+			placeholder: "?",
+			nvdargs:     []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(42)}},
+			want:        "",
+		},
+		{
+			name:  "placeholder in value",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE id = ? AND name = ?",
+			dargs: []driver.Value{int64(42), "why?"},
+			want:  "SELECT * FROM foo WHERE id = 42 AND name = 'why?'",
+		},
+		{
+			name:  "without parameters",
+			line:  line(),
+			query: "SELECT * FROM foo",
+			want:  "",
+		},
+		{
+			name:    "placeholder not found",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id = ?",
+			nvdargs: []driver.NamedValue{{Ordinal: 1, Value: int64(42)}},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := sqlteescan.Interpolate(tt.query, tt.placeholder, tt.dargs, tt.nvdargs)
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if s != tt.want {
+				t.Errorf("unexpected interpolation, want: %q, recieved: %q %s", tt.want, s, tt.line)
+			}
+		})
+	}
+}
+
+func TestPlaceholderMismatch(t *testing.T) {
+	var tests = []struct {
+		name         string
+		line         string
+		query        string
+		placeholder  string
+		dargs        []driver.Value
+		nvdargs      []driver.NamedValue
+		placeholders int
+		args         int
+		mismatch     bool
+	}{
+		{
+			name:         "match",
+			line:         line(),
+			query:        "SELECT * FROM foo WHERE id = ? AND name = ?",
+			dargs:        []driver.Value{int64(42), "bar"},
+			placeholders: 2,
+			args:         2,
+		},
+		{
+			name:         "too few args",
+			line:         line(),
+			query:        "SELECT * FROM foo WHERE id = ? AND name = ? AND age = ?",
+			dargs:        []driver.Value{int64(42), "bar"},
+			placeholders: 3,
+			args:         2,
+			mismatch:     true,
+		},
+		{
+			name:         "too many args",
+			line:         line(),
+			query:        "SELECT * FROM foo WHERE id = @p",
+			placeholder:  "@p",
+			nvdargs:      []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "bar"}},
+			placeholders: 1,
+			args:         2,
+			mismatch:     true,
+		},
+		{
+			name:    "ordinal values",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id = $1 AND name = $2",
+			nvdargs: []driver.NamedValue{{Ordinal: 1, Value: int64(42)}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			placeholders, args, mismatch := sqlteescan.PlaceholderMismatch(tt.query, tt.placeholder, tt.dargs, tt.nvdargs)
+			if placeholders != tt.placeholders || args != tt.args || mismatch != tt.mismatch {
+				t.Errorf("unexpected mismatch, want: %d %d %t, recieved: %d %d %t %s", tt.placeholders, tt.args, tt.mismatch, placeholders, args, mismatch, tt.line)
+			}
+		})
+	}
+}
+
+func TestScannerInterpolateMaxArgs(t *testing.T) {
+	var ordinals []driver.NamedValue
+	for i := 1; i <= 12; i++ {
+		ordinals = append(ordinals, driver.NamedValue{Ordinal: i, Value: int64(i * 10)})
+	}
+
+	var tests = []struct {
+		name        string
+		line        string
+		query       string
+		placeholder string
+		maxArgs     int
+		dargs       []driver.Value
+		nvdargs     []driver.NamedValue
+		want        string
+	}{
+		{
+			name:    "values",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id IN (?, ?, ?, ?, ?)",
+			maxArgs: 2,
+			dargs:   []driver.Value{int64(1), int64(2), int64(3), int64(4), int64(5)},
+			want:    "SELECT * FROM foo WHERE id IN (1, 2, …(+3 more args)",
+		},
+		{
+			name:    "ordinal values",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id IN ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)",
+			maxArgs: 10,
+			nvdargs: ordinals,
+			want:    "SELECT * FROM foo WHERE id IN (10, 20, 30, 40, 50, 60, 70, 80, 90, 100, …(+2 more args)",
+		},
+		{
+			name:        "explicit placeholder",
+			line:        line(),
+			query:       "SELECT * FROM foo WHERE id = @p AND name = @p",
+			placeholder: "@p",
+			maxArgs:     1,
+			nvdargs:     []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "bar"}},
+			want:        "SELECT * FROM foo WHERE id = 42 AND name = …(+1 more args)",
+		},
+		{
+			name:    "values within limit",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id IN (?, ?)",
+			maxArgs: 2,
+			dargs:   []driver.Value{int64(1), int64(2)},
+			want:    "SELECT * FROM foo WHERE id IN (1, 2)",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			scan := sqlteescan.GetScanner()
+			defer sqlteescan.PutScanner(scan)
+			scan.Values = tt.dargs
+			scan.NamedValues = tt.nvdargs
+			scan.MaxArgs = tt.maxArgs
+
+			s, err := scan.Interpolate(tt.query, tt.placeholder)
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if s != tt.want {
+				t.Errorf("unexpected interpolation, want: %q, recieved: %q %s", tt.want, s, tt.line)
+			}
+		})
+	}
+}
+
+func TestScannerInterpolateTypes(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		query    string
+		types    []string
+		dargs    []driver.Value
+		nvdargs  []driver.NamedValue
+		expected string
+	}{
+		{
+			name:     "values",
+			line:     line(),
+			query:    "SELECT * FROM foo WHERE id = ? AND name = ?",
+			types:    []string{"int8", "text"},
+			dargs:    []driver.Value{int64(42), "foo"},
+			expected: "SELECT * FROM foo WHERE id = 42::int8 AND name = 'foo'::text",
+		},
+		{
+			name:     "ordinal values",
+			line:     line(),
+			query:    "SELECT * FROM foo WHERE id = $1 AND name = $2",
+			types:    []string{"int8", "text"},
+			nvdargs:  []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "bar"}},
+			expected: "SELECT * FROM foo WHERE id = 42::int8 AND name = 'bar'::text",
+		},
+		{
+			name:     "unknown types",
+			line:     line(),
+			query:    "SELECT * FROM foo WHERE id = ? AND name = ?",
+			types:    []string{""},
+			dargs:    []driver.Value{int64(42), "foo"},
+			expected: "SELECT * FROM foo WHERE id = 42 AND name = 'foo'",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			scan := sqlteescan.GetScanner()
+			defer sqlteescan.PutScanner(scan)
+			scan.Values = tt.dargs
+			scan.NamedValues = tt.nvdargs
+			scan.Types = tt.types
+
+			s, err := scan.Interpolate(tt.query, "")
+			if err != nil {
+				t.Fatalf("interpolate error: %#v %s", err, tt.line)
+			}
+
+			if s != tt.expected {
+				t.Errorf("unexpected interpolation, expected: %q, recieved: %q %s", tt.expected, s, tt.line)
+			}
+		})
+	}
+}
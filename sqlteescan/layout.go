@@ -0,0 +1,242 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import (
+	"database/sql/driver"
+	"strconv"
+	"strings"
+)
+
+// Layout is a tokenized placeholder layout of the query which is built
+// by the first interpolation and reused by the subsequent interpolations
+// of the same query with the same parameter identifiers (for example
+// by the executions of the prepared statement), so they only substitute
+// the parameter values.
+//
+// Layout is not safe for concurrent use.
+type Layout struct {
+	built       bool
+	query       string
+	placeholder string
+	params      []layoutParam // parameter identifiers the tokens are built for
+	tokens      []layoutToken // parameter identifiers sorted by the position in the query
+	found       bool          // the last parameter identifier is found in the query
+	values      []string      // string representations of the parameter values reused between interpolations
+}
+
+type layoutParam struct {
+	name    string
+	ordinal int
+}
+
+type layoutToken struct {
+	start, end int // position of the parameter identifier in the query
+	param      int // index of the parameter
+}
+
+// Interpolate substitutes string representations of the scanner parameters
+// into the query the same as the Interpolate method of the scanner,
+// but it tokenizes the query only if the query, the placeholder or
+// the parameter identifiers are changed since the previous call.
+//
+// If the MaxArgs of the scanner is positive then Interpolate falls back
+// to the Interpolate method of the scanner because the query is truncated,
+// the same if the Placeholder style of the scanner is not the PlaceholderDefault.
+func (l *Layout) Interpolate(scan *Scanner, query, placeholder string) (string, error) {
+	if scan.MaxArgs > 0 || scan.style(placeholder) != PlaceholderDefault {
+		return scan.Interpolate(query, placeholder)
+	}
+
+	if !l.match(scan, query, placeholder) {
+		l.build(scan, query, placeholder)
+	}
+
+	if len(l.params) == 0 || !l.found {
+		return "", nil
+	}
+
+	l.values = l.values[:0]
+	for range l.params {
+		l.values = append(l.values, "")
+	}
+
+	scan.Reverse = true
+
+	var size int
+	for i := len(l.params) - 1; scan.Scan(); i-- {
+		_, _, l.values[i] = scan.Param()
+		size += len(l.values[i])
+	}
+
+	err := scan.Err()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + size)
+
+	var i int
+	for _, t := range l.tokens {
+		b.WriteString(query[i:t.start])
+		b.WriteString(l.values[t.param])
+		i = t.end
+	}
+	b.WriteString(query[i:])
+
+	return b.String(), nil
+}
+
+// match returns true if the layout is built for the query,
+// the placeholder and the parameter identifiers of the scanner.
+func (l *Layout) match(scan *Scanner, query, placeholder string) bool {
+	if !l.built || l.query != query || l.placeholder != placeholder {
+		return false
+	}
+
+	if len(scan.Values) != 0 {
+		if len(l.params) != len(scan.Values) {
+			return false
+		}
+		for _, p := range l.params {
+			if p != (layoutParam{}) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(l.params) != len(scan.NamedValues) {
+		return false
+	}
+	for i, nv := range scan.NamedValues {
+		if l.params[i] != (layoutParam{name: nv.Name, ordinal: nv.Ordinal}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// build tokenizes the query by the parameter identifiers of the scanner.
+// The positional parameters are matched to the placeholders in order
+// the same as the Interpolate method of the scanner does. The named
+// parameters are processed from ending to beginning, so the named
+// identifier overlapping the already taken one
+// (for example $1 of the $10) is skipped.
+func (l *Layout) build(scan *Scanner, query, placeholder string) {
+	l.built = true
+	l.query = query
+	l.placeholder = placeholder
+	l.params = l.params[:0]
+	l.tokens = l.tokens[:0]
+	l.found = false
+
+	if len(scan.Values) != 0 {
+		for range scan.Values {
+			l.params = append(l.params, layoutParam{})
+		}
+	} else {
+		for _, nv := range scan.NamedValues {
+			l.params = append(l.params, layoutParam{name: nv.Name, ordinal: nv.Ordinal})
+		}
+	}
+
+	pos := positional(placeholder, scan.Values, scan.NamedValues)
+	if pos != "" {
+		// positional parameters are matched to the placeholders in order
+		// and the unmatched placeholders or parameters are skipped
+		for i, k := range placeholderIndexes(query, pos) {
+			if i == len(l.params) {
+				break
+			}
+			l.tokens = append(l.tokens, layoutToken{start: k, end: k + len(pos), param: i})
+		}
+		l.found = len(l.tokens) != 0
+		return
+	}
+
+	for i := len(l.params) - 1; i >= 0; i-- {
+		p := l.params[i]
+
+		name := p.name
+		if name == "" && p.ordinal != 0 {
+			name = "$" + strconv.Itoa(p.ordinal)
+		}
+
+		var n int
+		if placeholder == "" && name != "" {
+			for j := 0; j <= len(query)-len(name); {
+				k := indexToken(query, name, j)
+				if k == -1 {
+					break
+				}
+				if !l.taken(k, k+len(name)) {
+					l.tokens = append(l.tokens, layoutToken{start: k, end: k + len(name), param: i})
+					n++
+				}
+				j = k + len(name)
+			}
+
+		} else {
+			if placeholder != "" {
+				name = placeholder
+			} else if name == "" {
+				name = "?"
+			}
+
+			for j := len(query); j >= 0; {
+				k := strings.LastIndex(query[:j], name)
+				if k == -1 {
+					break
+				}
+				if !l.taken(k, k+len(name)) {
+					l.tokens = append(l.tokens, layoutToken{start: k, end: k + len(name), param: i})
+					n++
+					break
+				}
+				j = k
+			}
+		}
+
+		if i == len(l.params)-1 {
+			l.found = n != 0
+		}
+	}
+
+	sortTokens(l.tokens)
+}
+
+// taken returns true if the range overlaps any token.
+func (l *Layout) taken(start, end int) bool {
+	for _, t := range l.tokens {
+		if start < t.end && t.start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// sortTokens sorts the tokens by the position by insertion
+// because the number of the parameters is usually small.
+func sortTokens(tokens []layoutToken) {
+	for i := 1; i < len(tokens); i++ {
+		for j := i; j > 0 && tokens[j].start < tokens[j-1].start; j-- {
+			tokens[j], tokens[j-1] = tokens[j-1], tokens[j]
+		}
+	}
+}
+
+// InterpolateLayout is the same as the Interpolate function but it reuses
+// the layout of the query (for example of the prepared statement).
+func InterpolateLayout(layout *Layout, query, placeholder string, dargs []driver.Value, nvdargs []driver.NamedValue) (string, error) {
+	scan := GetScanner()
+	scan.Values = dargs
+	scan.NamedValues = nvdargs
+	defer PutScanner(scan)
+
+	return layout.Interpolate(scan, query, placeholder)
+}
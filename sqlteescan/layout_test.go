@@ -0,0 +1,195 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestLayoutInterpolate(t *testing.T) {
+	var ordinals []driver.NamedValue
+	for i := 1; i <= 12; i++ {
+		ordinals = append(ordinals, driver.NamedValue{Ordinal: i, Value: int64(i * 10)})
+	}
+
+	var tests = []struct {
+		name        string
+		line        string
+		query       string
+		placeholder string
+		dargs       []driver.Value
+		nvdargs     []driver.NamedValue
+		want        string
+	}{
+		{
+			name:  "values",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE id = ? AND name = ?",
+			dargs: []driver.Value{int64(42), "bar"},
+			want:  "SELECT * FROM foo WHERE id = 42 AND name = 'bar'",
+		},
+		{
+			name:    "ordinal values",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id = $1 AND name = $2",
+			nvdargs: []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "bar"}},
+			want:    "SELECT * FROM foo WHERE id = 42 AND name = 'bar'",
+		},
+		{
+			name:    "ordinal values with two digits",
+			line:    line(),
+			query:   "INSERT INTO foo VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)",
+			nvdargs: ordinals,
+			want:    "INSERT INTO foo VALUES (10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120)",
+		},
+		{
+			name:    "named values",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id = :id OR parent_id = :id",
+			nvdargs: []driver.NamedValue{{Name: ":id", Ordinal: 1, Value: int64(42)}},
+			want:    "SELECT * FROM foo WHERE id = 42 OR parent_id = 42",
+		},
+		{
+			name:    "name is prefix of another name",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE namespace = :namespace AND name = :name",
+			nvdargs: []driver.NamedValue{{Name: ":namespace", Ordinal: 1, Value: "baz"}, {Name: ":name", Ordinal: 2, Value: "bar"}},
+			want:    "SELECT * FROM foo WHERE namespace = 'baz' AND name = 'bar'",
+		},
+		{
+			name:        "explicit placeholder",
+			line:        line(),
+			query:       "SELECT * FROM foo WHERE id = @p AND name = @p",
+			placeholder: "@p",
+			nvdargs:     []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "bar"}},
+			want:        "SELECT * FROM foo WHERE id = 42 AND name = 'bar'",
+		},
+		{
+			name:  "quoted string",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE name = ?",
+			dargs: []driver.Value{"it's"},
+			want:  "SELECT * FROM foo WHERE name = 'it''s'",
+		},
+		{
+			name:  "too few args",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE id = ? AND name = ? AND age = ?",
+			dargs: []driver.Value{int64(42), "bar"},
+			want:  "SELECT * FROM foo WHERE id = 42 AND name = 'bar' AND age = ?",
+		},
+		{
+			name:  "too many args",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE id = ? AND name = ?",
+			dargs: []driver.Value{int64(42), "bar", int64(7)},
+			want:  "SELECT * FROM foo WHERE id = 42 AND name = 'bar'",
+		},
+		{
+			name:  "placeholder in value",
+			line:  line(),
+			query: "SELECT * FROM foo WHERE id = ? AND name = ?",
+			dargs: []driver.Value{int64(42), "why?"},
+			want:  "SELECT * FROM foo WHERE id = 42 AND name = 'why?'",
+		},
+		{
+			name:  "without parameters",
+			line:  line(),
+			query: "SELECT * FROM foo",
+			want:  "",
+		},
+		{
+			name:    "placeholder not found",
+			line:    line(),
+			query:   "SELECT * FROM foo WHERE id = ?",
+			nvdargs: []driver.NamedValue{{Ordinal: 1, Value: int64(42)}},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			expected, err := sqlteescan.Interpolate(tt.query, tt.placeholder, tt.dargs, tt.nvdargs)
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if expected != tt.want {
+				t.Errorf("unexpected scanner interpolation, want: %q, recieved: %q %s", tt.want, expected, tt.line)
+			}
+
+			layout := new(sqlteescan.Layout)
+
+			for i := 0; i < 2; i++ {
+				s, err := sqlteescan.InterpolateLayout(layout, tt.query, tt.placeholder, tt.dargs, tt.nvdargs)
+				if err != nil {
+					t.Fatalf("unexpected error: %s %s", err, tt.line)
+				}
+
+				if s != tt.want {
+					t.Errorf("unexpected layout interpolation %d, want: %q, recieved: %q %s", i, tt.want, s, tt.line)
+				}
+			}
+		})
+	}
+}
+
+func TestLayoutRebuild(t *testing.T) {
+	layout := new(sqlteescan.Layout)
+
+	s, err := sqlteescan.InterpolateLayout(layout, "SELECT * FROM foo WHERE id = ?", "", []driver.Value{int64(42)}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s != "SELECT * FROM foo WHERE id = 42" {
+		t.Errorf("unexpected interpolation, want: %q, recieved: %q", "SELECT * FROM foo WHERE id = 42", s)
+	}
+
+	s, err = sqlteescan.InterpolateLayout(layout, "SELECT * FROM bar WHERE id = ? AND name = ?", "", []driver.Value{int64(7), "baz"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s != "SELECT * FROM bar WHERE id = 7 AND name = 'baz'" {
+		t.Errorf("unexpected interpolation, want: %q, recieved: %q", "SELECT * FROM bar WHERE id = 7 AND name = 'baz'", s)
+	}
+}
+
+var layoutQuery = "INSERT INTO foo (id, name, email, created_at) VALUES ($1, $2, $3, $4)"
+
+var layoutArgs = []driver.NamedValue{
+	{Ordinal: 1, Value: int64(42)},
+	{Ordinal: 2, Value: "foo"},
+	{Ordinal: 3, Value: "foo@example.com"},
+	{Ordinal: 4, Value: int64(1609459200)},
+}
+
+func BenchmarkScannerInterpolate(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := sqlteescan.Interpolate(layoutQuery, "", nil, layoutArgs)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLayoutInterpolate(b *testing.B) {
+	layout := new(sqlteescan.Layout)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := sqlteescan.InterpolateLayout(layout, layoutQuery, "", nil, layoutArgs)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
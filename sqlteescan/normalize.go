@@ -0,0 +1,136 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import (
+	"strings"
+)
+
+// Normalize returns a canonical form of the query for grouping
+// the queries by shape: comments are stripped, whitespaces are collapsed
+// into the single spaces and string literals, number literals
+// and placeholders ($1, :name, @name) are replaced by the ? question
+// characters. Quoted identifiers and the query keywords are kept as is.
+func Normalize(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	space := false
+	write := func(s string) {
+		if space && b.Len() != 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteString(s)
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case isSpace(c):
+			space = true
+			i++
+
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			j := strings.IndexByte(query[i:], '\n')
+			if j == -1 {
+				j = len(query) - i
+			}
+			space = true
+			i += j
+
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			j := strings.Index(query[i+2:], "*/")
+			if j == -1 {
+				j = len(query) - i
+			} else {
+				j += 4
+			}
+			space = true
+			i += j
+
+		case c == '\'':
+			write("?")
+			i += quotedLen(query[i:], '\'')
+
+		case c == '"' || c == '`':
+			j := quotedLen(query[i:], c)
+			write(query[i : i+j])
+			i += j
+
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			write("?")
+			i += numberLen(query[i:])
+
+		case (c == '$' || c == ':' || c == '@') && i+1 < len(query) && isIdent(query[i+1]) &&
+			(i == 0 || query[i-1] != c) && (c != '$' || isDigit(query[i+1])):
+			write("?")
+			i++
+			for i < len(query) && isIdent(query[i]) {
+				i++
+			}
+
+		case isIdent(c):
+			j := i
+			for j < len(query) && isIdent(query[j]) {
+				j++
+			}
+			// string literals with prefix (for example E'\\x00' or N'foo')
+			if j-i == 1 && j < len(query) && query[j] == '\'' && strings.IndexByte("EeNnXxBb", c) != -1 {
+				write("?")
+				i = j + quotedLen(query[j:], '\'')
+				continue
+			}
+			write(query[i:j])
+			i = j
+
+		default:
+			write(query[i : i+1])
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+// quotedLen returns the length of the quoted string (or identifier)
+// at the beginning of the s including the doubled quotes inside
+// or the length of the s if the closing quote is not found.
+func quotedLen(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == quote {
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// numberLen returns the length of the number literal
+// at the beginning of the s (for example 42, 3.14, 1e-10 or 0xff).
+func numberLen(s string) int {
+	i := 0
+	for i < len(s) && (isIdent(s[i]) || s[i] == '.' ||
+		((s[i] == '-' || s[i] == '+') && i > 0 && (s[i-1] == 'e' || s[i-1] == 'E'))) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || isDigit(c) || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80
+}
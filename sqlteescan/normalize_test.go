@@ -0,0 +1,99 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"testing"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestNormalize(t *testing.T) {
+	var tests = []struct {
+		name  string
+		line  string
+		query string
+		want  string
+	}{
+		{
+			name:  "number literal",
+			line:  line(),
+			query: "SELECT * FROM t WHERE id = 42",
+			want:  "SELECT * FROM t WHERE id = ?",
+		},
+		{
+			name:  "string literal",
+			line:  line(),
+			query: "SELECT * FROM t WHERE name = 'it''s'",
+			want:  "SELECT * FROM t WHERE name = ?",
+		},
+		{
+			name:  "prefixed string literals",
+			line:  line(),
+			query: `SELECT * FROM t WHERE b = E'\\xdead' AND n = N'foo'`,
+			want:  "SELECT * FROM t WHERE b = ? AND n = ?",
+		},
+		{
+			name:  "float and negative literals",
+			line:  line(),
+			query: "SELECT * FROM t WHERE x > 3.14 AND y < -1e-10 AND z = .5",
+			want:  "SELECT * FROM t WHERE x > ? AND y < -? AND z = ?",
+		},
+		{
+			name:  "placeholders",
+			line:  line(),
+			query: "SELECT * FROM t WHERE a = $1 AND b = $12 AND c = :name AND d = @p1 AND e = ?",
+			want:  "SELECT * FROM t WHERE a = ? AND b = ? AND c = ? AND d = ? AND e = ?",
+		},
+		{
+			name:  "interpolated and parameterized queries are the same",
+			line:  line(),
+			query: "SELECT * FROM t WHERE id = 1 AND name = 'foo'",
+			want:  sqlteescan.Normalize("SELECT * FROM t WHERE id = $1 AND name = $2"),
+		},
+		{
+			name:  "whitespaces",
+			line:  line(),
+			query: "\n\tSELECT  *\n  FROM t\r\n WHERE   id = 1  ",
+			want:  "SELECT * FROM t WHERE id = ?",
+		},
+		{
+			name:  "comments",
+			line:  line(),
+			query: "SELECT * -- all columns\nFROM t /* table\n t */ WHERE id = 1",
+			want:  "SELECT * FROM t WHERE id = ?",
+		},
+		{
+			name:  "identifiers with digits and quoted identifiers",
+			line:  line(),
+			query: `SELECT t1.col2, "Col 3", ` + "`col4`" + ` FROM t1 WHERE "x" = '5'`,
+			want:  `SELECT t1.col2, "Col 3", ` + "`col4`" + ` FROM t1 WHERE "x" = ?`,
+		},
+		{
+			name:  "type casts and system variables",
+			line:  line(),
+			query: "SELECT '1'::int, @@version",
+			want:  "SELECT ?::int, @@version",
+		},
+		{
+			name:  "unterminated string literal",
+			line:  line(),
+			query: "SELECT * FROM t WHERE name = 'foo",
+			want:  "SELECT * FROM t WHERE name = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			s := sqlteescan.Normalize(tt.query)
+			if s != tt.want {
+				t.Errorf("unexpected normalization, want: %q, recieved: %q %s", tt.want, s, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PlaceholderStyle is a style of the parameter identifiers
+// which Interpolate recognizes and substitutes.
+type PlaceholderStyle int
+
+const (
+	PlaceholderDefault  PlaceholderStyle = iota // ? question characters of the positional parameters, $1 of the ordinal and the name as is of the named parameters
+	PlaceholderQuestion                         // ? question characters of all parameters in order (for example MySQL or SQLite)
+	PlaceholderDollar                           // $1 of the ordinal position (for example PostgreSQL)
+	PlaceholderColon                            // :1 of the ordinal position or :name of the named parameters (for example Oracle)
+	PlaceholderAtP                              // @p1 of the ordinal position or @name of the named parameters (for example SQL Server)
+	PlaceholderNamed                            // :name or @name of the named parameters, the unnamed parameters are not substituted
+)
+
+// tokens returns the parameter identifiers of the parameter
+// of the name, the ordinal position (if not zero) and the position
+// in the list of the parameters (starts by one).
+// The leading :, @ and $ characters of the name are ignored.
+func (s PlaceholderStyle) tokens(name string, ordinal, position int) []string {
+	name = strings.TrimLeft(name, ":@$")
+
+	n := ordinal
+	if n == 0 {
+		n = position
+	}
+
+	switch s {
+	case PlaceholderDollar:
+		return []string{"$" + strconv.Itoa(n)}
+
+	case PlaceholderColon:
+		if name != "" {
+			return []string{":" + name}
+		}
+		return []string{":" + strconv.Itoa(n)}
+
+	case PlaceholderAtP:
+		if name != "" {
+			return []string{"@" + name}
+		}
+		return []string{"@p" + strconv.Itoa(n)}
+
+	case PlaceholderNamed:
+		if name != "" {
+			return []string{":" + name, "@" + name}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestPlaceholderStyle(t *testing.T) {
+	var (
+		values   = []driver.Value{int64(42), "bar"}
+		ordinals = []driver.NamedValue{{Ordinal: 1, Value: int64(42)}, {Ordinal: 2, Value: "bar"}}
+		names    = []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(42)}, {Name: "name", Ordinal: 2, Value: "bar"}}
+	)
+
+	const want = "SELECT * FROM foo WHERE id = 42 AND name = 'bar'"
+
+	var tests = []struct {
+		name    string
+		line    string
+		style   sqlteescan.PlaceholderStyle
+		query   string
+		maxArgs int
+		dargs   []driver.Value
+		nvdargs []driver.NamedValue
+		want    string
+	}{
+		{
+			name:  "default",
+			line:  line(),
+			style: sqlteescan.PlaceholderDefault,
+			query: "SELECT * FROM foo WHERE id = ? AND name = ?",
+			dargs: values,
+			want:  want,
+		},
+		{
+			name:    "question of ordinal values",
+			line:    line(),
+			style:   sqlteescan.PlaceholderQuestion,
+			query:   "SELECT * FROM foo WHERE id = ? AND name = ?",
+			nvdargs: ordinals,
+			want:    want,
+		},
+		{
+			name:  "dollar of values",
+			line:  line(),
+			style: sqlteescan.PlaceholderDollar,
+			query: "SELECT * FROM foo WHERE id = $1 AND name = $2",
+			dargs: values,
+			want:  want,
+		},
+		{
+			name:    "dollar of ordinal values",
+			line:    line(),
+			style:   sqlteescan.PlaceholderDollar,
+			query:   "SELECT * FROM foo WHERE id = $1 AND name = $2",
+			nvdargs: ordinals,
+			want:    want,
+		},
+		{
+			name:    "colon of ordinal values",
+			line:    line(),
+			style:   sqlteescan.PlaceholderColon,
+			query:   "SELECT * FROM foo WHERE id = :1 AND name = :2",
+			nvdargs: ordinals,
+			want:    want,
+		},
+		{
+			name:    "colon of named values",
+			line:    line(),
+			style:   sqlteescan.PlaceholderColon,
+			query:   "SELECT * FROM foo WHERE id = :id AND name = :name",
+			nvdargs: names,
+			want:    want,
+		},
+		{
+			name:    "at p of ordinal values",
+			line:    line(),
+			style:   sqlteescan.PlaceholderAtP,
+			query:   "SELECT * FROM foo WHERE id = @p1 AND name = @p2",
+			nvdargs: ordinals,
+			want:    want,
+		},
+		{
+			name:    "at p of named values",
+			line:    line(),
+			style:   sqlteescan.PlaceholderAtP,
+			query:   "SELECT * FROM foo WHERE id = @id AND name = @name",
+			nvdargs: names,
+			want:    want,
+		},
+		{
+			name:    "named",
+			line:    line(),
+			style:   sqlteescan.PlaceholderNamed,
+			query:   "SELECT * FROM foo WHERE id = :id AND name = @name",
+			nvdargs: names,
+			want:    want,
+		},
+		{
+			name:    "named of ordinal values",
+			line:    line(),
+			style:   sqlteescan.PlaceholderNamed,
+			query:   "SELECT * FROM foo WHERE id = :1 AND name = :2",
+			nvdargs: ordinals,
+			want:    "",
+		},
+		{
+			name:    "colon of the ordinal position is not a prefix of another",
+			line:    line(),
+			style:   sqlteescan.PlaceholderColon,
+			query:   "SELECT * FROM foo WHERE id = :1 OR :1 IS NULL",
+			nvdargs: ordinals[:1],
+			want:    "SELECT * FROM foo WHERE id = 42 OR 42 IS NULL",
+		},
+		{
+			name:    "at p with max args",
+			line:    line(),
+			style:   sqlteescan.PlaceholderAtP,
+			query:   "SELECT * FROM foo WHERE id = @p1 AND name = @p2",
+			maxArgs: 1,
+			nvdargs: ordinals,
+			want:    "SELECT * FROM foo WHERE id = 42 AND name = …(+1 more args)",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			scan := sqlteescan.GetScanner()
+			defer sqlteescan.PutScanner(scan)
+			scan.Values = tt.dargs
+			scan.NamedValues = tt.nvdargs
+			scan.Placeholder = tt.style
+			scan.MaxArgs = tt.maxArgs
+
+			s, err := scan.Interpolate(tt.query, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if s != tt.want {
+				t.Errorf("unexpected interpolation, want: %q, recieved: %q %s", tt.want, s, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import "fmt"
+
+// Number is a constraint of the integer and the floating-point types
+// which literals are rendered the same as the numeric parameters.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Quote returns the numeric literal of the value
+// (for example in the custom AssertFunc).
+func Quote[T Number](v T) string {
+	return fmt.Sprint(v)
+}
+
+// QuoteString returns the single-quoted string literal
+// of the default dialect where the quotes are doubled.
+func QuoteString(s string) string {
+	return DialectDefault.QuoteString(s)
+}
+
+// QuoteBytes returns the binary string literal of the dialect
+// (for example E'\\x2a' or 0x2a).
+func QuoteBytes(p []byte, d Dialect) string {
+	return d.QuoteBytes(p)
+}
+
+// Null returns NULL if the pointer is nil
+// or the literal of the value rendered by the render function,
+// for example:
+//
+//	Null(name, QuoteString)
+//	Null(id, Quote[int64])
+func Null[T any](p *T, render func(T) string) string {
+	if p == nil {
+		return "NULL"
+	}
+	return render(*p)
+}
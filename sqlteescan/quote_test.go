@@ -0,0 +1,99 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestQuote(t *testing.T) {
+	type id int64
+
+	var tests = []struct {
+		name  string
+		line  string
+		quote string
+		value interface{} // value of the established ValueString output
+	}{
+		{name: "int", line: line(), quote: sqlteescan.Quote(42), value: 42},
+		{name: "negative int8", line: line(), quote: sqlteescan.Quote(int8(-8)), value: int8(-8)},
+		{name: "uint64", line: line(), quote: sqlteescan.Quote(uint64(18446744073709551615)), value: uint64(18446744073709551615)},
+		{name: "float64", line: line(), quote: sqlteescan.Quote(4.2), value: 4.2},
+		{name: "float32", line: line(), quote: sqlteescan.Quote(float32(0.1)), value: float32(0.1)},
+		{name: "named integer", line: line(), quote: sqlteescan.Quote(id(7)), value: int64(7)},
+		{name: "string", line: line(), quote: sqlteescan.QuoteString("foo"), value: "foo"},
+		{name: "string with quotes", line: line(), quote: sqlteescan.QuoteString("it's 'quoted'"), value: "it's 'quoted'"},
+		{name: "bytes", line: line(), quote: sqlteescan.QuoteBytes([]byte("*foo"), sqlteescan.DialectDefault), value: []byte("*foo")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			want, err := sqlteescan.ValueString(tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if tt.quote != want {
+				t.Errorf("unexpected quotation, want: %q, recieved: %q %s", want, tt.quote, tt.line)
+			}
+		})
+	}
+}
+
+func TestQuoteBytesDialect(t *testing.T) {
+	want, err := sqlteescan.DialectSQLServer.ValueString([]byte("*foo"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s := sqlteescan.QuoteBytes([]byte("*foo"), sqlteescan.DialectSQLServer); s != want || s != "0x2a666f6f" {
+		t.Errorf("unexpected quotation, want: %q, recieved: %q", want, s)
+	}
+}
+
+func TestNull(t *testing.T) {
+	var (
+		i  = 42
+		f  = 4.2
+		s  = "it's"
+		b  = true
+		tm = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+
+	var tests = []struct {
+		name string
+		line string
+		got  string
+		want string
+	}{
+		{name: "int", line: line(), got: sqlteescan.Null(&i, sqlteescan.Quote[int]), want: "42"},
+		{name: "nil int", line: line(), got: sqlteescan.Null((*int)(nil), sqlteescan.Quote[int]), want: "NULL"},
+		{name: "float64", line: line(), got: sqlteescan.Null(&f, sqlteescan.Quote[float64]), want: "4.2"},
+		{name: "nil float64", line: line(), got: sqlteescan.Null((*float64)(nil), sqlteescan.Quote[float64]), want: "NULL"},
+		{name: "string", line: line(), got: sqlteescan.Null(&s, sqlteescan.QuoteString), want: "'it''s'"},
+		{name: "nil string", line: line(), got: sqlteescan.Null((*string)(nil), sqlteescan.QuoteString), want: "NULL"},
+		{name: "bool", line: line(), got: sqlteescan.Null(&b, func(b bool) string { return strconv.FormatBool(b) }), want: "true"},
+		{name: "time", line: line(), got: sqlteescan.Null(&tm, func(t time.Time) string { return sqlteescan.QuoteString(t.Format(time.RFC3339)) }), want: "'2021-01-02T03:04:05Z'"},
+		{name: "nil time", line: line(), got: sqlteescan.Null((*time.Time)(nil), func(time.Time) string { return "never" }), want: "NULL"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			if tt.got != tt.want {
+				t.Errorf("unexpected null rendering, want: %q, recieved: %q %s", tt.want, tt.got, tt.line)
+			}
+		})
+	}
+}
@@ -5,9 +5,12 @@
 package sqlteescan
 
 import (
+	"database/sql"
 	"database/sql/driver"
-	"encoding/hex"
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -21,8 +24,9 @@ var pool = sync.Pool{New: func() interface{} { return new(Scanner) }}
 // ordinal position of the parameter identifier and parameter value.
 //
 // The specification of a parameter value is defined by a Assert function of
-// type AssertFunc; the default Assert function provides access to an
-// string representation of the SQL parameter.
+// type AssertFunc; the default (nil) Assert function is the ValueString
+// method of the Dialect which provides access to an string representation
+// of the SQL parameter.
 //
 // Scanning stops unrecoverably at the first error.
 //
@@ -30,7 +34,12 @@ type Scanner struct {
 	Values      []driver.Value      // Non named/non ordinal parameters in database/sql/driver representation.
 	NamedValues []driver.NamedValue // Named or ordinal parameters in database/sql/driver representation.
 	Assert      AssertFunc          // The function to get string representation of the SQL parameter.
+	Dialect     Dialect             // SQL dialect of the string representation of the SQL parameter if Assert is nil.
 	Reverse     bool                // Scans parameters from ending to beginning
+	MaxArgs     int                 // If positive then Interpolate substitutes only first MaxArgs parameters.
+	EscapeLike  bool                // Escapes LIKE pattern metacharacters % and _ of the string parameters as \% and \_.
+	Placeholder PlaceholderStyle    // Style of the parameter identifiers recognized by Interpolate if the explicit placeholder is blank.
+	Types       []string            // SQL types of the parameters by position, the values of the non blank types are annotated by the type (for example 42::int8).
 	dirty       bool                // Scan has been called.
 	name        string              // Last name of the parameter identifier geted by scanner.
 	ordinal     int                 // Last ordinal position of the parameter identifier geted by scanner.
@@ -49,22 +58,25 @@ type Scanner struct {
 //
 type AssertFunc func(interface{}) (string, error)
 
+// GetScanner returns a scanner in the zero state from the pool.
 func GetScanner() *Scanner {
 	s := pool.Get().(*Scanner)
-	s.Values = s.Values[:0]
-	s.NamedValues = s.NamedValues[:0]
-	s.Assert = ValueString
-	s.Reverse = false
-	s.dirty = false
-	s.idx = 0
-	s.max = 0
+	s.reset()
 	return s
 }
 
+// PutScanner resets all fields of the scanner into the zero state
+// and returns the scanner into the pool, so the pooled scanners never
+// leak configuration or parameters between users.
 func PutScanner(s *Scanner) {
+	s.reset()
 	pool.Put(s)
 }
 
+func (s *Scanner) reset() {
+	*s = Scanner{}
+}
+
 // Err returns the first error that was encountered by the Scanner.
 func (s *Scanner) Err() error {
 	return s.err
@@ -113,13 +125,15 @@ func (s *Scanner) Scan() bool {
 	s.idx++
 
 	if len(s.Values) != 0 {
-		s.value, s.err = s.Assert(s.Values[i])
+		s.value, s.err = s.assert(s.Values[i])
+		s.annotate(i)
 
 		return s.err == nil
 	} else if len(s.NamedValues) != 0 {
 		s.name = s.NamedValues[i].Name
 		s.ordinal = s.NamedValues[i].Ordinal
-		s.value, s.err = s.Assert(s.NamedValues[i].Value)
+		s.value, s.err = s.assert(s.NamedValues[i].Value)
+		s.annotate(i)
 
 		return s.err == nil
 	}
@@ -127,92 +141,260 @@ func (s *Scanner) Scan() bool {
 	return false
 }
 
+// annotate appends the SQL type of the parameter of the index
+// to the value if the type is known.
+func (s *Scanner) annotate(i int) {
+	if s.err == nil && i < len(s.Types) && s.Types[i] != "" {
+		s.value += "::" + s.Types[i]
+	}
+}
+
+func (s *Scanner) assert(value interface{}) (string, error) {
+	if s.EscapeLike {
+		value = escapeLike(value)
+	}
+
+	if s.Assert != nil {
+		return s.Assert(value)
+	}
+	return s.Dialect.ValueString(value)
+}
+
+var likeReplacer = strings.NewReplacer("%", `\%`, "_", `\_`)
+
+// escapeLike returns the string (or the pointer to the string) parameter value
+// with the escaped LIKE pattern metacharacters or the value as is.
+func escapeLike(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return likeReplacer.Replace(v)
+
+	case *string:
+		if v != nil {
+			return likeReplacer.Replace(*v)
+		}
+	}
+
+	return value
+}
+
 // ValueString is a type assertion function for a Scanner that receives
 // untyped SQL parameter value and returns string representation of
-// the SQL parameter appropriate for the substitution into the plain SQL query.
+// the SQL parameter appropriate for the substitution into the plain SQL query
+// of the default (PostgreSQL) dialect.
 func ValueString(value interface{}) (string, error) {
+	return DialectDefault.ValueString(value)
+}
+
+// ValueString is a type assertion function for a Scanner that receives
+// untyped SQL parameter value and returns string representation of
+// the SQL parameter appropriate for the substitution into the plain SQL query
+// of the dialect.
+func (d Dialect) ValueString(value interface{}) (string, error) {
+	if v, ok := value.(driver.Valuer); ok {
+		return d.valuerString(v)
+	}
+
+	// the pointer is dereferenced after the interfaces of the original value
+	// are checked, so the types which implement the interfaces on the pointer
+	// receiver (for example *big.Int or *url.URL) keep their rendering
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return "NULL", nil
+	}
+
 	switch v := value.(type) {
-	case int, int32, int64, float32, float64:
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
 		return fmt.Sprint(v), nil
 
-	case *int:
-		if v == nil {
-			return "NULL", nil
-		}
-		return fmt.Sprint(*v), nil
+	case bool:
+		return d.Bool(v), nil
 
-	case *int32:
-		if v == nil {
-			return "NULL", nil
-		}
-		return fmt.Sprint(*v), nil
+	case []byte:
+		return d.QuoteBytes(v), nil
+
+	case string:
+		return d.QuoteString(v), nil
 
-	case *int64:
+	case nil:
+		return "NULL", nil
+
+	case time.Time:
+		return d.Time(v), nil
+
+	case *time.Time:
+		return d.Time(*v), nil
+
+	case []time.Time:
 		if v == nil {
 			return "NULL", nil
 		}
-		return fmt.Sprint(*v), nil
+		return d.QuoteString(timeArray(v, d.timeElement)), nil
 
-	case *float32:
-		if v == nil {
-			return "NULL", nil
+	case sql.Out:
+		return d.outString(v)
+
+	case encoding.TextMarshaler:
+		return d.textString(v)
+
+	case fmt.Stringer:
+		return d.QuoteString(v.String()), nil
+
+	case encoding.BinaryMarshaler:
+		return d.binaryString(v)
+
+	default:
+		if rv.Kind() == reflect.Ptr {
+			return d.ValueString(rv.Elem().Interface())
+		}
+		if s, ok := d.jsonString(v); ok {
+			return s, nil
 		}
-		return fmt.Sprint(*v), nil
+		return "", fmt.Errorf("unexpected type %T of the parameter value: %v", v, v)
+	}
+}
 
-	case *float64:
-		if v == nil {
-			return "NULL", nil
+// outString returns a marker of the output parameter of the stored procedure
+// (for example OUT(*int64)) instead of the value because the output parameter
+// has no input value yet or the marker with the input value of the input/output
+// parameter (for example INOUT(42)).
+func (d Dialect) outString(out sql.Out) (string, error) {
+	if !out.In {
+		return fmt.Sprintf("OUT(%T)", out.Dest), nil
+	}
+
+	s, err := d.ValueString(out.Dest)
+	if err != nil {
+		return "", err
+	}
+
+	return "INOUT(" + s + ")", nil
+}
+
+// textString returns the string literal of the dialect
+// of the text representation of the value.
+func (d Dialect) textString(v encoding.TextMarshaler) (string, error) {
+	p, err := v.MarshalText()
+	if err != nil {
+		return "", fmt.Errorf("unexpected marshal text error of the parameter value %T: %w", v, err)
+	}
+
+	return d.QuoteString(string(p)), nil
+}
+
+// binaryString returns the byte literal of the dialect
+// of the binary representation of the value.
+func (d Dialect) binaryString(v encoding.BinaryMarshaler) (string, error) {
+	p, err := v.MarshalBinary()
+	if err != nil {
+		return "", fmt.Errorf("unexpected marshal binary error of the parameter value %T: %w", v, err)
+	}
+
+	return d.QuoteBytes(p), nil
+}
+
+// jsonString returns single-quoted JSON representation of the map or
+// the struct which does not implement driver.Valuer (for example
+// map[string]interface{} bound to the jsonb column).
+// The struct without the exported fields is not rendered
+// because its JSON representation is always misleading {}.
+func (d Dialect) jsonString(v interface{}) (string, bool) {
+	if _, ok := v.(driver.Valuer); ok {
+		return "", false
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map && rv.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	if rv.Kind() == reflect.Struct && !exportedFields(rv.Type()) {
+		return "", false
+	}
+
+	p, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+
+	return d.QuoteString(string(p)), true
+}
+
+// exportedFields reports whether the struct type has the exported fields.
+func exportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
 		}
-		return fmt.Sprint(*v), nil
+	}
+	return false
+}
 
-	case bool:
-		return strings.ToUpper(fmt.Sprint(v)), nil
+// valuerString returns string representation of the value returned by
+// driver.Valuer (for example pq.Array returns already formatted array literal).
+func (d Dialect) valuerString(v driver.Valuer) (string, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return "NULL", nil
+	}
 
-	case *bool:
-		if v == nil {
-			return "NULL", nil
+	value, err := v.Value()
+	if err != nil {
+		return "", err
+	}
+
+	if value == nil {
+		return "NULL", nil
+	}
+
+	return d.ValueString(value)
+}
+
+// SizeString returns a type assertion function for a Scanner which
+// renders []byte or string parameter value longer than max bytes
+// as a size marker (for example <bytes:10240> or <string:4096>)
+// instead of its content, other values are rendered by the assert
+// function (or by ValueString if assert is nil).
+func SizeString(max int, assert AssertFunc) AssertFunc {
+	if assert == nil {
+		assert = ValueString
+	}
+
+	return func(value interface{}) (string, error) {
+		if s, ok := SizeMarker(value, max); ok {
+			return s, nil
 		}
-		return strings.ToUpper(fmt.Sprint(*v)), nil
+		return assert(value)
+	}
+}
 
+// SizeMarker returns a size marker (for example <bytes:10240> or <string:4096>)
+// and true if the value is []byte or string longer than max bytes.
+func SizeMarker(value interface{}, max int) (string, bool) {
+	switch v := value.(type) {
 	case []byte:
-		return bytea(v), nil
+		if len(v) > max {
+			return fmt.Sprintf("<bytes:%d>", len(v)), true
+		}
 
 	case *[]byte:
-		if v == nil {
-			return "NULL", nil
+		if v != nil && len(*v) > max {
+			return fmt.Sprintf("<bytes:%d>", len(*v)), true
 		}
-		return bytea(*v), nil
 
 	case string:
-		return fmt.Sprintf("'%s'", v), nil
-
-	case *string:
-		if v == nil {
-			return "NULL", nil
+		if len(v) > max {
+			return fmt.Sprintf("<string:%d>", len(v)), true
 		}
-		return fmt.Sprintf("'%s'", *v), nil
-
-	case time.Time:
-		return time3339(v), nil
 
-	case *time.Time:
-		if v == nil {
-			return "NULL", nil
+	case *string:
+		if v != nil && len(*v) > max {
+			return fmt.Sprintf("<string:%d>", len(*v)), true
 		}
-		return time3339(*v), nil
-
-	default:
-		return "", fmt.Errorf("unexpected type %T of the parameter value: %v", v, v)
 	}
+
+	return "", false
 }
 
 func time3339(t time.Time) string {
 	return fmt.Sprintf("'%s'", t.Format(time.RFC3339))
 }
-
-// bytea hex format <https://www.postgresql.org/docs/current/datatype-binary.html#id-1.5.7.12.9>.
-func bytea(p []byte) string {
-	dst := make([]byte, hex.EncodedLen(len(p)))
-	hex.Encode(dst, p)
-	return fmt.Sprintf("E'\\\\x%s'", dst)
-}
@@ -5,15 +5,46 @@
 package sqlteescan_test
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"net/url"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/danil/sqltee/sqlteescan"
 )
 
+func TestValueStringJSONValid(t *testing.T) {
+	in := map[string]interface{}{"foo": map[string]interface{}{"bar": "baz", "xyz": []interface{}{1, 2.5, true, nil}}}
+
+	s, err := sqlteescan.ValueString(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(s, "'") || !strings.HasSuffix(s, "'") {
+		t.Fatalf("unexpected quotation: %s", s)
+	}
+
+	if !json.Valid([]byte(s[1 : len(s)-1])) {
+		t.Errorf("unexpected invalid json: %s", s)
+	}
+}
+
+func TestValueStringJSONError(t *testing.T) {
+	_, err := sqlteescan.ValueString(map[string]interface{}{"foo": func() {}})
+	if err == nil {
+		t.Error("expected error on unsupported map value")
+	}
+}
+
 func TestValueString(t *testing.T) {
 	var tests = []struct {
 		name      string
@@ -154,6 +185,120 @@ func TestValueString(t *testing.T) {
 			in:   func() *time.Time { return nil }(),
 			want: "NULL",
 		},
+		{
+			name: "uint64 pointer",
+			line: line(),
+			in:   func() *uint64 { var i uint64 = 18446744073709551615; return &i }(),
+			want: "18446744073709551615",
+		},
+		{
+			name: "uint64 nil pointer",
+			line: line(),
+			in:   func() *uint64 { return nil }(),
+			want: "NULL",
+		},
+		{
+			name: "int8 pointer",
+			line: line(),
+			in:   func() *int8 { var i int8 = -8; return &i }(),
+			want: "-8",
+		},
+		{
+			name: "int double pointer",
+			line: line(),
+			in:   func() **int { i := 10; p := &i; return &p }(),
+			want: "10",
+		},
+		{
+			name: "int double pointer to nil",
+			line: line(),
+			in:   func() **int { var p *int; return &p }(),
+			want: "NULL",
+		},
+		{
+			name: "struct pointer",
+			line: line(),
+			in:   &struct{ Foo string }{Foo: "bar"},
+			want: `'{"Foo":"bar"}'`,
+		},
+		{
+			name: "valuer array",
+			line: line(),
+			in:   valuer{value: "{1,2,3}"},
+			want: "'{1,2,3}'",
+		},
+		{
+			name: "valuer nil value",
+			line: line(),
+			in:   valuer{},
+			want: "NULL",
+		},
+		{
+			name: "valuer nil pointer",
+			line: line(),
+			in:   func() *valuer { return nil }(),
+			want: "NULL",
+		},
+		{
+			name: "map",
+			line: line(),
+			in:   map[string]interface{}{"foo": "bar", "baz": 42},
+			want: `'{"baz":42,"foo":"bar"}'`,
+		},
+		{
+			name: "nested map",
+			line: line(),
+			in:   map[string]interface{}{"foo": map[string]interface{}{"bar": []interface{}{1, "it's"}}},
+			want: `'{"foo":{"bar":[1,"it''s"]}}'`,
+		},
+		{
+			name: "struct",
+			line: line(),
+			in:   struct{ Foo string }{Foo: "bar"},
+			want: `'{"Foo":"bar"}'`,
+		},
+		{
+			name: "binary marshaler",
+			line: line(),
+			in:   binaryMarshaler{p: []byte("foo")},
+			want: `E'\\x666f6f'`,
+		},
+		{
+			name: "binary marshaler pointer",
+			line: line(),
+			in:   &binaryMarshaler{p: []byte{0xde, 0xad}},
+			want: `E'\\xdead'`,
+		},
+		{
+			name: "big int pointer",
+			line: line(),
+			in:   big.NewInt(42),
+			want: "'42'",
+		},
+		{
+			name: "url pointer",
+			line: line(),
+			in:   &url.URL{Scheme: "https", Host: "example.com", Path: "/foo"},
+			want: "'https://example.com/foo'",
+		},
+		{
+			name: "pointer receiver stringer",
+			line: line(),
+			in:   &pointerStringer{s: "it's pointer"},
+			want: `'it''s pointer'`,
+		},
+		{
+			name: "text marshaler before stringer and binary marshaler",
+			line: line(),
+			in:   textMarshaler{text: "it's text"},
+			want: `'it''s text'`,
+		},
+		{
+			name: "stringer before binary marshaler",
+			line: line(),
+			in:   stringer{binaryMarshaler: binaryMarshaler{p: []byte("foo")}, s: "it's string"},
+			want: `'it''s string'`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -173,6 +318,310 @@ func TestValueString(t *testing.T) {
 	}
 }
 
+type valuer struct {
+	value driver.Value
+}
+
+func (v valuer) Value() (driver.Value, error) {
+	return v.value, nil
+}
+
+// binaryMarshaler is a struct which is marshaled to the binary
+// representation instead of the JSON.
+type binaryMarshaler struct {
+	p   []byte
+	err error
+}
+
+func (m binaryMarshaler) MarshalBinary() ([]byte, error) {
+	return m.p, m.err
+}
+
+// textMarshaler is a struct which is marshaled to the text representation
+// instead of the string and the binary representations.
+type textMarshaler struct {
+	text string
+	err  error
+}
+
+func (m textMarshaler) MarshalText() ([]byte, error) { return []byte(m.text), m.err }
+
+func (m textMarshaler) String() string { return "string" }
+
+func (m textMarshaler) MarshalBinary() ([]byte, error) { return []byte("binary"), nil }
+
+// stringer is a struct which is rendered by the String method
+// instead of the binary representation.
+type stringer struct {
+	binaryMarshaler
+	s string
+}
+
+func (s stringer) String() string { return s.s }
+
+// pointerStringer implements fmt.Stringer on the pointer receiver.
+type pointerStringer struct {
+	s string
+}
+
+func (s *pointerStringer) String() string { return s.s }
+
+func TestValueStringUnexportedStruct(t *testing.T) {
+	_, err := sqlteescan.ValueString(struct{ foo int }{foo: 42})
+	if err == nil {
+		t.Errorf("unexpected error, expected: the error of the struct without the exported fields, recieved: %v", err)
+	}
+}
+
+func TestValueStringTextMarshalerError(t *testing.T) {
+	errMarshal := errors.New("marshal failure")
+
+	_, err := sqlteescan.ValueString(textMarshaler{err: errMarshal})
+	if !errors.Is(err, errMarshal) {
+		t.Errorf("unexpected error, expected: %v, recieved: %v", errMarshal, err)
+	}
+}
+
+func TestValueStringBinaryMarshalerError(t *testing.T) {
+	errMarshal := errors.New("marshal failure")
+
+	_, err := sqlteescan.ValueString(binaryMarshaler{err: errMarshal})
+	if !errors.Is(err, errMarshal) {
+		t.Errorf("unexpected error, expected: %v, recieved: %v", errMarshal, err)
+	}
+}
+
+func TestSizeString(t *testing.T) {
+	var tests = []struct {
+		name string
+		line string
+		in   interface{}
+		want string
+	}{
+		{
+			name: "over-threshold byte slice",
+			line: line(),
+			in:   make([]byte, 10240),
+			want: "<bytes:10240>",
+		},
+		{
+			name: "over-threshold string",
+			line: line(),
+			in:   strings.Repeat("x", 4096),
+			want: "<string:4096>",
+		},
+		{
+			name: "over-threshold string pointer",
+			line: line(),
+			in:   func() *string { s := strings.Repeat("x", 4096); return &s }(),
+			want: "<string:4096>",
+		},
+		{
+			name: "under-threshold byte slice",
+			line: line(),
+			in:   []byte("foo"),
+			want: "E'\\\\x666f6f'",
+		},
+		{
+			name: "under-threshold string",
+			line: line(),
+			in:   "foo",
+			want: "'foo'",
+		},
+		{
+			name: "int",
+			line: line(),
+			in:   int64(1234567890),
+			want: "1234567890",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := sqlteescan.SizeString(8, nil)(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if s != tt.want {
+				t.Errorf("unexpected string, want: %q, recieved: %q %s", tt.want, s, tt.line)
+			}
+		})
+	}
+}
+
+func TestDialectSQLServerValueString(t *testing.T) {
+	var tests = []struct {
+		name string
+		in   interface{}
+		want string
+		line string
+	}{
+		{
+			name: "boolean true",
+			line: line(),
+			in:   true,
+			want: "1",
+		},
+		{
+			name: "boolean false",
+			line: line(),
+			in:   false,
+			want: "0",
+		},
+		{
+			name: "boolean pointer",
+			line: line(),
+			in:   func() *bool { b := true; return &b }(),
+			want: "1",
+		},
+		{
+			name: "string",
+			line: line(),
+			in:   "foo",
+			want: "N'foo'",
+		},
+		{
+			name: "unicode string with quote",
+			line: line(),
+			in:   "it's ☃",
+			want: "N'it''s ☃'",
+		},
+		{
+			name: "string pointer",
+			line: line(),
+			in:   func() *string { s := "foo"; return &s }(),
+			want: "N'foo'",
+		},
+		{
+			name: "byte slice",
+			line: line(),
+			in:   []byte("foo"),
+			want: "0x666f6f",
+		},
+		{
+			name: "byte slice pointer",
+			line: line(),
+			in:   func() *[]byte { p := []byte("foo"); return &p }(),
+			want: "0x666f6f",
+		},
+		{
+			name: "int",
+			line: line(),
+			in:   int64(42),
+			want: "42",
+		},
+		{
+			name: "valuer boolean",
+			line: line(),
+			in:   valuer{value: true},
+			want: "1",
+		},
+		{
+			name: "binary marshaler",
+			line: line(),
+			in:   binaryMarshaler{p: []byte("foo")},
+			want: "0x666f6f",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := sqlteescan.DialectSQLServer.ValueString(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if s != tt.want {
+				t.Errorf("unexpected interpolation, want: %q, recieved: %q %s", tt.want, s, tt.line)
+			}
+		})
+	}
+}
+
+func TestScannerEscapeLike(t *testing.T) {
+	var tests = []struct {
+		name       string
+		line       string
+		in         interface{}
+		escapeLike bool
+		want       string
+	}{
+		{
+			name:       "escape like",
+			line:       line(),
+			in:         "50%_off",
+			escapeLike: true,
+			want:       `'50\%\_off'`,
+		},
+		{
+			name: "do not escape like",
+			line: line(),
+			in:   "50%_off",
+			want: "'50%_off'",
+		},
+		{
+			name:       "escape like of pointer",
+			line:       line(),
+			in:         func() *string { s := "a_b"; return &s }(),
+			escapeLike: true,
+			want:       `'a\_b'`,
+		},
+		{
+			name:       "escape like of bytes",
+			line:       line(),
+			in:         []byte("%"),
+			escapeLike: true,
+			want:       `E'\\x25'`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			s := sqlteescan.GetScanner()
+			defer sqlteescan.PutScanner(s)
+			s.Values = []driver.Value{tt.in}
+			s.EscapeLike = tt.escapeLike
+
+			if !s.Scan() {
+				t.Fatalf("unexpected scan error: %s %s", s.Err(), tt.line)
+			}
+
+			_, _, value := s.Param()
+			if value != tt.want {
+				t.Errorf("unexpected value, want: %s, recieved: %s %s", tt.want, value, tt.line)
+			}
+		})
+	}
+}
+
+func TestPutScanner(t *testing.T) {
+	s := sqlteescan.GetScanner()
+	s.Values = []driver.Value{int64(1), "foo"}
+	s.NamedValues = []driver.NamedValue{{Name: "foo", Ordinal: 1, Value: "bar"}}
+	s.Assert = func(interface{}) (string, error) { return "", errors.New("foo") }
+	s.Dialect = sqlteescan.DialectSQLServer
+	s.Reverse = true
+	s.Scan()
+	sqlteescan.PutScanner(s)
+
+	s = sqlteescan.GetScanner()
+	defer sqlteescan.PutScanner(s)
+
+	if !reflect.DeepEqual(*s, sqlteescan.Scanner{}) {
+		t.Errorf("unexpected scanner state, expected zero state, recieved: %+v", *s)
+	}
+}
+
 // New reports file and line number information about function invocations.
 func line() string {
 	_, file, line, ok := runtime.Caller(1)
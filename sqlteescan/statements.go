@@ -0,0 +1,95 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import (
+	"strings"
+)
+
+// StatementCount returns the number of the statements of the batch
+// of the semicolon-separated statements (for example 3 of the
+// INSERT INTO t VALUES (1); INSERT INTO t VALUES (2); SELECT 1).
+// Semicolons inside the string literals, the quoted identifiers,
+// the dollar-quoted strings and the comments do not separate
+// the statements, the blank statements and the statements
+// of the comments only are not counted.
+func StatementCount(query string) int {
+	var (
+		n     int
+		blank = true
+	)
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case isSpace(c):
+			i++
+
+		case c == ';':
+			if !blank {
+				n++
+			}
+			blank = true
+			i++
+
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			j := strings.IndexByte(query[i:], '\n')
+			if j == -1 {
+				j = len(query) - i
+			}
+			i += j
+
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			j := strings.Index(query[i+2:], "*/")
+			if j == -1 {
+				j = len(query) - i
+			} else {
+				j += 4
+			}
+			i += j
+
+		case c == '\'' || c == '"' || c == '`':
+			blank = false
+			i += quotedLen(query[i:], c)
+
+		case c == '$':
+			blank = false
+			i += dollarQuotedLen(query[i:])
+
+		default:
+			blank = false
+			i++
+		}
+	}
+
+	if !blank {
+		n++
+	}
+
+	return n
+}
+
+// dollarQuotedLen returns the length of the dollar-quoted string
+// at the beginning of the s (for example $$;$$ or $fn$;$fn$)
+// or the length of the s if the closing tag is not found
+// or one if the s does not begin with the dollar-quoted string
+// (for example of the $1 placeholder).
+func dollarQuotedLen(s string) int {
+	i := 1
+	for i < len(s) && isIdent(s[i]) && !(i == 1 && isDigit(s[i])) {
+		i++
+	}
+	if i == len(s) || s[i] != '$' {
+		return 1
+	}
+
+	tag := s[:i+1]
+	j := strings.Index(s[len(tag):], tag)
+	if j == -1 {
+		return len(s)
+	}
+	return 2*len(tag) + j
+}
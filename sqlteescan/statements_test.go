@@ -0,0 +1,44 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"testing"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestStatementCount(t *testing.T) {
+	var tests = []struct {
+		name  string
+		line  string
+		query string
+		want  int
+	}{
+		{name: "blank", line: line(), query: "", want: 0},
+		{name: "semicolons only", line: line(), query: " ; ;\n", want: 0},
+		{name: "single", line: line(), query: "SELECT 1", want: 1},
+		{name: "single with trailing semicolon", line: line(), query: "SELECT 1;", want: 1},
+		{name: "batch with semicolon in string literal", line: line(), query: "INSERT INTO t (s) VALUES ('a;b'); UPDATE t SET s = 'it''s;'; DELETE FROM t", want: 3},
+		{name: "semicolons in quoted identifiers", line: line(), query: `SELECT "a;b", ` + "`c;d`" + ` FROM t; SELECT 2`, want: 2},
+		{name: "semicolons in comments", line: line(), query: "SELECT 1; -- SELECT 2;\nSELECT 3 /* ; SELECT 4; */", want: 2},
+		{name: "comment only statement", line: line(), query: "SELECT 1; /* done; */", want: 1},
+		{name: "dollar-quoted strings", line: line(), query: "CREATE FUNCTION f() RETURNS int AS $fn$ BEGIN RETURN 1; END; $fn$ LANGUAGE plpgsql; SELECT $$;$$", want: 2},
+		{name: "placeholders", line: line(), query: "UPDATE t SET a = $1; UPDATE t SET b = $2", want: 2},
+		{name: "unterminated string literal", line: line(), query: "SELECT 1; SELECT ';", want: 2},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			n := sqlteescan.StatementCount(tt.query)
+			if n != tt.want {
+				t.Errorf("unexpected statement count, want: %d, recieved: %d %s", tt.want, n, tt.line)
+			}
+		})
+	}
+}
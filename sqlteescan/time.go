@@ -0,0 +1,119 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeFormat is a format of the string representation of the time.Time
+// parameter values: one of the epoch modes or the layout of the time.Time.Format
+// (the blank format is the RFC 3339 layout).
+type TimeFormat string
+
+const (
+	TimeRFC3339      TimeFormat = ""             // single-quoted RFC 3339 (for example '2021-01-02T03:04:05Z')
+	TimeEpochSeconds TimeFormat = "epoch"        // unquoted Unix time in seconds (for example 1609556645)
+	TimeEpochMillis  TimeFormat = "epoch-millis" // unquoted Unix time in milliseconds (for example 1609556645000)
+)
+
+// String returns the string representation of the time of the format.
+func (f TimeFormat) String(t time.Time) string {
+	switch f {
+	case TimeRFC3339:
+		return time3339(t)
+
+	case TimeEpochSeconds:
+		return strconv.FormatInt(t.Unix(), 10)
+
+	case TimeEpochMillis:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+
+	default:
+		return "'" + strings.ReplaceAll(t.Format(string(f)), "'", "''") + "'"
+	}
+}
+
+// ArrayString returns the string representation of the array
+// of the times (for example the timestamptz[] of the PostgreSQL)
+// as the single-quoted array literal of the times of the format
+// (for example '{"2020-11-21T13:56:42Z","2020-11-22T13:56:42Z"}')
+// or NULL if the slice is nil.
+func (f TimeFormat) ArrayString(ts []time.Time) string {
+	if ts == nil {
+		return "NULL"
+	}
+	return "'" + strings.ReplaceAll(timeArray(ts, f.element), "'", "''") + "'"
+}
+
+// element returns the unquoted string representation
+// of the time of the format as the element of the array.
+func (f TimeFormat) element(t time.Time) string {
+	switch f {
+	case TimeRFC3339:
+		return t.Format(time.RFC3339)
+
+	case TimeEpochSeconds, TimeEpochMillis:
+		return f.String(t)
+
+	default:
+		return t.Format(string(f))
+	}
+}
+
+// timeArray returns the array literal of the times
+// (for example {"2020-11-21T13:56:42Z"}) with the double quotes
+// and the backslashes of the elements escaped by the backslashes.
+func timeArray(ts []time.Time, element func(time.Time) string) string {
+	var b strings.Builder
+
+	b.WriteByte('{')
+	for i, t := range ts {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('"')
+		b.WriteString(arrayEscaper.Replace(element(t)))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+var arrayEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// TimeString returns a type assertion function for a Scanner which renders
+// time.Time or []time.Time (or non-nil pointers to them) parameter value in the format,
+// other values are rendered by the assert function
+// (or by ValueString if assert is nil).
+func TimeString(format TimeFormat, assert AssertFunc) AssertFunc {
+	if assert == nil {
+		assert = ValueString
+	}
+
+	return func(value interface{}) (string, error) {
+		switch v := value.(type) {
+		case time.Time:
+			return format.String(v), nil
+
+		case *time.Time:
+			if v != nil {
+				return format.String(*v), nil
+			}
+
+		case []time.Time:
+			return format.ArrayString(v), nil
+
+		case *[]time.Time:
+			if v != nil {
+				return format.ArrayString(*v), nil
+			}
+		}
+		return assert(value)
+	}
+}
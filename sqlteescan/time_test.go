@@ -0,0 +1,111 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlteescan_test
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+func TestTimeString(t *testing.T) {
+	tm := time.Date(2021, 1, 2, 3, 4, 5, 678000000, time.UTC)
+
+	var tests = []struct {
+		name   string
+		line   string
+		format sqlteescan.TimeFormat
+		in     interface{}
+		want   string
+	}{
+		{name: "epoch seconds", line: line(), format: sqlteescan.TimeEpochSeconds, in: tm, want: "1609556645"},
+		{name: "epoch seconds of pointer", line: line(), format: sqlteescan.TimeEpochSeconds, in: &tm, want: "1609556645"},
+		{name: "epoch seconds of nil pointer", line: line(), format: sqlteescan.TimeEpochSeconds, in: (*time.Time)(nil), want: "NULL"},
+		{name: "epoch seconds of local time", line: line(), format: sqlteescan.TimeEpochSeconds, in: tm.In(time.FixedZone("UTC+3", 3*60*60)), want: "1609556645"},
+		{name: "epoch millis", line: line(), format: sqlteescan.TimeEpochMillis, in: tm, want: "1609556645678"},
+		{name: "epoch millis of pointer", line: line(), format: sqlteescan.TimeEpochMillis, in: &tm, want: "1609556645678"},
+		{name: "epoch millis of nil pointer", line: line(), format: sqlteescan.TimeEpochMillis, in: (*time.Time)(nil), want: "NULL"},
+		{name: "epoch millis of int", line: line(), format: sqlteescan.TimeEpochMillis, in: int64(42), want: "42"},
+		{name: "epoch millis of string", line: line(), format: sqlteescan.TimeEpochMillis, in: "foo", want: "'foo'"},
+		{name: "rfc3339", line: line(), format: sqlteescan.TimeRFC3339, in: tm, want: "'2021-01-02T03:04:05Z'"},
+		{name: "layout", line: line(), format: "2006-01-02", in: tm, want: "'2021-01-02'"},
+		{name: "layout with quote", line: line(), format: "2006-01-02 'Z'", in: tm, want: "'2021-01-02 ''Z'''"},
+		{name: "rfc3339 array", line: line(), format: sqlteescan.TimeRFC3339, in: []time.Time{tm, tm.Add(24 * time.Hour)}, want: `'{"2021-01-02T03:04:05Z","2021-01-03T03:04:05Z"}'`},
+		{name: "rfc3339 empty array", line: line(), format: sqlteescan.TimeRFC3339, in: []time.Time{}, want: "'{}'"},
+		{name: "rfc3339 nil array", line: line(), format: sqlteescan.TimeRFC3339, in: []time.Time(nil), want: "NULL"},
+		{name: "epoch millis array", line: line(), format: sqlteescan.TimeEpochMillis, in: []time.Time{tm, tm.Add(time.Second)}, want: `'{"1609556645678","1609556646678"}'`},
+		{name: "epoch millis array of pointer", line: line(), format: sqlteescan.TimeEpochMillis, in: &[]time.Time{tm}, want: `'{"1609556645678"}'`},
+		{name: "layout array", line: line(), format: "2006-01-02", in: []time.Time{tm, tm.Add(24 * time.Hour)}, want: `'{"2021-01-02","2021-01-03"}'`},
+		{name: "layout array with quotes", line: line(), format: `2006-01-02 "Z" 'Z'`, in: []time.Time{tm}, want: `'{"2021-01-02 \"Z\" ''Z''"}'`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := sqlteescan.TimeString(tt.format, nil)(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if s != tt.want {
+				t.Errorf("unexpected time string, want: %q, recieved: %q %s", tt.want, s, tt.line)
+			}
+		})
+	}
+}
+
+func TestTimeStringInterpolate(t *testing.T) {
+	tm := time.Date(2021, 1, 2, 3, 4, 5, 678000000, time.UTC)
+
+	scan := sqlteescan.GetScanner()
+	defer sqlteescan.PutScanner(scan)
+
+	scan.NamedValues = []driver.NamedValue{{Ordinal: 1, Value: tm}, {Ordinal: 2, Value: (*time.Time)(nil)}}
+	scan.Assert = sqlteescan.TimeString(sqlteescan.TimeEpochMillis, nil)
+
+	s, err := scan.Interpolate("UPDATE foo SET created_at = $1, deleted_at = $2", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s != "UPDATE foo SET created_at = 1609556645678, deleted_at = NULL" {
+		t.Errorf("unexpected interpolation, recieved: %q", s)
+	}
+}
+
+func TestValueStringTimeArray(t *testing.T) {
+	tm := time.Date(2020, 11, 21, 13, 56, 42, 0, time.UTC)
+
+	var tests = []struct {
+		name string
+		line string
+		in   interface{}
+		want string
+	}{
+		{name: "two elements", line: line(), in: []time.Time{tm, tm.Add(time.Hour)}, want: `'{"2020-11-21T13:56:42Z","2020-11-21T14:56:42Z"}'`},
+		{name: "empty", line: line(), in: []time.Time{}, want: "'{}'"},
+		{name: "nil", line: line(), in: []time.Time(nil), want: "NULL"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := sqlteescan.ValueString(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %s %s", err, tt.line)
+			}
+
+			if s != tt.want {
+				t.Errorf("unexpected time array string, want: %q, recieved: %q %s", tt.want, s, tt.line)
+			}
+		})
+	}
+}
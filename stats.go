@@ -0,0 +1,240 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TopicStat is a statistic of the operations of the topic
+// (for example conn-exec-context or tx-commit).
+type TopicStat struct {
+	Count    int64         // number of the operations
+	Errors   int64         // number of the failed operations
+	Duration time.Duration // total duration of the operations
+}
+
+// Stats returns the statistics of the operations
+// accumulated by the driver per topic.
+func (d *Driver) Stats() map[string]TopicStat {
+	d.stats.mu.Lock()
+	defer d.stats.mu.Unlock()
+	return d.stats.snapshot(false)
+}
+
+// ResetStats returns the statistics of the operations accumulated
+// by the driver per topic and zeroes the counters in one operation,
+// so the operations which are completed between the read and the reset
+// are neither lost nor counted twice (for example to compute the rates).
+func (d *Driver) ResetStats() map[string]TopicStat {
+	d.stats.mu.Lock()
+	defer d.stats.mu.Unlock()
+	return d.stats.snapshot(true)
+}
+
+// stats is a set of the counters of the topics.
+// Counters are incremented atomically under the read lock,
+// so the snapshot under the write lock is consistent.
+type stats struct {
+	mu     sync.RWMutex
+	topics map[string]*topicCounters
+}
+
+type topicCounters struct {
+	count    int64
+	errors   int64
+	duration int64
+}
+
+func (s *stats) record(topic string, d time.Duration, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	s.mu.RLock()
+	c, ok := s.topics[topic]
+	if !ok {
+		s.mu.RUnlock()
+		s.mu.Lock()
+		if c, ok = s.topics[topic]; !ok {
+			if s.topics == nil {
+				s.topics = make(map[string]*topicCounters)
+			}
+			c = new(topicCounters)
+			s.topics[topic] = c
+		}
+		s.mu.Unlock()
+		s.mu.RLock()
+	}
+
+	atomic.AddInt64(&c.count, 1)
+	if err != nil {
+		atomic.AddInt64(&c.errors, 1)
+	}
+	atomic.AddInt64(&c.duration, int64(d))
+	s.mu.RUnlock()
+}
+
+// snapshot returns the statistics of the topics and zeroes
+// the counters if reset is true. It should be called under the write lock.
+func (s *stats) snapshot(reset bool) map[string]TopicStat {
+	m := make(map[string]TopicStat, len(s.topics))
+	for topic, c := range s.topics {
+		m[topic] = TopicStat{Count: c.count, Errors: c.errors, Duration: time.Duration(c.duration)}
+		if reset {
+			*c = topicCounters{}
+		}
+	}
+	return m
+}
+
+// durationRecorder records the duration of the operation of the topic.
+type durationRecorder interface {
+	record(topic string, d time.Duration, err error)
+}
+
+// recordLogger is a Logger which records the durations of the operations
+// (for example into the statistics of the driver) and passes all logs
+// through to the underlying logger.
+type recordLogger struct {
+	forwarder
+	recorder durationRecorder
+}
+
+func newRecordLogger(inner Logger, recorder durationRecorder) recordLogger {
+	l := recordLogger{recorder: recorder}
+	l.forwarder = forward(inner, func(inner Logger) Logger { return newRecordLogger(inner, recorder) })
+	return l
+}
+
+func (l recordLogger) DriverOpen(name string, d time.Duration, err error) {
+	l.recorder.record("driver-open", d, err)
+	l.Logger.DriverOpen(name, d, err)
+}
+
+func (l recordLogger) ConnPrepare(d time.Duration, query string, err error) {
+	l.recorder.record("conn-prepare", d, err)
+	l.Logger.ConnPrepare(d, query, err)
+}
+
+func (l recordLogger) ConnClose(d time.Duration, err error) {
+	l.recorder.record("conn-close", d, err)
+	l.Logger.ConnClose(d, err)
+}
+
+func (l recordLogger) ConnBegin(d time.Duration, err error) {
+	l.recorder.record("conn-begin", d, err)
+	l.Logger.ConnBegin(d, err)
+}
+
+func (l recordLogger) ConnBeginTx(ctx context.Context, d time.Duration, opts driver.TxOptions, err error) {
+	l.recorder.record("conn-begin-tx", d, err)
+	l.Logger.ConnBeginTx(ctx, d, opts, err)
+}
+
+func (l recordLogger) ConnPrepareContext(ctx context.Context, d time.Duration, query string, err error) {
+	l.recorder.record("conn-prepare-context", d, err)
+	l.Logger.ConnPrepareContext(ctx, d, query, err)
+}
+
+func (l recordLogger) ConnPrepareFallback(ctx context.Context, d time.Duration, query string, err error) {
+	l.recorder.record("conn-prepare-fallback", d, err)
+	logPrepareFallback(l.Logger, ctx, d, query, err)
+}
+
+func (l recordLogger) ConnExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.recorder.record("conn-exec", d, err)
+	l.Logger.ConnExec(d, query, dargs, res, err)
+}
+
+func (l recordLogger) ConnExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.recorder.record("conn-exec-context", d, err)
+	l.Logger.ConnExecContext(ctx, d, query, nvdargs, res, err)
+}
+
+func (l recordLogger) ConnPing(ctx context.Context, d time.Duration, err error) {
+	l.recorder.record("conn-ping", d, err)
+	l.Logger.ConnPing(ctx, d, err)
+}
+
+func (l recordLogger) ConnRaw() {
+	l.recorder.record("conn-raw", 0, nil)
+	logRaw(l.Logger)
+}
+
+func (l recordLogger) ConnExplain(ctx context.Context, d time.Duration, query string, plan []string, err error) {
+	l.recorder.record("conn-explain", d, err)
+	logExplain(l.Logger, ctx, d, query, plan, err)
+}
+
+func (l recordLogger) ConnQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.recorder.record("conn-query", d, err)
+	l.Logger.ConnQuery(d, query, dargs, err)
+}
+
+func (l recordLogger) ConnQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.recorder.record("conn-query-context", d, err)
+	l.Logger.ConnQueryContext(ctx, d, query, nvdargs, err)
+}
+
+func (l recordLogger) StmtClose(d time.Duration, err error) {
+	l.recorder.record("stmt-close", d, err)
+	l.Logger.StmtClose(d, err)
+}
+
+func (l recordLogger) StmtCloseTotal(d, total time.Duration, err error) {
+	l.recorder.record("stmt-close", d, err)
+	logStmtClose(l.Logger, d, total, err)
+}
+
+func (l recordLogger) StmtExec(d time.Duration, query string, dargs []driver.Value, res driver.Result, err error) {
+	l.recorder.record("stmt-exec", d, err)
+	l.Logger.StmtExec(d, query, dargs, res, err)
+}
+
+func (l recordLogger) StmtExecContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, res driver.Result, err error) {
+	l.recorder.record("stmt-exec-context", d, err)
+	l.Logger.StmtExecContext(ctx, d, query, nvdargs, res, err)
+}
+
+func (l recordLogger) StmtQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.recorder.record("stmt-query", d, err)
+	l.Logger.StmtQuery(d, query, dargs, err)
+}
+
+func (l recordLogger) StmtQueryContext(ctx context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.recorder.record("stmt-query-context", d, err)
+	l.Logger.StmtQueryContext(ctx, d, query, nvdargs, err)
+}
+
+func (l recordLogger) RowsNext(d time.Duration, dest []driver.Value, err error) {
+	l.recorder.record("rows-next", d, err)
+	l.Logger.RowsNext(d, dest, err)
+}
+
+func (l recordLogger) RowsNextRow(d time.Duration, row int, columns []string, dest []driver.Value, err error) {
+	l.recorder.record("rows-next", d, err)
+	logRowsNext(l.Logger, d, row, columns, dest, err)
+}
+
+func (l recordLogger) TxCommit(d time.Duration, err error) {
+	l.recorder.record("tx-commit", d, err)
+	l.Logger.TxCommit(d, err)
+}
+
+func (l recordLogger) TxRollback(d time.Duration, err error) {
+	l.recorder.record("tx-rollback", d, err)
+	l.Logger.TxRollback(d, err)
+}
+
+func (l recordLogger) TxSavepoint(ctx context.Context, d time.Duration, query, command, name string, err error) {
+	l.recorder.record("tx-savepoint", d, err)
+	l.Logger.TxSavepoint(ctx, d, query, command, name, err)
+}
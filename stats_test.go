@@ -0,0 +1,129 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestDriverStats(t *testing.T) {
+	drv := &Driver{Driver: fakedb.Driver, Logger: EventLogger{Callback: func(Event) {}, NewTimer: func() Timer { return fakeTimer{} }}}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_driver_stats")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE|tbl|id=int64`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err = db.Exec("INSERT|tbl|id=?", i)
+		if err != nil {
+			t.Fatalf("db exec error: %#v", err)
+		}
+	}
+
+	_, err = db.Exec("INSERT|nonexistent_table|id=?", 42)
+	if err == nil {
+		t.Fatal("expected db exec error")
+	}
+
+	stat := drv.Stats()["stmt-exec-context"]
+	if stat != (TopicStat{Count: 4, Duration: 4 * 42}) {
+		t.Errorf("unexpected stmt-exec-context stat, expected: %+v, recieved: %+v", TopicStat{Count: 4, Duration: 4 * 42}, stat)
+	}
+
+	stat = drv.Stats()["conn-prepare-context"]
+	if stat.Count != 5 || stat.Errors != 1 {
+		t.Errorf("unexpected conn-prepare-context stat, expected: 5 operations and 1 error, recieved: %+v", stat)
+	}
+
+	if _, ok := drv.Stats()["conn-exec-context"]; ok {
+		t.Errorf("unexpected conn-exec-context stat of the driver.ErrSkip: %+v", drv.Stats())
+	}
+
+	reset := drv.ResetStats()
+	if reset["stmt-exec-context"].Count != 4 {
+		t.Errorf("unexpected reset stmt-exec-context stat, expected: 4 operations, recieved: %+v", reset["stmt-exec-context"])
+	}
+
+	if stat := drv.Stats()["stmt-exec-context"]; stat != (TopicStat{}) {
+		t.Errorf("unexpected stmt-exec-context stat after reset, expected: zero, recieved: %+v", stat)
+	}
+}
+
+func TestDriverResetStatsConcurrency(t *testing.T) {
+	const (
+		goroutines = 16
+		records    = 2000
+	)
+
+	drv := &Driver{}
+	errFail := errors.New("fail")
+
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+		mu   sync.Mutex
+		sum  TopicStat
+	)
+
+	add := func(m map[string]TopicStat) {
+		mu.Lock()
+		defer mu.Unlock()
+		s := m["conn-exec"]
+		sum.Count += s.Count
+		sum.Errors += s.Errors
+		sum.Duration += s.Duration
+	}
+
+	resetter := make(chan struct{})
+	go func() {
+		defer close(resetter)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				add(drv.ResetStats())
+			}
+		}
+	}()
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < records; j++ {
+				var err error
+				if j%2 == 0 {
+					err = errFail
+				}
+				drv.stats.record("conn-exec", 3, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+	<-resetter
+	add(drv.ResetStats())
+
+	expected := TopicStat{Count: goroutines * records, Errors: goroutines * records / 2, Duration: goroutines * records * 3}
+	if sum != expected {
+		t.Errorf("unexpected sum of the reset stats, expected: %+v, recieved: %+v", expected, sum)
+	}
+}
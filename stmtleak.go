@@ -0,0 +1,61 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"sync/atomic"
+)
+
+// StmtLeakLogger may be implemented by the Logger to log
+// the number of the prepared statements of the connection
+// which are not closed by the conn-close (the statements leaked
+// by the code which prepares the statements and never closes them).
+//
+// The leaks are detected only if the Driver.DetectStmtLeaks is true
+// and only for the code which uses the driver.Conn directly
+// (for example through the Connector.Connect), the database/sql
+// closes the statements of the connection before it closes
+// the connection, so the leaks of the *sql.DB are never detected.
+type StmtLeakLogger interface {
+	WithStmtLeak(unclosed int) Logger
+}
+
+// prepared counts the statement prepared by the connection
+// (for the statement leaks and for the prepare ratio).
+func (c connection) prepared() {
+	if c.stmts != nil {
+		atomic.AddInt32(c.stmts, 1)
+	}
+	if c.prepareRatio.every > 0 {
+		c.prepareRatio.counts.prepare()
+	}
+}
+
+// closed counts the statement of the connection which is closed.
+func (c connection) closed() {
+	if c.stmts != nil {
+		atomic.AddInt32(c.stmts, -1)
+	}
+}
+
+// withStmtLeak returns the logger tagged by the number of the unclosed
+// statements of the connection if the statements are not closed
+// and the logger implements StmtLeakLogger.
+func (c connection) withStmtLeak(logger Logger) Logger {
+	if c.stmts == nil {
+		return logger
+	}
+
+	n := atomic.LoadInt32(c.stmts)
+	if n <= 0 {
+		return logger
+	}
+
+	if l, ok := logger.(StmtLeakLogger); ok {
+		return l.WithStmtLeak(int(n))
+	}
+
+	return logger
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestStmtLeak(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		detect   bool
+		prepare  int
+		close    int
+		expected int
+	}{
+		{
+			name:     "one of two statements is not closed",
+			line:     line(),
+			detect:   true,
+			prepare:  2,
+			close:    1,
+			expected: 1,
+		},
+		{
+			name:     "all statements are closed",
+			line:     line(),
+			detect:   true,
+			prepare:  2,
+			close:    2,
+			expected: 0,
+		},
+		{
+			name:     "leaks are not detected",
+			line:     line(),
+			prepare:  2,
+			close:    1,
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu       sync.Mutex
+				unclosed = -1
+			)
+
+			l := EventLogger{
+				Callback: func(e Event) {
+					if e.Topic == "conn-close" {
+						mu.Lock()
+						defer mu.Unlock()
+						unclosed = e.StmtLeak
+					}
+				},
+				NewTimer: func() Timer { return fakeTimer{} },
+			}
+			drv := &Driver{Driver: fakedb.Driver, Logger: l, DetectStmtLeaks: tt.detect}
+
+			c, err := drv.OpenConnector("fakedb_sqltee_test_stmt_leak_" + tt.line)
+			if err != nil {
+				t.Fatalf("driver open connector error: %#v %s", err, tt.line)
+			}
+
+			conn, err := c.Connect(context.Background())
+			if err != nil {
+				t.Fatalf("connector connect error: %#v %s", err, tt.line)
+			}
+
+			var stmts []driver.Stmt
+			for i := 0; i < tt.prepare; i++ {
+				stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(context.Background(), "WIPE")
+				if err != nil {
+					t.Fatalf("conn prepare error: %#v %s", err, tt.line)
+				}
+				stmts = append(stmts, stmt)
+			}
+
+			for _, stmt := range stmts[:tt.close] {
+				err = stmt.Close()
+				if err != nil {
+					t.Fatalf("stmt close error: %#v %s", err, tt.line)
+				}
+			}
+
+			// the fake driver fails the close of the connection
+			// with the dangling statements, the close is logged anyway
+			err = conn.Close()
+			if (err != nil) != (tt.close != tt.prepare) {
+				t.Fatalf("unexpected conn close error: %#v %s", err, tt.line)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if unclosed != tt.expected {
+				t.Errorf("unexpected number of the unclosed statements, expected: %d, recieved: %d %s", tt.expected, unclosed, tt.line)
+			}
+		})
+	}
+}
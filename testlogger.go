@@ -0,0 +1,191 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/sqlteescan"
+)
+
+// TestLogger returns a Logger which logs a topic, an execution time and
+// an interpolated query (or a query and arguments) through tb.Logf,
+// so the SQL shows up in the go test -v output.
+// The driver.ErrSkip fast-path errors and the io.EOF of rows are not logged.
+func TestLogger(tb testing.TB) Logger {
+	return testLogger{tb: tb}
+}
+
+type testLogger struct {
+	tb testing.TB
+}
+
+func (l testLogger) DriverOpen(name string, d time.Duration, err error) {
+	l.log("driver-open", d, SanitizeDSN(name), err)
+}
+
+func (l testLogger) ConnPrepare(d time.Duration, query string, err error) {
+	l.log("conn-prepare", d, query, err)
+}
+
+func (l testLogger) ConnClose(d time.Duration, err error) {
+	l.log("conn-close", d, "", err)
+}
+
+func (l testLogger) ConnBegin(d time.Duration, err error) {
+	l.log("conn-begin", d, "", err)
+}
+
+func (l testLogger) ConnBeginTx(_ context.Context, d time.Duration, opts driver.TxOptions, err error) {
+	var s string
+	if (opts != driver.TxOptions{}) {
+		s = fmt.Sprintf("{Isolation:%s ReadOnly:%t}", IsolationName(opts.Isolation), opts.ReadOnly)
+	}
+	l.log("conn-begin-tx", d, s, err)
+}
+
+func (l testLogger) ConnPrepareContext(_ context.Context, d time.Duration, query string, err error) {
+	l.log("conn-prepare-context", d, query, err)
+}
+
+func (l testLogger) ConnPrepareFallback(_ context.Context, d time.Duration, query string, err error) {
+	l.log("conn-prepare-fallback", d, query, err)
+}
+
+func (l testLogger) ConnExec(d time.Duration, query string, dargs []driver.Value, _ driver.Result, err error) {
+	l.interpolation("conn-exec", d, query, dargs, nil, err)
+}
+
+func (l testLogger) ConnExecContext(_ context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, _ driver.Result, err error) {
+	l.interpolation("conn-exec-context", d, query, nil, nvdargs, err)
+}
+
+func (l testLogger) ConnPing(_ context.Context, d time.Duration, err error) {
+	if err == ErrPingUnsupported {
+		l.log("conn-ping", d, "ping: unsupported", nil)
+		return
+	}
+	l.log("conn-ping", d, "", err)
+}
+
+func (l testLogger) ConnRaw() {
+	l.log("conn-raw", 0, "", nil)
+}
+
+func (l testLogger) ConnExplain(_ context.Context, d time.Duration, query string, plan []string, err error) {
+	l.log("conn-explain", d, strings.Join(append([]string{query}, plan...), "\n"), err)
+}
+
+func (l testLogger) ConnQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.interpolation("conn-query", d, query, dargs, nil, err)
+}
+
+func (l testLogger) ConnQueryContext(_ context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.interpolation("conn-query-context", d, query, nil, nvdargs, err)
+}
+
+func (l testLogger) StmtClose(d time.Duration, err error) {
+	l.log("stmt-close", d, "", err)
+}
+
+func (l testLogger) StmtCloseTotal(d, total time.Duration, err error) {
+	l.log("stmt-close", d, fmt.Sprintf("stmt-total: %s", total), err)
+}
+
+func (l testLogger) StmtExec(d time.Duration, query string, dargs []driver.Value, _ driver.Result, err error) {
+	l.interpolation("stmt-exec", d, query, dargs, nil, err)
+}
+
+func (l testLogger) StmtExecContext(_ context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, _ driver.Result, err error) {
+	l.interpolation("stmt-exec-context", d, query, nil, nvdargs, err)
+}
+
+func (l testLogger) StmtQuery(d time.Duration, query string, dargs []driver.Value, err error) {
+	l.interpolation("stmt-query", d, query, dargs, nil, err)
+}
+
+func (l testLogger) StmtQueryContext(_ context.Context, d time.Duration, query string, nvdargs []driver.NamedValue, err error) {
+	l.interpolation("stmt-query-context", d, query, nil, nvdargs, err)
+}
+
+func (l testLogger) RowsNext(d time.Duration, _ []driver.Value, err error) {
+	if err == io.EOF {
+		return
+	}
+	l.log("rows-next", d, "", err)
+}
+
+func (l testLogger) TxCommit(d time.Duration, err error) {
+	l.log("tx-commit", d, "", err)
+}
+
+func (l testLogger) TxRollback(d time.Duration, err error) {
+	l.log("tx-rollback", d, "", err)
+}
+
+func (l testLogger) TxSavepoint(_ context.Context, d time.Duration, _, command, name string, err error) {
+	l.log("tx-savepoint", d, command+" "+name, err)
+}
+
+func (l testLogger) Timer() Timer {
+	return timer{start: time.Now()}
+}
+
+func (l testLogger) interpolation(topic string, d time.Duration, query string, dargs []driver.Value, nvdargs []driver.NamedValue, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	s, serr := sqlteescan.Interpolate(query, "", dargs, nvdargs)
+	if s == "" || serr != nil {
+		s = query
+		if len(dargs) != 0 {
+			s = strings.TrimSpace(fmt.Sprintf("%s %v", s, dargs))
+		} else if len(nvdargs) != 0 {
+			s = strings.TrimSpace(fmt.Sprintf("%s %+v", s, nvdargs))
+		}
+	}
+
+	l.log(topic, d, s, err)
+}
+
+func (l testLogger) log(topic string, d time.Duration, s string, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	var b strings.Builder
+
+	b.WriteString(topic)
+	b.WriteByte(' ')
+	b.WriteString(d.String())
+
+	if s != "" {
+		b.WriteByte(' ')
+		b.WriteString(s)
+	}
+
+	if err != nil {
+		b.WriteString(" error: ")
+		b.WriteString(err.Error())
+	}
+
+	l.tb.Logf("%s", b.String())
+}
+
+type timer struct {
+	start time.Time
+}
+
+func (t timer) Stop() time.Duration {
+	return time.Since(t.start)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+type fakeTB struct {
+	testing.TB
+	mu   sync.Mutex
+	logs []string
+}
+
+func (tb *fakeTB) Logf(format string, args ...interface{}) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.logs = append(tb.logs, fmt.Sprintf(format, args...))
+}
+
+func TestTestLogger(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	drv := &Driver{Driver: fakedb.Driver, Logger: TestLogger(tb)}
+
+	c, err := drv.OpenConnector("fakedb_sqltee_test_test_logger")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE|tbl|id=int64,name=string`)
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|tbl|id=?,name=?", 42, "foo")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	db.Close()
+
+	expected := `driver-open [0-9.nµms]+ fakedb_sqltee_test_test_logger
+conn-prepare-context [0-9.nµms]+ CREATE\|tbl\|id=int64,name=string
+stmt-exec-context [0-9.nµms]+ CREATE\|tbl\|id=int64,name=string
+stmt-close [0-9.nµms]+ stmt-total: [0-9.nµms]+
+conn-prepare-context [0-9.nµms]+ INSERT\|tbl\|id=\?,name=\?
+stmt-exec-context [0-9.nµms]+ INSERT\|tbl\|id=\?,name=\? \[\{Name: Ordinal:1 Value:42\} \{Name: Ordinal:2 Value:foo\}\]
+stmt-close [0-9.nµms]+ stmt-total: [0-9.nµms]+
+conn-close [0-9.nµms]+$`
+
+	r, err := regexp.Compile(expected)
+	if err != nil {
+		t.Fatalf("regexp compile error: %#v", err)
+	}
+
+	logs := strings.Join(tb.logs, "\n")
+	if !r.MatchString(logs) {
+		t.Errorf("unexpected log, expected: %v, recieved: %v", expected, logs)
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import "context"
+
+// withStatementTimeout returns a copy of the parent context
+// with the statement timeout of the connection (see Driver.StatementTimeout)
+// and the cancel function of the copy or the parent context as is
+// and the nop cancel function if the statement timeout is not positive.
+func (c connection) withStatementTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.statementTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, c.statementTimeout)
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+func TestStatementTimeoutExec(t *testing.T) {
+	drv := &Driver{Driver: fakedb.Driver, Logger: NopLogger{}, StatementTimeout: 10 * time.Millisecond}
+
+	c, err := drv.OpenConnector("TestStatementTimeoutExec")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("WAIT|50ms|INSERT|t1|name=?", "foo")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected exec error, expected: %v, recieved: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestStatementTimeoutRows(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		ctxs = map[string]context.Context{}
+	)
+
+	l := EventLogger{Callback: func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Ctx != nil {
+			ctxs[e.Topic] = e.Ctx
+		}
+	}}
+	drv := &Driver{Driver: fakedb.Driver, Logger: l, StatementTimeout: time.Minute}
+
+	c, err := drv.OpenConnector("TestStatementTimeoutRows")
+	if err != nil {
+		t.Fatalf("driver open connector error: %#v", err)
+	}
+
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE|t1|name=string")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	_, err = db.Exec("INSERT|t1|name=?", "foo")
+	if err != nil {
+		t.Fatalf("db exec error: %#v", err)
+	}
+
+	rows, err := db.Query("SELECT|t1|name|")
+	if err != nil {
+		t.Fatalf("db query error: %#v", err)
+	}
+
+	mu.Lock()
+	qctx := ctxs["stmt-query-context"]
+	exctx := ctxs["stmt-exec-context"]
+	mu.Unlock()
+
+	if _, ok := qctx.Deadline(); !ok {
+		t.Fatalf("unexpected query context, expected: deadline of the statement timeout, recieved: %v", qctx)
+	}
+
+	if exctx.Err() != context.Canceled {
+		t.Errorf("unexpected exec context error after the exec, expected: %v, recieved: %v", context.Canceled, exctx.Err())
+	}
+
+	var names []string
+	for rows.Next() {
+		if qctx.Err() != nil {
+			t.Errorf("unexpected query context error while the rows are read, expected: nil, recieved: %v", qctx.Err())
+		}
+
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			t.Fatalf("rows scan error: %#v", err)
+		}
+		names = append(names, name)
+	}
+
+	err = rows.Close()
+	if err != nil {
+		t.Fatalf("rows close error: %#v", err)
+	}
+
+	if len(names) != 1 || names[0] != "foo" {
+		t.Errorf("unexpected rows, expected: [foo], recieved: %q", names)
+	}
+
+	if qctx.Err() != context.Canceled {
+		t.Errorf("unexpected query context error after the close, expected: %v, recieved: %v", context.Canceled, qctx.Err())
+	}
+}
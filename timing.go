@@ -0,0 +1,32 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+// TimingLogger may be implemented by the Logger
+// which does not log the durations (for example the pure audit)
+// to skip the timers of the operations, so the operations
+// are logged with the zero durations without the clock reads.
+// The statistics of the Driver (see Driver.Stats) record
+// the zero durations of such a Logger as well.
+type TimingLogger interface {
+	NeedsTiming() bool
+}
+
+// startTimer returns the timer of the logger
+// or the zero duration timer if the logger opts out of the timing.
+func startTimer(l Logger) Timer {
+	if !needsTiming(l) {
+		return nopTimer{}
+	}
+	return l.Timer()
+}
+
+// needsTiming reports whether the logger needs the timing.
+func needsTiming(l Logger) bool {
+	if tl, ok := l.(TimingLogger); ok {
+		return tl.NeedsTiming()
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// untimedLogger is the event logger which opts out of the timing.
+type untimedLogger struct{ EventLogger }
+
+func (untimedLogger) NeedsTiming() bool { return false }
+
+func TestTimingLogger(t *testing.T) {
+	var tests = []struct {
+		name     string
+		untimed  bool
+		expected time.Duration
+	}{
+		{name: "timed", expected: 42},
+		{name: "untimed", untimed: true, expected: 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu        sync.Mutex
+				durations = map[time.Duration]int{}
+				timers    int32
+			)
+
+			el := EventLogger{
+				Callback: func(e Event) {
+					mu.Lock()
+					defer mu.Unlock()
+					durations[e.Duration]++
+				},
+				NewTimer: func() Timer {
+					atomic.AddInt32(&timers, 1)
+					return fakeTimer{}
+				},
+			}
+
+			var l Logger = el
+			if tt.untimed {
+				l = untimedLogger{el}
+			}
+
+			drv := &Driver{Driver: fakedb.Driver, Logger: l}
+
+			c, err := drv.OpenConnector("fakedb_sqltee_test_timing_" + tt.name)
+			if err != nil {
+				t.Fatalf("driver open connector error: %#v", err)
+			}
+
+			db := sql.OpenDB(c)
+
+			err = workload(db)
+			if err != nil {
+				t.Fatalf("workload error: %#v", err)
+			}
+
+			db.Close()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(durations) != 1 || durations[tt.expected] == 0 {
+				t.Errorf("unexpected durations, expected: only %s, recieved: %v", tt.expected, durations)
+			}
+
+			if n := atomic.LoadInt32(&timers); tt.untimed && n != 0 || !tt.untimed && n == 0 {
+				t.Errorf("unexpected number of the timers, expected untimed: %t, recieved: %d", tt.untimed, n)
+			}
+		})
+	}
+}
+
+// BenchmarkTimingLogger compares the workload through the logger
+// of the wall clock timer and through the logger which opts out of the timing.
+func BenchmarkTimingLogger(b *testing.B) {
+	el := EventLogger{Callback: func(Event) {}}
+
+	var benchmarks = []struct {
+		name   string
+		logger Logger
+	}{
+		{name: "timed", logger: el},
+		{name: "untimed", logger: untimedLogger{el}},
+	}
+
+	for i, bb := range benchmarks {
+		bb := bb
+		dsn := fmt.Sprintf("BenchmarkTimingLogger_%d", i)
+		b.Run(bb.name, func(b *testing.B) {
+			c, err := (&Driver{Driver: fakedb.Driver, Logger: bb.logger}).OpenConnector(dsn)
+			if err != nil {
+				b.Fatalf("open connector error: %#v", err)
+			}
+
+			db := sql.OpenDB(c)
+			defer db.Close()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				err = workload(db)
+				if err != nil {
+					b.Fatalf("workload error: %#v", err)
+				}
+			}
+		})
+	}
+}
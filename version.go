@@ -0,0 +1,61 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import "database/sql/driver"
+
+// DriverVersionLogger may be implemented by the Logger
+// to log the version of the driver (for example 1.10.9)
+// by the first driver-open of the driver, so the logs record
+// which driver build produced them.
+//
+// The version is detected if the driver or the connection
+// implements the optional interface{ Version() string }.
+type DriverVersionLogger interface {
+	WithDriverVersion(version string) Logger
+}
+
+// versioner is implemented by the drivers
+// or the connections which expose the version of the driver.
+type versioner interface {
+	Version() string
+}
+
+// version returns the version of the driver or of the connection
+// if the version is detected for the first time by the driver
+// or blank string.
+func (d *Driver) version(conn driver.Conn) string {
+	var v string
+	if drv, ok := d.Driver.(versioner); ok {
+		v = drv.Version()
+	} else if c, ok := conn.(versioner); ok {
+		v = c.Version()
+	}
+	if v == "" {
+		return ""
+	}
+
+	var first bool
+	d.versionOnce.Do(func() { first = true })
+	if !first {
+		return ""
+	}
+
+	return v
+}
+
+// withDriverVersion returns the logger tagged by the version of the driver
+// if the version is not blank and the logger implements DriverVersionLogger.
+func withDriverVersion(logger Logger, version string) Logger {
+	if version == "" {
+		return logger
+	}
+
+	if l, ok := logger.(DriverVersionLogger); ok {
+		return l.WithDriverVersion(version)
+	}
+
+	return logger
+}
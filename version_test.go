@@ -0,0 +1,131 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/danil/sqltee/internal/fakedb"
+)
+
+// versionedDriver is a driver which exposes the version of the driver.
+type versionedDriver struct {
+	driver.Driver
+	version string
+	err     error // if not nil then returned by the first open
+}
+
+func (d *versionedDriver) Open(name string) (driver.Conn, error) {
+	if err := d.err; err != nil {
+		d.err = nil
+		return nil, err
+	}
+	return d.Driver.Open(name)
+}
+
+func (d *versionedDriver) Version() string { return d.version }
+
+// versionedConn is a connection which exposes the version of the driver.
+type versionedConn struct {
+	driver.Conn
+}
+
+func (versionedConn) Version() string { return "2.0.0" }
+
+type versionedConnDriver struct {
+	driver.Driver
+}
+
+func (d versionedConnDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return versionedConn{Conn: conn}, nil
+}
+
+func TestDriverVersion(t *testing.T) {
+	var tests = []struct {
+		name     string
+		line     string
+		driver   driver.Driver
+		expected []string
+	}{
+		{
+			name:     "driver version",
+			line:     line(),
+			driver:   &versionedDriver{Driver: fakedb.Driver, version: "1.10.9"},
+			expected: []string{"1.10.9", "", ""},
+		},
+		{
+			name:     "connection version",
+			line:     line(),
+			driver:   versionedConnDriver{Driver: fakedb.Driver},
+			expected: []string{"2.0.0", "", ""},
+		},
+		{
+			name:     "version after failed open",
+			line:     line(),
+			driver:   &versionedDriver{Driver: fakedb.Driver, version: "1.10.9", err: errors.New("bad connection")},
+			expected: []string{"", "1.10.9", ""},
+		},
+		{
+			name:     "without version",
+			line:     line(),
+			driver:   fakedb.Driver,
+			expected: []string{"", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu    sync.Mutex
+				opens []string
+			)
+
+			l := EventLogger{
+				Callback: func(e Event) {
+					mu.Lock()
+					defer mu.Unlock()
+					if e.Topic == "driver-open" {
+						opens = append(opens, e.Version)
+					} else if e.Version != "" {
+						t.Errorf("unexpected version of %s, expected: blank, recieved: %q %s", e.Topic, e.Version, tt.line)
+					}
+				},
+				NewTimer: func() Timer { return fakeTimer{} },
+			}
+
+			drv := &Driver{Driver: tt.driver, Logger: l}
+
+			for range tt.expected {
+				conn, err := drv.Open("TestDriverVersion")
+				if err == nil {
+					conn.Close()
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(opens) != len(tt.expected) {
+				t.Fatalf("unexpected driver-open logs, expected: %q, recieved: %q %s", tt.expected, opens, tt.line)
+			}
+			for i := range opens {
+				if opens[i] != tt.expected[i] {
+					t.Errorf("unexpected versions, expected: %q, recieved: %q %s", tt.expected, opens, tt.line)
+					break
+				}
+			}
+		})
+	}
+}
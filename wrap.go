@@ -0,0 +1,45 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+// opError is an error of the operation wrapped with the topic.
+type opError struct {
+	topic string
+	err   error
+}
+
+func (e *opError) Error() string {
+	return "sqltee " + e.topic + ": " + e.err.Error()
+}
+
+func (e *opError) Unwrap() error {
+	return e.err
+}
+
+// wrapError returns the error wrapped with the topic of the operation
+// if wrap is true, so errors.Is and errors.As still find the error.
+// The sentinel errors compared by database/sql without unwrapping
+// (driver.ErrSkip, driver.ErrBadConn, driver.ErrRemoveArgument and io.EOF)
+// and the already wrapped errors (for example of the fallback
+// of ExecContext to Exec) are returned as is.
+func wrapError(wrap bool, topic string, err error) error {
+	if !wrap || err == nil || err == driver.ErrSkip || err == driver.ErrBadConn ||
+		err == driver.ErrRemoveArgument || err == io.EOF {
+		return err
+	}
+
+	var e *opError
+	if errors.As(err, &e) {
+		return err
+	}
+
+	return &opError{topic: topic, err: err}
+}
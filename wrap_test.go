@@ -0,0 +1,121 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqltee
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errFailed = errors.New("failed")
+
+// failDriver is a driver which connections fail all operations.
+type failDriver struct{}
+
+func (failDriver) Open(string) (driver.Conn, error) { return failConn{}, nil }
+
+type failConn struct{}
+
+func (failConn) Prepare(string) (driver.Stmt, error) { return nil, errFailed }
+func (failConn) Close() error                        { return nil }
+func (failConn) Begin() (driver.Tx, error)           { return nil, errFailed }
+func (failConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return nil, errFailed
+}
+func (failConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return nil, errFailed
+}
+
+func TestWrapErrors(t *testing.T) {
+	var tests = []struct {
+		name       string
+		line       string
+		wrapErrors bool
+		fetch      func(*sql.DB) error
+		expected   string
+	}{
+		{
+			name:       "exec",
+			line:       line(),
+			wrapErrors: true,
+			fetch:      func(db *sql.DB) error { _, err := db.Exec("DELETE FROM foo"); return err },
+			expected:   "sqltee conn-exec-context: failed",
+		},
+		{
+			name:       "query",
+			line:       line(),
+			wrapErrors: true,
+			fetch:      func(db *sql.DB) error { _, err := db.Query("SELECT * FROM foo"); return err },
+			expected:   "sqltee conn-query-context: failed",
+		},
+		{
+			name:       "prepare",
+			line:       line(),
+			wrapErrors: true,
+			fetch:      func(db *sql.DB) error { _, err := db.Prepare("SELECT * FROM foo"); return err },
+			expected:   "sqltee conn-prepare-fallback: failed",
+		},
+		{
+			name:       "begin",
+			line:       line(),
+			wrapErrors: true,
+			fetch:      func(db *sql.DB) error { _, err := db.Begin(); return err },
+			expected:   "sqltee conn-begin-tx: failed",
+		},
+		{
+			name:     "exec without wrapping",
+			line:     line(),
+			fetch:    func(db *sql.DB) error { _, err := db.Exec("DELETE FROM foo"); return err },
+			expected: "failed",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+"/"+tt.line, func(t *testing.T) {
+			t.Parallel()
+
+			drv := &Driver{Driver: failDriver{}, Logger: NopLogger{}, WrapErrors: tt.wrapErrors}
+
+			c, err := drv.OpenConnector("")
+			if err != nil {
+				t.Fatalf("driver open connector error: %#v %s", err, tt.line)
+			}
+
+			db := sql.OpenDB(c)
+			defer db.Close()
+
+			err = tt.fetch(db)
+			if !errors.Is(err, errFailed) {
+				t.Errorf("unexpected error, expected: %#v, recieved: %#v %s", errFailed, err, tt.line)
+			}
+
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("unexpected error message, expected: %q, recieved: %v %s", tt.expected, err, tt.line)
+			}
+		})
+	}
+}
+
+func TestWrapErrorSentinels(t *testing.T) {
+	for _, err := range []error{nil, driver.ErrSkip, driver.ErrBadConn, driver.ErrRemoveArgument} {
+		if wrapped := wrapError(true, "conn-exec", err); wrapped != err {
+			t.Errorf("unexpected wrapping of the sentinel error, expected: %#v, recieved: %#v", err, wrapped)
+		}
+	}
+
+	wrapped := wrapError(true, "conn-exec", errFailed)
+	if twice := wrapError(true, "conn-exec-context", wrapped); twice != wrapped {
+		t.Errorf("unexpected wrapping of the wrapped error, expected: %v, recieved: %v", wrapped, twice)
+	}
+
+	if !strings.HasPrefix(wrapped.Error(), "sqltee conn-exec: ") {
+		t.Errorf("unexpected error message, expected: sqltee conn-exec: failed, recieved: %v", wrapped)
+	}
+}